@@ -0,0 +1,281 @@
+package recur
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAdminRecentEvents is how many recent events an AdminHandler keeps
+const DefaultAdminRecentEvents = 50
+
+// BreakerSet is a set of breakers an AdminHandler can show and control.
+// Targets implements it.
+type BreakerSet interface {
+	Breakers() []BreakerStatus
+	TripBreaker(i int) bool
+	ResetBreaker(i int) bool
+}
+
+// AdminEvent is a failed attempt or give-up recorded by AdminHandler.Hook
+type AdminEvent struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation,omitempty"`
+	CycleID   string    `json:"cycle_id,omitempty"`
+	Attempt   int       `json:"attempt"`
+	Err       string    `json:"error"`
+	GaveUp    bool      `json:"gave_up"` // Whether the cycle stopped after this attempt
+}
+
+// AdminStatus is the state an AdminHandler serves as JSON
+type AdminStatus struct {
+	KillSwitch       bool                       `json:"kill_switch"`
+	SleepingRetriers int64                      `json:"sleeping_retriers"`
+	Retriers         []MetricsSnapshot          `json:"retriers"`
+	Breakers         map[string][]BreakerStatus `json:"breakers"`
+	Limiters         map[string]LimiterStatus   `json:"limiters"`
+	Recent           []AdminEvent               `json:"recent"` // Newest first
+}
+
+// LimiterStatus is the state of an AdaptiveLimiter
+type LimiterStatus struct {
+	Limit    int `json:"limit"`
+	Inflight int `json:"inflight"`
+}
+
+// AdminHandler serves an operator page listing registered retriers with
+// their metrics, breakers, concurrency limits and recent failures, and lets
+// operators trip or reset breakers and toggle the kill-switch. It serves
+// HTML to browsers and JSON to clients sending Accept: application/json.
+// Actions are POSTed as form values: action=trip or action=reset with set
+// and target, or action=kill-switch with engaged=true or false.
+//
+// The handler changes production behavior; mount it behind authentication
+// and cross-site request protection.
+//
+// Example:
+//
+//	admin := recur.NewAdminHandler().
+//	    Register(paymentsRetrier.Metrics()).
+//	    RegisterBreakers("replicas", replicas)
+//	recur.RegisterGlobalHook(admin.Hook())
+//	mux.Handle("/debug/recur", requireOperator(admin))
+type AdminHandler struct {
+	mu         sync.Mutex
+	collectors []*MetricsCollector
+	breakers   map[string]BreakerSet
+	limiters   map[string]*AdaptiveLimiter
+	recent     []AdminEvent
+	keep       int
+}
+
+// NewAdminHandler creates an admin handler with nothing registered
+func NewAdminHandler() *AdminHandler {
+	return &AdminHandler{
+		breakers: map[string]BreakerSet{},
+		limiters: map[string]*AdaptiveLimiter{},
+		keep:     DefaultAdminRecentEvents,
+	}
+}
+
+// Register lists collectors, typically from named retriers' Metrics, and
+// their policy variants
+func (h *AdminHandler) Register(collectors ...*MetricsCollector) *AdminHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.collectors = append(h.collectors, collectors...)
+	return h
+}
+
+// RegisterBreakers lists set's breakers under name and allows operators to
+// trip and reset them
+func (h *AdminHandler) RegisterBreakers(name string, set BreakerSet) *AdminHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.breakers[name] = set
+	return h
+}
+
+// RegisterLimiter lists an adaptive concurrency limiter under name
+func (h *AdminHandler) RegisterLimiter(name string, limiter *AdaptiveLimiter) *AdminHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.limiters[name] = limiter
+	return h
+}
+
+// Hook returns a hook recording failed attempts and give-ups as recent
+// events. Register it globally or on the retriers to watch.
+func (h *AdminHandler) Hook() Hook {
+	return func(_ context.Context, e RetryEvent) {
+		event := AdminEvent{
+			Time:      time.Now(),
+			Operation: e.Operation,
+			CycleID:   e.CycleID,
+			Attempt:   e.Attempt,
+			GaveUp:    !e.WillRetry,
+		}
+		if err := cmp.Or(e.Final, e.Err); err != nil {
+			event.Err = err.Error()
+		}
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.recent = append(h.recent, event)
+		if over := len(h.recent) - h.keep; over > 0 {
+			h.recent = slices.Delete(h.recent, 0, over)
+		}
+	}
+}
+
+// Status returns the state the handler serves
+func (h *AdminHandler) Status() AdminStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	status := AdminStatus{
+		KillSwitch:       KillSwitchEngaged(),
+		SleepingRetriers: SleepingRetriers(),
+		Retriers:         []MetricsSnapshot{},
+		Breakers:         map[string][]BreakerStatus{},
+		Limiters:         map[string]LimiterStatus{},
+		Recent:           slices.Clone(h.recent),
+	}
+	for _, m := range h.collectors {
+		status.Retriers = append(status.Retriers, m.Snapshot())
+		for _, v := range m.Variants() {
+			status.Retriers = append(status.Retriers, v.Snapshot())
+		}
+	}
+	for name, set := range h.breakers {
+		status.Breakers[name] = set.Breakers()
+	}
+	for name, l := range h.limiters {
+		status.Limiters[name] = LimiterStatus{Limit: l.Limit(), Inflight: l.Inflight()}
+	}
+	slices.Reverse(status.Recent)
+	return status
+}
+
+// ServeHTTP implements http.Handler
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost:
+		if status, msg := h.act(r); status != http.StatusOK {
+			http.Error(w, msg, status)
+			return
+		}
+		if !wantsJSON(r) {
+			http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := h.Status()
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = adminPage.Execute(w, status)
+}
+
+// act applies the action posted in r
+func (h *AdminHandler) act(r *http.Request) (int, string) {
+	if err := r.ParseForm(); err != nil {
+		return http.StatusBadRequest, err.Error()
+	}
+	switch action := r.PostForm.Get("action"); action {
+	case "kill-switch":
+		engaged, err := strconv.ParseBool(r.PostForm.Get("engaged"))
+		if err != nil {
+			return http.StatusBadRequest, "engaged must be true or false"
+		}
+		SetKillSwitch(engaged)
+	case "trip", "reset":
+		h.mu.Lock()
+		set, ok := h.breakers[r.PostForm.Get("set")]
+		h.mu.Unlock()
+		if !ok {
+			return http.StatusNotFound, "unknown breaker set"
+		}
+		i, err := strconv.Atoi(r.PostForm.Get("target"))
+		if err != nil {
+			return http.StatusBadRequest, "target must be an index"
+		}
+		toggle := set.ResetBreaker
+		if action == "trip" {
+			toggle = set.TripBreaker
+		}
+		if !toggle(i) {
+			return http.StatusConflict, "breaker not available"
+		}
+	default:
+		return http.StatusBadRequest, "unknown action"
+	}
+	return http.StatusOK, ""
+}
+
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+var adminPage = template.Must(template.New("admin").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>recur</title>
+<style>body{font-family:sans-serif;margin:2em}table{border-collapse:collapse;margin-bottom:2em}td,th{border:1px solid #ccc;padding:4px 8px;text-align:left}.open{color:#b00}</style>
+</head>
+<body>
+<h1>recur</h1>
+<form method="post">
+<input type="hidden" name="action" value="kill-switch">
+<p>Kill-switch: <b>{{if .KillSwitch}}engaged{{else}}off{{end}}</b>
+<input type="hidden" name="engaged" value="{{not .KillSwitch}}">
+<button>{{if .KillSwitch}}Release{{else}}Engage{{end}}</button>
+&middot; {{.SleepingRetriers}} retriers sleeping</p>
+</form>
+
+<h2>Retriers</h2>
+<table>
+<tr><th>Name</th><th>Variant</th><th>Cycles</th><th>Attempts</th><th>Retries</th><th>Successes</th><th>Failures</th><th>Success rate</th><th>Attempts per success</th></tr>
+{{range .Retriers}}<tr><td>{{.Name}}</td><td>{{.Variant}}</td><td>{{.Cycles}}</td><td>{{.Attempts}}</td><td>{{.Retries}}</td><td>{{.Successes}}</td><td>{{.Failures}}</td><td>{{printf "%.3f" .SuccessRate}}</td><td>{{printf "%.2f" .AttemptsPerSuccess}}</td></tr>
+{{end}}</table>
+
+<h2>Breakers</h2>
+{{range $set, $breakers := .Breakers}}<h3>{{$set}}</h3>
+<table>
+<tr><th>Target</th><th>Failures</th><th>State</th><th></th></tr>
+{{range $i, $b := $breakers}}<tr><td>{{$b.Target}}</td><td>{{$b.Failures}}</td>
+<td>{{if $b.Open}}<span class="open">open</span> for {{$b.Remaining}}{{else}}closed{{end}}</td>
+<td><form method="post"><input type="hidden" name="set" value="{{$set}}"><input type="hidden" name="target" value="{{$i}}">
+{{if $b.Open}}<button name="action" value="reset">Reset</button>{{else}}<button name="action" value="trip">Trip</button>{{end}}</form></td></tr>
+{{end}}</table>
+{{end}}
+
+<h2>Limiters</h2>
+<table>
+<tr><th>Name</th><th>Limit</th><th>In flight</th></tr>
+{{range $name, $l := .Limiters}}<tr><td>{{$name}}</td><td>{{$l.Limit}}</td><td>{{$l.Inflight}}</td></tr>
+{{end}}</table>
+
+<h2>Recent failures</h2>
+<table>
+<tr><th>Time</th><th>Operation</th><th>Cycle</th><th>Attempt</th><th>Error</th></tr>
+{{range .Recent}}<tr><td>{{.Time.Format "15:04:05.000"}}</td><td>{{.Operation}}</td><td>{{.CycleID}}</td><td>{{.Attempt}}{{if .GaveUp}} (gave up){{end}}</td><td>{{.Err}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
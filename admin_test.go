@@ -0,0 +1,78 @@
+package recur
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminHandler(t *testing.T) {
+	defer SetKillSwitch(false)
+
+	replicas := NewTargets(RoundRobin,
+		Target[string]{Value: "db-1"},
+		Target[string]{Value: "db-2"},
+	).WithBreaker(1, time.Minute)
+	retrier := Func0(func() error { return ErrTemporary }).
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		WithMetrics("payments")
+
+	admin := NewAdminHandler().
+		Register(retrier.Metrics()).
+		RegisterBreakers("replicas", replicas).
+		RegisterLimiter("upstream", NewAdaptiveLimiter(4, 10))
+	_ = retrier.OnRetry(admin.Hook()).Build()()
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/debug/recur", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		admin.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := post(url.Values{"action": {"trip"}, "set": {"replicas"}, "target": {"1"}}); rec.Code != http.StatusSeeOther {
+		t.Fatalf("Expected redirect after tripping a breaker, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := post(url.Values{"action": {"kill-switch"}, "engaged": {"true"}}); rec.Code != http.StatusSeeOther || !KillSwitchEngaged() {
+		t.Fatalf("Expected kill-switch engaged, got %d", rec.Code)
+	}
+	if rec := post(url.Values{"action": {"trip"}, "set": {"unknown"}, "target": {"0"}}); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected unknown breaker set rejected, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/recur", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	admin.ServeHTTP(rec, req)
+
+	var status AdminStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if !status.KillSwitch {
+		t.Error("Expected kill-switch reported engaged")
+	}
+	if len(status.Retriers) != 1 || status.Retriers[0].Name != "payments" || status.Retriers[0].Attempts != 2 {
+		t.Errorf("Expected payments retrier metrics, got %+v", status.Retriers)
+	}
+	if b := status.Breakers["replicas"]; len(b) != 2 || b[0].Open || !b[1].Open || b[1].Target != "db-2" {
+		t.Errorf("Expected db-2 breaker open, got %+v", b)
+	}
+	if l := status.Limiters["upstream"]; l.Limit != 4 {
+		t.Errorf("Expected limiter status, got %+v", l)
+	}
+	if len(status.Recent) != 2 || !status.Recent[0].GaveUp || status.Recent[1].GaveUp {
+		t.Errorf("Expected a retry and a give-up newest first, got %+v", status.Recent)
+	}
+
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/recur", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "payments") || !strings.Contains(body, "db-2") {
+		t.Errorf("Expected HTML page listing retriers and breakers, got %s", body)
+	}
+}
@@ -0,0 +1,79 @@
+package recur
+
+import "sync"
+
+// ErrHookEventDropped is reported to OnHookError for every event dropped
+// because the queue of asynchronous hooks was full
+var ErrHookEventDropped error = &codedError{code: CodeHookEventDropped, msg: "recur: hook event dropped"}
+
+// defaultHookBuffer is the queue size of WithAsyncHooks for non-positive
+// sizes
+const defaultHookBuffer = 256
+
+// WithAsyncHooks runs hooks on a worker goroutine instead of the iterating
+// one, so slow logging or metrics sinks never delay the next attempt.
+// Events are handled in order, with up to buffer of them waiting; while
+// the queue is full, new events are dropped and ErrHookEventDropped is
+// reported to OnHookError. A non-positive buffer uses 256.
+//
+// Asynchronous hooks receive the cycle's context without its cancellation,
+// since the cycle may have ended by the time they run, and their panics are
+// always recovered, as there is no caller left to propagate them to. The
+// worker exits whenever the queue is empty.
+func (b *IteratorBuilder) WithAsyncHooks(buffer int) *IteratorBuilder {
+	if buffer <= 0 {
+		buffer = defaultHookBuffer
+	}
+	b.hookQueue = &hookQueue{size: buffer}
+	return b
+}
+
+// AsyncHooks returns a policy dispatching hooks asynchronously, see
+// IteratorBuilder.WithAsyncHooks
+func AsyncHooks(buffer int) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithAsyncHooks(buffer)
+	}
+}
+
+// hookQueue is a bounded queue of hook dispatches drained in order by at
+// most one worker goroutine
+type hookQueue struct {
+	mu      sync.Mutex
+	pending []func()
+	size    int
+	running bool
+}
+
+// push queues dispatch, starting a worker if none is running. It returns
+// false if the queue is full.
+func (q *hookQueue) push(dispatch func()) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) >= q.size {
+		return false
+	}
+	q.pending = append(q.pending, dispatch)
+	if !q.running {
+		q.running = true
+		go q.drain()
+	}
+	return true
+}
+
+// drain runs queued dispatches until the queue is empty
+func (q *hookQueue) drain() {
+	for {
+		q.mu.Lock()
+		if len(q.pending) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		dispatch := q.pending[0]
+		q.pending[0] = nil
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+		dispatch()
+	}
+}
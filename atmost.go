@@ -0,0 +1,71 @@
+package recur
+
+import "slices"
+
+// atMost caps how often errors matching matcher are retried in a cycle
+type atMost struct {
+	n       int
+	matcher ErrorMatcher
+}
+
+// WithRetryLimit retries errors matching matcher at most n times within a
+// cycle, even when attempts remain, so different error classes can get
+// different retry allowances. Limits only cap retries the matcher set with
+// RetryIf allows, and take precedence over Transient and RateLimited
+// classifications. Each call adds a limit, counted separately per cycle.
+//
+// Example:
+//
+//	// Retry rate limiting up to 4 times but a connection reset only once
+//	recur.Iter().WithMaxAttempts(6).
+//	    RetryIf(recur.Or(isTooManyRequests, isConnReset)).
+//	    WithRetryLimit(4, isTooManyRequests).
+//	    WithRetryLimit(1, isConnReset)
+func (b *IteratorBuilder) WithRetryLimit(n int, matcher ErrorMatcher) *IteratorBuilder {
+	b.limits = append(slices.Clip(b.limits), &atMost{n: n, matcher: matcher})
+	return b
+}
+
+// MatchAtMost returns a policy retrying errors matching matcher at most n
+// times within a cycle, see IteratorBuilder.WithRetryLimit
+func MatchAtMost(n int, matcher ErrorMatcher) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithRetryLimit(n, matcher)
+	}
+}
+
+// WithRetryLimit caps retries of errors matching matcher per call, see
+// IteratorBuilder.WithRetryLimit
+func (r *Retrier[F, C]) WithRetryLimit(n int, matcher ErrorMatcher) *Retrier[F, C] {
+	r.config.WithRetryLimit(n, matcher)
+	return r
+}
+
+// countLimited counts att's error against every limit it matches
+func (s *iteratorState) countLimited(att *Attempt) {
+	if len(s.builder.limits) == 0 || att.result == nil {
+		return
+	}
+	if s.limited == nil {
+		s.limited = make([]int, len(s.builder.limits))
+	}
+	for i, limit := range s.builder.limits {
+		if limit.matcher(att.result) {
+			s.limited[i]++
+		}
+	}
+}
+
+// limitExceeded reports whether err matches a limit that has been retried
+// as often as it allows
+func (s *iteratorState) limitExceeded(err error) bool {
+	if s.limited == nil {
+		return false
+	}
+	for i, limit := range s.builder.limits {
+		if s.limited[i] > limit.n && limit.matcher(err) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package recur
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Audit decisions recorded by AuditLog
+const (
+	DecisionRetry   = "retry"
+	DecisionSuccess = "success"
+	DecisionGiveUp  = "give_up"
+)
+
+// AuditRecord is a single retry decision written as one JSON line
+type AuditRecord struct {
+	Time      time.Time `json:"ts"`
+	Operation string    `json:"op"`
+	Attempt   int       `json:"attempt"`
+	Decision  string    `json:"decision"`
+	DelayMs   int64     `json:"delay_ms"`
+	Code      string    `json:"code,omitempty"`
+	CycleID   string    `json:"cycle_id,omitempty"`
+}
+
+// AuditLog appends one compact JSON record per retry decision to a writer,
+// for environments that must show why automated retries occurred. It is safe
+// to share between iterators. Records are never sampled.
+type AuditLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewAuditLog creates an audit log writing JSON lines to w
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{enc: json.NewEncoder(w)}
+}
+
+// Record writes rec as a single line
+func (l *AuditLog) Record(rec AuditRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.enc.Encode(rec); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+// Err returns the first write error encountered, if any
+func (l *AuditLog) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// WithAuditLog records every retry decision of this iterator to log under
+// the given operation name
+func (b *IteratorBuilder) WithAuditLog(log *AuditLog, operation string) *IteratorBuilder {
+	b.audit = log
+	b.auditOp = operation
+	return b
+}
+
+// audit records a decision about the last attempt
+func (s *iteratorState) audit(decision string, delay time.Duration, code string) {
+	if s.builder.audit == nil || s.lastAttempt == nil {
+		return
+	}
+	s.builder.audit.Record(AuditRecord{
+		Time:      time.Now().UTC(),
+		Operation: s.builder.auditOp,
+		Attempt:   s.lastAttempt.Number,
+		Decision:  decision,
+		DelayMs:   delay.Milliseconds(),
+		Code:      code,
+		CycleID:   s.cycleID(),
+	})
+}
+
+// auditBreak records the outcome of a cycle whose loop body broke out,
+// classified as its metrics are: a failure if the last attempt reported an
+// error, otherwise a success
+func (s *iteratorState) auditBreak() {
+	if last := s.lastAttempt; last != nil && last.resultSet && last.result != nil {
+		s.audit(DecisionGiveUp, 0, ErrorCode(last.result))
+		return
+	}
+	s.audit(DecisionSuccess, 0, "")
+}
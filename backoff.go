@@ -2,6 +2,7 @@ package recur
 
 import (
 	"math"
+	"math/rand/v2"
 	"time"
 )
 
@@ -13,6 +14,7 @@ type Backoff interface {
 // ConstantBackoff returns a fixed delay between retries
 type ConstantBackoff struct {
 	delay time.Duration
+	min   time.Duration
 }
 
 // Constant creates a backoff that waits a fixed duration between retries
@@ -20,15 +22,24 @@ func Constant(delay time.Duration) Backoff {
 	return &ConstantBackoff{delay: delay}
 }
 
+// WithMinDelay sets a floor no delay drops below
+func (b *ConstantBackoff) WithMinDelay(minDelay time.Duration) *ConstantBackoff {
+	b.min = minDelay
+	return b
+}
+
 func (b *ConstantBackoff) Next(attempt int) time.Duration {
-	return b.delay
+	return max(b.delay, b.min)
 }
 
 // ExponentialBackoff increases delay exponentially
 type ExponentialBackoff struct {
-	factor  float64
-	initial time.Duration
-	max     time.Duration
+	factor     float64
+	initial    time.Duration
+	max        time.Duration
+	min        time.Duration
+	firstExact bool
+	firstSet   bool
 }
 
 // Exponential creates a backoff that increases exponentially
@@ -47,24 +58,48 @@ func (b *ExponentialBackoff) WithMaxDelay(maxDelay time.Duration) *ExponentialBa
 	return b
 }
 
+// WithMinDelay sets a floor no delay drops below, taking precedence over
+// the maximum
+func (b *ExponentialBackoff) WithMinDelay(minDelay time.Duration) *ExponentialBackoff {
+	b.min = minDelay
+	return b
+}
+
 // WithFactor sets the exponential factor (default 2.0)
 func (b *ExponentialBackoff) WithFactor(factor float64) *ExponentialBackoff {
 	b.factor = factor
 	return b
 }
 
+// WithFirstDelayExact makes the first retry wait exactly initial instead of
+// initial * factor, i.e. delay = initial * (factor ^ (attempt - 1))
+func (b *ExponentialBackoff) WithFirstDelayExact(exact bool) *ExponentialBackoff {
+	b.firstExact = exact
+	b.firstSet = true
+	return b
+}
+
 func (b *ExponentialBackoff) Next(attempt int) time.Duration {
-	delay := float64(b.initial) * math.Pow(b.factor, float64(attempt))
+	if b.firstExact {
+		attempt--
+	}
+	return b.delay(attempt)
+}
+
+// delay returns initial * factor^n, clamped to the configured bounds
+func (b *ExponentialBackoff) delay(n int) time.Duration {
+	delay := float64(b.initial) * math.Pow(b.factor, float64(n))
 	if delay > float64(b.max) {
-		return b.max
+		return max(b.max, b.min)
 	}
-	return time.Duration(delay)
+	return max(time.Duration(delay), b.min)
 }
 
 // FibonacciBackoff uses fibonacci sequence for delays
 type FibonacciBackoff struct {
 	initial time.Duration
 	max     time.Duration
+	min     time.Duration
 }
 
 // Fibonacci creates a backoff using fibonacci sequence
@@ -82,21 +117,30 @@ func (b *FibonacciBackoff) WithMaxDelay(maxDelay time.Duration) *FibonacciBackof
 	return b
 }
 
+// WithMinDelay sets a floor no delay drops below, taking precedence over
+// the maximum
+func (b *FibonacciBackoff) WithMinDelay(minDelay time.Duration) *FibonacciBackoff {
+	b.min = minDelay
+	return b
+}
+
 func (b *FibonacciBackoff) Next(attempt int) time.Duration {
-	fib := fibonacci(attempt + 1)
-	delay := time.Duration(fib) * b.initial
-	if delay > b.max {
-		return b.max
+	if b.initial <= 0 {
+		return max(b.min, 0)
+	}
+	limit := int(b.max / b.initial)
+	fib := fibonacci(attempt+1, limit)
+	if fib > limit {
+		return max(b.max, b.min)
 	}
-	return delay
+	return max(time.Duration(fib)*b.initial, b.min)
 }
 
-func fibonacci(n int) int {
-	if n <= 1 {
-		return 1
-	}
+// fibonacci returns the nth fibonacci number, or the first one above limit
+// if that comes earlier, so large n neither overflows nor loops for long
+func fibonacci(n, limit int) int {
 	a, b := 1, 1
-	for i := 2; i <= n; i++ {
+	for i := 2; i <= n && b <= limit && a <= math.MaxInt-b; i++ {
 		a, b = b, a+b
 	}
 	return b
@@ -104,9 +148,12 @@ func fibonacci(n int) int {
 
 // LinearBackoff increases delay linearly
 type LinearBackoff struct {
-	initial   time.Duration
-	increment time.Duration
-	max       time.Duration
+	initial    time.Duration
+	increment  time.Duration
+	max        time.Duration
+	min        time.Duration
+	firstExact bool
+	firstSet   bool
 }
 
 // Linear creates a backoff that increases linearly
@@ -125,12 +172,159 @@ func (b *LinearBackoff) WithMaxDelay(maxDelay time.Duration) *LinearBackoff {
 	return b
 }
 
+// WithMinDelay sets a floor no delay drops below, taking precedence over
+// the maximum
+func (b *LinearBackoff) WithMinDelay(minDelay time.Duration) *LinearBackoff {
+	b.min = minDelay
+	return b
+}
+
+// WithFirstDelayExact makes the first retry wait exactly initial instead of
+// initial + increment, i.e. delay = initial + (increment * (attempt - 1))
+func (b *LinearBackoff) WithFirstDelayExact(exact bool) *LinearBackoff {
+	b.firstExact = exact
+	b.firstSet = true
+	return b
+}
+
 func (b *LinearBackoff) Next(attempt int) time.Duration {
-	delay := b.initial + (b.increment * time.Duration(attempt))
+	if b.firstExact {
+		attempt--
+	}
+	return b.delay(attempt)
+}
+
+// delay returns initial + increment * n, clamped to the configured bounds
+func (b *LinearBackoff) delay(n int) time.Duration {
+	delay := b.initial + (b.increment * time.Duration(n))
 	if delay > b.max {
-		return b.max
+		return max(b.max, b.min)
+	}
+	return max(delay, b.min)
+}
+
+// ElapsedBackoffer is implemented by strategies whose delay depends on the
+// time elapsed since the first failure rather than on the attempt count.
+// The iterator prefers NextElapsed over Next when it is available.
+type ElapsedBackoffer interface {
+	NextElapsed(attempt int, elapsed time.Duration) time.Duration
+}
+
+// ElapsedBackoff grows the delay with wall time since the first failure
+type ElapsedBackoff struct {
+	initial time.Duration
+	max     time.Duration
+	min     time.Duration
+	rampUp  time.Duration
+}
+
+// Elapsed creates a backoff that grows exponentially from initial to maxDelay
+// over rampUp of wall time since the first failure, then stays at maxDelay.
+// It behaves better than attempt-based growth when attempt durations vary widely.
+// delay = initial * (maxDelay/initial) ^ (elapsed/rampUp)
+func Elapsed(initial, maxDelay, rampUp time.Duration) Backoff {
+	return &ElapsedBackoff{
+		initial: initial,
+		max:     maxDelay,
+		rampUp:  rampUp,
+	}
+}
+
+// WithMinDelay sets a floor no delay drops below, taking precedence over
+// the maximum
+func (b *ElapsedBackoff) WithMinDelay(minDelay time.Duration) *ElapsedBackoff {
+	b.min = minDelay
+	return b
+}
+
+// Next returns the initial delay, since no elapsed time is known
+func (b *ElapsedBackoff) Next(attempt int) time.Duration {
+	return max(b.initial, b.min)
+}
+
+func (b *ElapsedBackoff) NextElapsed(attempt int, elapsed time.Duration) time.Duration {
+	if b.rampUp <= 0 || elapsed >= b.rampUp || b.initial <= 0 {
+		return max(b.max, b.min)
+	}
+	progress := float64(elapsed) / float64(b.rampUp)
+	delay := float64(b.initial) * math.Pow(float64(b.max)/float64(b.initial), progress)
+	if delay > float64(b.max) {
+		return max(b.max, b.min)
+	}
+	return max(time.Duration(delay), b.min)
+}
+
+// JitterBackoff randomizes the delays of another strategy
+type JitterBackoff struct {
+	base     Backoff
+	fraction float64
+	min      time.Duration
+}
+
+// Jitter wraps b so each delay is drawn uniformly from
+// [delay * (1 - fraction), delay], spreading out retries from many clients
+// that failed at the same moment. fraction is clamped to [0, 1].
+func Jitter(b Backoff, fraction float64) Backoff {
+	return &JitterBackoff{base: b, fraction: min(max(fraction, 0), 1)}
+}
+
+// WithMinDelay sets a floor no jittered delay drops below, for downstream
+// rate limits that require a minimum spacing between calls
+func (b *JitterBackoff) WithMinDelay(minDelay time.Duration) *JitterBackoff {
+	b.min = minDelay
+	return b
+}
+
+func (b *JitterBackoff) Next(attempt int) time.Duration {
+	return b.jitter(b.base.Next(attempt))
+}
+
+// NextElapsed jitters the base strategy's elapsed delay when it has one
+func (b *JitterBackoff) NextElapsed(attempt int, elapsed time.Duration) time.Duration {
+	if eb, ok := b.base.(ElapsedBackoffer); ok {
+		return b.jitter(eb.NextElapsed(attempt, elapsed))
+	}
+	return b.Next(attempt)
+}
+
+func (b *JitterBackoff) jitter(delay time.Duration) time.Duration {
+	spread := float64(delay) * b.fraction
+	delay -= time.Duration(rand.Float64() * spread) //nolint:gosec // jitter needs no crypto randomness
+	return max(delay, b.min)
+}
+
+// CappedBackoff clamps the delays of another strategy to a maximum
+type CappedBackoff struct {
+	base Backoff
+	max  time.Duration
+}
+
+// CapBackoff wraps b so no delay exceeds maxDelay. It works with any
+// strategy, including custom ones whose maximum isn't configurable.
+func CapBackoff(b Backoff, maxDelay time.Duration) Backoff {
+	return &CappedBackoff{base: b, max: maxDelay}
+}
+
+func (b *CappedBackoff) Next(attempt int) time.Duration {
+	return min(b.base.Next(attempt), b.max)
+}
+
+// NextElapsed caps the base strategy's elapsed delay when it has one
+func (b *CappedBackoff) NextElapsed(attempt int, elapsed time.Duration) time.Duration {
+	if eb, ok := b.base.(ElapsedBackoffer); ok {
+		return min(eb.NextElapsed(attempt, elapsed), b.max)
+	}
+	return b.Next(attempt)
+}
+
+// Schedule returns the delays b produces before each of the first n retries,
+// matching what an iterator sleeps before attempts 2 through n+1
+func Schedule(b Backoff, n int) []time.Duration {
+	delays := make([]time.Duration, n)
+	for i := range delays {
+		delays[i] = b.Next(i + 1)
 	}
-	return delay
+	return delays
 }
 
 // NoBackoff doesn't wait between retries
@@ -0,0 +1,104 @@
+package recur
+
+import (
+	"fmt"
+	"time"
+)
+
+// BehaviorVersion selects between the original semantics of the library and
+// later fixes that change observable behavior. Iterators and retriers use
+// V1 unless configured otherwise, so existing code keeps working and each
+// call site can opt in to newer behavior once it has been checked.
+type BehaviorVersion int
+
+const (
+	// V1 is the original behavior and the default
+	V1 BehaviorVersion = 1
+
+	// V2 opts in to the following fixes:
+	//
+	//   - Context error passthrough: when retrying stops because the cycle's
+	//     context is done, the final error wraps the context error, its cause
+	//     and the last attempt's error, even if attempts ran out at the same
+	//     time. V1 returns only ctx.Err(), or a MaxAttemptsExceededError if
+	//     attempts ran out.
+	//   - Attempt counting: a maximum below 1 still makes one attempt. V1
+	//     makes none and reports success.
+	//   - First delays: Linear and Exponential backoffs, including wrapped
+	//     by Jitter or CapBackoff, wait exactly their initial delay before
+	//     the first retry unless WithFirstDelayExact was called explicitly.
+	//     The backoff is read on every retry, so later changes to it apply.
+	V2 BehaviorVersion = 2
+)
+
+// WithBehaviorVersion selects the behavior version, see BehaviorVersion
+func (b *IteratorBuilder) WithBehaviorVersion(v BehaviorVersion) *IteratorBuilder {
+	b.behavior = v
+	return b
+}
+
+// Behavior returns a policy selecting the behavior version, see
+// BehaviorVersion
+func Behavior(v BehaviorVersion) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithBehaviorVersion(v)
+	}
+}
+
+// fixes reports whether the builder opted in to the behavior of version v
+func (b *IteratorBuilder) fixes(v BehaviorVersion) bool {
+	return b.behavior >= v
+}
+
+// attemptLimit returns the maximum attempts under the behavior version
+func (b *IteratorBuilder) attemptLimit() int {
+	if b.fixes(V2) {
+		return max(b.maxAttempts, 1)
+	}
+	return b.maxAttempts
+}
+
+// exactDelay returns the delay before the given retry under V2 if b is a
+// Linear or Exponential backoff left at the V1 default, possibly wrapped by
+// Jitter or CapBackoff, so it waits exactly the initial delay first. It
+// reads b on every call, so changes made to b after configuration apply
+// under both versions.
+func exactDelay(b Backoff, retry int) (time.Duration, bool) {
+	switch b := b.(type) {
+	case *ExponentialBackoff:
+		if b.firstSet {
+			return 0, false
+		}
+		return b.delay(retry - 1), true
+	case *LinearBackoff:
+		if b.firstSet {
+			return 0, false
+		}
+		return b.delay(retry - 1), true
+	case *JitterBackoff:
+		delay, ok := exactDelay(b.base, retry)
+		if !ok {
+			return 0, false
+		}
+		return b.jitter(delay), true
+	case *CappedBackoff:
+		delay, ok := exactDelay(b.base, retry)
+		if !ok {
+			return 0, false
+		}
+		return min(delay, b.max), true
+	}
+	return 0, false
+}
+
+// contextError is the final error of a cycle whose context is done
+func (s *iteratorState) contextError() error {
+	if !s.builder.fixes(V2) {
+		return s.ctx.Err()
+	}
+	err := causeError(s.ctx)
+	if last := s.lastAttempt; last != nil && last.result != nil {
+		err = fmt.Errorf("%w: %w", err, s.redact(last.result))
+	}
+	return err
+}
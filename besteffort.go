@@ -0,0 +1,31 @@
+package recur
+
+import "context"
+
+// RunBestEffort retries fn like a FuncR retrier configured with policies,
+// but when the attempts run out it returns the value from the most recent
+// attempt, even one accompanied by an error, together with the classified
+// final error. Pipelines that prefer degraded data over nothing can use
+// the value and log the error.
+//
+// Example:
+//
+//	prices, err := recur.RunBestEffort(ctx, fetchPrices, recur.MaxAttempts(3))
+//	if err != nil {
+//	    log.Printf("using partial prices: %v", err)
+//	}
+//	render(prices)
+func RunBestEffort[T any](ctx context.Context, fn func(ctx context.Context) (T, error), policies ...Policy) (T, error) {
+	config := Iter().WithPolicy(CombinePolicies(policies...))
+
+	var latest slot[T]
+	err := config.run(ctx, func(ctx context.Context) error {
+		v, err := fn(ctx)
+		latest.set(v)
+		if err == nil {
+			err = checkValue(config, nil, v)
+		}
+		return err
+	})
+	return latest.close(), err
+}
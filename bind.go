@@ -0,0 +1,43 @@
+package recur
+
+// Bind helpers adapt a method expression, its receiver and arguments into a
+// zero-argument operation, removing closure boilerplate when retrying calls
+// on client structs. The argument values are captured once, so every attempt
+// calls the method with the same receiver and arguments.
+//
+// Example:
+//
+//	fetch := recur.Bind1R(client, (*Client).GetUser, userID)
+//
+//	for attempt := range recur.Iter().Seq() {
+//	    user, err = fetch()
+//	    attempt.Result(err)
+//	}
+
+// Bind1 binds a receiver and one argument to a method returning only an error
+func Bind1[R, A any](recv R, method func(R, A) error, a A) func() error {
+	return func() error {
+		return method(recv, a)
+	}
+}
+
+// Bind2 binds a receiver and two arguments to a method returning only an error
+func Bind2[R, A, B any](recv R, method func(R, A, B) error, a A, b B) func() error {
+	return func() error {
+		return method(recv, a, b)
+	}
+}
+
+// Bind1R binds a receiver and one argument to a method returning a value and an error
+func Bind1R[R, A, T any](recv R, method func(R, A) (T, error), a A) func() (T, error) {
+	return func() (T, error) {
+		return method(recv, a)
+	}
+}
+
+// Bind2R binds a receiver and two arguments to a method returning a value and an error
+func Bind2R[R, A, B, T any](recv R, method func(R, A, B) (T, error), a A, b B) func() (T, error) {
+	return func() (T, error) {
+		return method(recv, a, b)
+	}
+}
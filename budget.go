@@ -0,0 +1,97 @@
+package recur
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Metadata keys used to propagate a retry budget to downstream services.
+// gRPC metadata lower-cases keys, which ExtractBudget handles transparently
+// since both http.Header and metadata.MD lookups are case-insensitive.
+const (
+	HeaderRetryAttempt   = "X-Retry-Attempt"
+	HeaderRetryRemaining = "X-Retry-Remaining"
+	HeaderRetryDeadline  = "X-Retry-Deadline"
+)
+
+// RetryBudget describes how much retrying the caller still has left
+type RetryBudget struct {
+	Attempt   int       // Current attempt number (1-based)
+	Remaining int       // Attempts the caller may still make after this one
+	Deadline  time.Time // Caller's deadline, zero if none
+}
+
+// UpstreamWillRetry reports whether the caller still has attempts left.
+// Downstream services can skip their own retries in that case to avoid
+// multiplying load across layers.
+func (b RetryBudget) UpstreamWillRetry() bool {
+	return b.Remaining > 0
+}
+
+// Budget returns the retry budget remaining at this attempt
+func (a *Attempt) Budget() RetryBudget {
+	budget := RetryBudget{
+		Attempt:   a.Number,
+		Remaining: max(a.maxRetry-a.Number, 0),
+	}
+	if deadline, ok := a.ctx.Deadline(); ok {
+		budget.Deadline = deadline
+	}
+	return budget
+}
+
+// InjectBudget writes budget into outgoing request metadata through set.
+//
+// Example:
+//
+//	recur.InjectBudget(req.Header.Set, attempt.Budget())
+//
+//	// gRPC
+//	md := metadata.MD{}
+//	recur.InjectBudget(func(k, v string) { md.Set(k, v) }, attempt.Budget())
+func InjectBudget(set func(key, value string), budget RetryBudget) {
+	set(HeaderRetryAttempt, strconv.Itoa(budget.Attempt))
+	set(HeaderRetryRemaining, strconv.Itoa(budget.Remaining))
+	if !budget.Deadline.IsZero() {
+		set(HeaderRetryDeadline, budget.Deadline.UTC().Format(time.RFC3339Nano))
+	}
+}
+
+// ExtractBudget parses a retry budget from incoming request metadata through get.
+// It returns false if the caller didn't propagate a budget.
+//
+// Example:
+//
+//	budget, ok := recur.ExtractBudget(r.Header.Get)
+func ExtractBudget(get func(key string) string) (RetryBudget, bool) {
+	attempt, err := strconv.Atoi(get(HeaderRetryAttempt))
+	if err != nil {
+		return RetryBudget{}, false
+	}
+	remaining, err := strconv.Atoi(get(HeaderRetryRemaining))
+	if err != nil {
+		return RetryBudget{}, false
+	}
+
+	budget := RetryBudget{Attempt: attempt, Remaining: remaining}
+	if v := get(HeaderRetryDeadline); v != "" {
+		if deadline, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			budget.Deadline = deadline
+		}
+	}
+	return budget, true
+}
+
+type budgetKey struct{}
+
+// ContextWithBudget returns a copy of ctx carrying the caller's retry budget
+func ContextWithBudget(ctx context.Context, budget RetryBudget) context.Context {
+	return context.WithValue(ctx, budgetKey{}, budget)
+}
+
+// BudgetFromContext returns the caller's retry budget stored in ctx, if any
+func BudgetFromContext(ctx context.Context) (RetryBudget, bool) {
+	budget, ok := ctx.Value(budgetKey{}).(RetryBudget)
+	return budget, ok
+}
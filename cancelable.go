@@ -0,0 +1,139 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrAbandoned reports that an operation ignored cancellation for longer
+// than its hard stop and was left running in the background
+var ErrAbandoned error = &codedError{code: CodeAbandoned, msg: "operation abandoned after hard stop"}
+
+// abandoned tracks abandoned operations that are still running
+var abandoned struct {
+	mu      sync.Mutex
+	running int64
+	idle    chan struct{} // Closed when running drops back to zero
+}
+
+// AbandonedOperations returns the number of operations abandoned by
+// RunCancelable or isolated attempts that haven't returned yet. A steadily
+// growing value means an operation ignores its context and leaks
+// goroutines.
+func AbandonedOperations() int64 {
+	abandoned.mu.Lock()
+	defer abandoned.mu.Unlock()
+	return abandoned.running
+}
+
+// WaitAbandoned blocks until every abandoned operation has returned, or
+// until ctx is done, whose error it then returns. Call it on shutdown to
+// join the goroutines of operations that ignored cancellation, or in tests
+// to check that none leak.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := recur.WaitAbandoned(ctx); err != nil {
+//	    log.Printf("%d abandoned operations still running", recur.AbandonedOperations())
+//	}
+func WaitAbandoned(ctx context.Context) error {
+	abandoned.mu.Lock()
+	idle := abandoned.idle
+	abandoned.mu.Unlock()
+	if idle == nil {
+		return nil
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// abandon counts an abandoned operation until done is closed
+func abandon(done <-chan error) {
+	abandoned.mu.Lock()
+	if abandoned.running == 0 {
+		abandoned.idle = make(chan struct{})
+	}
+	abandoned.running++
+	abandoned.mu.Unlock()
+
+	go func() {
+		<-done
+		abandoned.mu.Lock()
+		defer abandoned.mu.Unlock()
+		if abandoned.running--; abandoned.running == 0 {
+			close(abandoned.idle)
+			abandoned.idle = nil
+		}
+	}()
+}
+
+// RunCancelable runs op with a context canceled when ctx is done. If op
+// hasn't returned hardStop after cancellation, it is abandoned: RunCancelable
+// returns an ErrAbandoned error wrapping ctx's error, and op keeps running
+// in the background until it returns on its own. This bounds how long an
+// ill-behaved operation can hold up the retry loop.
+//
+// Example:
+//
+//	for attempt := range recur.Iter().WithTimeout(5 * time.Second).Seq() {
+//	    attempt.Result(attempt.RunCancelable(legacyClient.Call, time.Second))
+//	}
+func RunCancelable(ctx context.Context, op func(ctx context.Context) error, hardStop time.Duration) error {
+	return runCancelable(ctx, op, hardStop, nil)
+}
+
+// RunCancelable runs op under the attempt's context like the package-level
+// RunCancelable, also counting abandoned operations in the iterator's metrics
+func (a *Attempt) RunCancelable(op func(ctx context.Context) error, hardStop time.Duration) error {
+	return runCancelable(a.ctx, op, hardStop, a.metrics)
+}
+
+func runCancelable(ctx context.Context, op func(ctx context.Context) error, hardStop time.Duration, metrics *MetricsCollector) error {
+	opCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- op(opCtx)
+	}()
+
+	select {
+	case err := <-done:
+		cancel()
+		return err
+	case <-ctx.Done():
+		cancel()
+	}
+
+	timer := time.NewTimer(hardStop)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
+	select {
+	case err := <-done: // Returned just as the hard stop expired
+		return err
+	default:
+	}
+
+	abandon(done)
+	if metrics != nil {
+		metrics.AbandonedCount.Add(1)
+	}
+	return fmt.Errorf("%w after %v: %w", ErrAbandoned, hardStop, causeError(ctx))
+}
+
+// IsAbandoned checks if an operation was abandoned by RunCancelable
+func IsAbandoned(err error) bool {
+	return errors.Is(err, ErrAbandoned)
+}
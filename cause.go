@@ -0,0 +1,42 @@
+package recur
+
+import (
+	"context"
+	"fmt"
+)
+
+// Causes the engine cancels contexts with, returned by context.Cause so
+// operations and their downstream calls can tell why they were cut off.
+// The contexts' Err still returns context.Canceled or
+// context.DeadlineExceeded.
+var (
+	// ErrRetryTimeout is the cause when the cycle's WithTimeout expires
+	ErrRetryTimeout error = &codedError{code: CodeRetryTimeout, msg: "recur: retry timeout"}
+
+	// ErrAttemptTimeout is the cause when an attempt's WithAttemptTimeout
+	// expires
+	ErrAttemptTimeout error = &codedError{code: CodeAttemptTimeout, msg: "recur: attempt timeout"}
+
+	// ErrLifecycleDone is the cause when the lifecycle context set with
+	// Bind is done
+	ErrLifecycleDone error = &codedError{code: CodeLifecycleDone, msg: "recur: lifecycle context done"}
+
+	// ErrAttemptFailed is the cause when subtasks started with Attempt.Go
+	// are canceled because Result was called with an error. The cause also
+	// wraps that error.
+	ErrAttemptFailed error = &codedError{code: CodeAttemptFailed, msg: "recur: attempt failed"}
+
+	// ErrAttemptFinished is the cause when subtasks started with Attempt.Go
+	// are canceled because the iterator moved past their attempt
+	ErrAttemptFinished error = &codedError{code: CodeAttemptFinished, msg: "recur: attempt finished"}
+)
+
+// causeError returns ctx's error, wrapping its cause as well if they differ,
+// so callers can match both context.DeadlineExceeded and ErrRetryTimeout
+func causeError(ctx context.Context) error {
+	err := ctx.Err()
+	if cause := context.Cause(ctx); cause != err {
+		return fmt.Errorf("%w: %w", err, cause)
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package recur
+
+import (
+	"time"
+)
+
+// RetryableError is implemented by errors that know whether the operation
+// is worth retrying. The iterator honors it ahead of the configured matcher.
+type RetryableError interface {
+	error
+	Retryable() bool
+}
+
+// RetryAfterError is implemented by errors carrying a minimum delay before
+// the next attempt, such as a rate limit's Retry-After. The iterator waits
+// at least that long even if the backoff is shorter.
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+// ErrorClass is the retry verdict for an error, see Attempt.Classify
+type ErrorClass int
+
+const (
+	// ClassNone is the class of a nil error
+	ClassNone ErrorClass = iota
+	// ClassRetryable errors are worth retrying after the usual backoff
+	ClassRetryable
+	// ClassRateLimited errors are worth retrying no sooner than their
+	// RetryAfter delay
+	ClassRateLimited
+	// ClassFatal errors are not worth retrying
+	ClassFatal
+)
+
+// String returns the class's name
+func (c ErrorClass) String() string {
+	switch c {
+	case ClassNone:
+		return "none"
+	case ClassRetryable:
+		return "retryable"
+	case ClassRateLimited:
+		return "rate_limited"
+	case ClassFatal:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// classifiedError marks an error with retry behavior
+type classifiedError struct {
+	err       error
+	retryable bool
+	after     time.Duration
+}
+
+func (e *classifiedError) Error() string             { return e.err.Error() }
+func (e *classifiedError) Unwrap() error             { return e.err }
+func (e *classifiedError) Retryable() bool           { return e.retryable }
+func (e *classifiedError) RetryAfter() time.Duration { return e.after }
+
+// Transient marks err as retryable even if the configured matcher would
+// reject it. It returns nil if err is nil.
+//
+// Example:
+//
+//	if resp.StatusCode == http.StatusServiceUnavailable {
+//	    return recur.Transient(fmt.Errorf("upstream unavailable"))
+//	}
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, retryable: true}
+}
+
+// RateLimited marks err as retryable no sooner than retryAfter from now.
+// It returns nil if err is nil.
+func RateLimited(err error, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, retryable: true, after: retryAfter}
+}
+
+// Fatal marks err as not worth retrying, stopping the iterator even when
+// the matcher matches or fail-fast is disabled. It returns nil if err is nil.
+func Fatal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, retryable: false}
+}
+
+// Classify reports the retry decision carried by err through
+// RetryableError. ok is false if err carries none, leaving the decision to
+// the configured matcher.
+func Classify(err error) (retryable, ok bool) {
+	r, ok := asError[RetryableError](err)
+	if !ok {
+		return false, false
+	}
+	return r.Retryable(), true
+}
+
+// RetryAfter returns the minimum delay carried by err through
+// RetryAfterError, if any
+func RetryAfter(err error) (time.Duration, bool) {
+	r, ok := asError[RetryAfterError](err)
+	if !ok {
+		return 0, false
+	}
+	return r.RetryAfter(), true
+}
+
+// classify returns the class of err under matcher, letting an explicit
+// classification override matcher
+func classify(matcher ErrorMatcher, err error) ErrorClass {
+	if err == nil {
+		return ClassNone
+	}
+	if !retryable(matcher, err) {
+		return ClassFatal
+	}
+	if after, ok := RetryAfter(err); ok && after > 0 {
+		return ClassRateLimited
+	}
+	return ClassRetryable
+}
+
+// retryable decides whether err should be retried, letting an explicit
+// classification override matcher
+func retryable(matcher ErrorMatcher, err error) bool {
+	if r, ok := Classify(err); ok {
+		return r
+	}
+	return matcher(err)
+}
@@ -0,0 +1,80 @@
+package recur
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrCoordinated reports that a cycle made no attempts because another
+// instance holds its coordination key, see WithCoordinator
+var ErrCoordinated error = &codedError{code: CodeCoordinated, msg: "recur: operation coordinated by another instance"}
+
+// ErrCoordinatorUnavailable reports that a cycle made no attempts because
+// its Coordinator couldn't tell whether the key was free. It wraps the
+// error from TryAcquire.
+var ErrCoordinatorUnavailable error = &codedError{code: CodeCoordinatorUnavailable, msg: "recur: coordinator unavailable"}
+
+// Coordinator grants one instance in a fleet the right to run a shared
+// operation, typically through a distributed lock with a lease. See the
+// rediscoord package for a Redis implementation.
+type Coordinator interface {
+	// TryAcquire reports whether this instance now holds key. It must not
+	// wait for other holders to release it. An error means the holder
+	// couldn't be determined, such as when the backend is unreachable,
+	// and is reported instead of treating the key as held elsewhere.
+	TryAcquire(ctx context.Context, key string) (bool, error)
+
+	// Release gives up key after a successful TryAcquire
+	Release(ctx context.Context, key string)
+}
+
+// WithCoordinator makes each cycle acquire key from coordinator before its
+// first attempt and hold it until the cycle ends, so only one instance in a
+// fleet runs and retries an expensive shared operation such as a cache
+// rebuild. Cycles that cannot acquire key make no attempts and fail with
+// ErrCoordinated, or ErrCoordinatorUnavailable if TryAcquire fails, which
+// Seq loops see as the LastErr of a single attempt whose context is
+// already canceled. An empty key uses the operation name (see WithName).
+// Leases must outlast the longest cycle, which WithTimeout can bound.
+func (b *IteratorBuilder) WithCoordinator(coordinator Coordinator, key string) *IteratorBuilder {
+	b.coordinator = coordinator
+	b.coordinationKey = key
+	return b
+}
+
+// Coordinated returns a policy running cycles under a coordination key,
+// see IteratorBuilder.WithCoordinator
+func Coordinated(coordinator Coordinator, key string) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithCoordinator(coordinator, key)
+	}
+}
+
+// WithCoordinator runs calls under a coordination key, see
+// IteratorBuilder.WithCoordinator
+func (r *Retrier[F, C]) WithCoordinator(coordinator Coordinator, key string) *Retrier[F, C] {
+	r.config.WithCoordinator(coordinator, key)
+	return r
+}
+
+// coordinate acquires the cycle's coordination key, returning
+// ErrCoordinated if another instance holds it or ErrCoordinatorUnavailable
+// if the coordinator fails. The returned func releases the key.
+func (s *iteratorState) coordinate() (func(), error) {
+	c := s.builder.coordinator
+	if c == nil {
+		return func() {}, nil
+	}
+	key := s.builder.coordinationKey
+	if key == "" {
+		key = s.builder.operationName()
+	}
+	acquired, err := c.TryAcquire(s.ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCoordinatorUnavailable, err)
+	}
+	if !acquired {
+		return nil, ErrCoordinated
+	}
+	return func() { c.Release(context.WithoutCancel(s.ctx), key) }, nil
+}
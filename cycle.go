@@ -0,0 +1,93 @@
+package recur
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+)
+
+// HeaderRetryCycleID carries the retry cycle ID to downstream services
+const HeaderRetryCycleID = "X-Retry-Cycle-Id"
+
+type cycleIDKey struct{}
+
+// ContextWithCycleID returns a context carrying id. A retry cycle started
+// with this context reuses id instead of generating a new one, so a server
+// can continue the caller's cycle ID extracted from HeaderRetryCycleID.
+func ContextWithCycleID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, cycleIDKey{}, id)
+}
+
+// CycleIDFromContext returns the ID of the retry cycle ctx belongs to.
+// Every attempt of one cycle shares the ID, so it can correlate attempts
+// of one logical operation across logs and traces.
+//
+// Example:
+//
+//	for attempt := range recur.Iter().Seq() {
+//	    id, _ := recur.CycleIDFromContext(attempt.Context())
+//	    log.Printf("cycle %s attempt %d", id, attempt.Number)
+//	}
+func CycleIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(cycleIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// CycleID returns the ID of the retry cycle this attempt belongs to
+func (a *Attempt) CycleID() string {
+	id, _ := CycleIDFromContext(a.ctx)
+	return id
+}
+
+// InjectCycleID writes the cycle ID carried by ctx into outgoing request
+// metadata through set. It does nothing if ctx carries no cycle ID.
+//
+// Example:
+//
+//	recur.InjectCycleID(req.Header.Set, attempt.Context())
+func InjectCycleID(set func(key, value string), ctx context.Context) {
+	if id, ok := CycleIDFromContext(ctx); ok {
+		set(HeaderRetryCycleID, id)
+	}
+}
+
+// cycleContext carries a cycle's ID, generating it only when something
+// asks for it, so cycles nobody correlates don't pay for one
+type cycleContext struct {
+	context.Context
+	once sync.Once
+	id   string
+}
+
+// Value answers cycle ID lookups, deferring others to the parent
+func (c *cycleContext) Value(key any) any {
+	if _, ok := key.(cycleIDKey); ok {
+		c.once.Do(c.generate)
+		return c.id
+	}
+	return c.Context.Value(key)
+}
+
+func (c *cycleContext) generate() {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], rand.Uint64()) //nolint:gosec // correlation IDs need no crypto randomness
+	c.id = hex.EncodeToString(b[:])
+}
+
+// withCycleID returns ctx with a cycle ID unless it already carries one.
+// The ID is generated on first use.
+func (s *iteratorState) withCycleID(ctx context.Context) context.Context {
+	if _, ok := CycleIDFromContext(ctx); ok {
+		return ctx
+	}
+	s.cycle.Context = ctx
+	return &s.cycle
+}
+
+// cycleID returns the cycle's ID, generating it if needed
+func (s *iteratorState) cycleID() string {
+	id, _ := CycleIDFromContext(s.ctx)
+	return id
+}
@@ -0,0 +1,106 @@
+package recur
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// DebugTrace is the full history of a retry cycle sampled for debugging
+type DebugTrace struct {
+	Operation string
+	CycleID   string
+	Start     time.Time
+	Duration  time.Duration
+	Attempts  []DebugAttempt
+	Final     error // Why the cycle gave up, nil if it succeeded or the loop was exited
+	Policy    PolicyDescription
+}
+
+// DebugAttempt is one attempt of a DebugTrace
+type DebugAttempt struct {
+	Number      int
+	Delay       time.Duration // Backoff delay before the attempt
+	StartedAt   time.Time
+	Latency     time.Duration
+	Err         error // Error passed to Result, nil on success or if Result wasn't called
+	Diagnostics *AttemptDiagnostics
+}
+
+// WithDebugSampling records the full history of a rate fraction (0 to 1) of
+// cycles, with runtime diagnostics for every attempt, and passes it to emit
+// when the cycle ends. Sampled cycles also get every hook, sample and debug
+// log regardless of WithTelemetrySampling, so rare retry pathologies can be
+// debugged in production while the rest of the traffic stays lightweight.
+// Errors are redacted with WithErrorRedactor.
+//
+// Example:
+//
+//	recur.Iter().WithDebugSampling(0.001, func(trace recur.DebugTrace) {
+//	    if len(trace.Attempts) > 1 {
+//	        slog.Debug("retry trace", "cycle", trace.CycleID, "attempts", trace.Attempts)
+//	    }
+//	})
+func (b *IteratorBuilder) WithDebugSampling(rate float64, emit func(DebugTrace)) *IteratorBuilder {
+	b.debugRate = rate
+	b.debugEmit = emit
+	return b
+}
+
+// DebugSampling returns a policy tracing a fraction of cycles, see
+// IteratorBuilder.WithDebugSampling
+func DebugSampling(rate float64, emit func(DebugTrace)) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithDebugSampling(rate, emit)
+	}
+}
+
+// startTrace decides whether to trace the cycle
+func (s *iteratorState) startTrace() {
+	b := s.builder
+	if b.debugEmit == nil || b.debugRate <= 0 {
+		return
+	}
+	if b.debugRate < 1 && rand.Float64() >= b.debugRate { //nolint:gosec // sampling needs no crypto randomness
+		return
+	}
+	policy := b.Describe()
+	s.policy = &policy
+	s.sampled = true
+	s.trace = &DebugTrace{
+		Operation: b.operationName(),
+		CycleID:   s.cycleID(),
+		Start:     s.startTime,
+		Policy:    policy,
+	}
+}
+
+// traceAttempt adds att to the cycle's trace
+func (s *iteratorState) traceAttempt(att *Attempt, latency time.Duration) {
+	if s.trace == nil {
+		return
+	}
+	s.trace.Attempts = append(s.trace.Attempts, DebugAttempt{
+		Number:      att.Number,
+		Delay:       att.Delay,
+		StartedAt:   att.startedAt,
+		Latency:     latency,
+		Err:         s.redact(att.result),
+		Diagnostics: att.diag,
+	})
+}
+
+// traceFinal records why the traced cycle gave up
+func (s *iteratorState) traceFinal(final error) {
+	if s.trace != nil {
+		s.trace.Final = final
+	}
+}
+
+// emitTrace passes the finished trace to the builder's emitter
+func (s *iteratorState) emitTrace() {
+	if s.trace == nil {
+		return
+	}
+	s.trace.Duration = time.Since(s.startTime)
+	s.builder.debugEmit(*s.trace)
+}
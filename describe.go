@@ -0,0 +1,343 @@
+package recur
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PolicyDescription is a serializable view of a resolved retry configuration,
+// useful for startup logging and for diffing configuration changes
+type PolicyDescription struct {
+	Name        string             `json:"name,omitempty"`
+	Variant     string             `json:"variant,omitempty"`
+	MaxAttempts int                `json:"max_attempts"`
+	Backoff     BackoffDescription `json:"backoff"`
+	Timeout     string             `json:"timeout,omitempty"`
+	Matcher     string             `json:"matcher"`
+	Hooks       int                `json:"hooks"`
+	Metrics     string             `json:"metrics,omitempty"`
+	FailFast    bool               `json:"fail_fast"`
+	SampleRate  float64            `json:"sample_rate"`
+	MinDelay    string             `json:"min_delay,omitempty"`
+	MaxDelay    string             `json:"max_delay,omitempty"`
+}
+
+// BackoffDescription names a backoff strategy and its parameters
+type BackoffDescription struct {
+	Type   string            `json:"type"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// String renders the description as type(key=value, ...) with keys sorted,
+// such as "constant(delay=100ms, min=0s)"
+func (d BackoffDescription) String() string {
+	var b strings.Builder
+	b.WriteString(d.Type)
+	b.WriteByte('(')
+	for i, k := range slices.Sorted(maps.Keys(d.Params)) {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(d.Params[k])
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// Describe resolves policy against the defaults used by Iter and returns
+// the effective configuration
+func Describe(policy Policy) PolicyDescription {
+	return Iter().WithPolicy(policy).Describe()
+}
+
+// Describe returns the iterator's effective configuration
+func (b *IteratorBuilder) Describe() PolicyDescription {
+	desc := PolicyDescription{
+		Name:        b.operationName(),
+		Variant:     b.variant,
+		MaxAttempts: b.maxAttempts,
+		Backoff:     describeBackoff(b.backoff),
+		Matcher:     matcherName(b.matcher),
+		Hooks:       len(b.hooks),
+		FailFast:    b.failFast,
+		SampleRate:  b.sampleRate,
+	}
+	if b.timeout > 0 {
+		desc.Timeout = b.timeout.String()
+	}
+	if b.minDelay > 0 {
+		desc.MinDelay = b.minDelay.String()
+	}
+	if b.maxDelay > 0 {
+		desc.MaxDelay = b.maxDelay.String()
+	}
+	if b.metrics != nil {
+		desc.Metrics = b.metrics.Name()
+	}
+	return desc
+}
+
+// Describe returns the retrier's effective configuration
+func (r *Retrier[F, C]) Describe() PolicyDescription {
+	return r.config.Describe()
+}
+
+// DescribeBackoff returns a serializable description of backoff. Built-in
+// strategies also implement fmt.Stringer and json.Marshaler through it, so
+// they can be logged, diffed on reload and rendered in admin UIs.
+func DescribeBackoff(backoff Backoff) BackoffDescription {
+	return describeBackoff(backoff)
+}
+
+// ParseBackoff builds a backoff from a description produced by
+// DescribeBackoff, so strategies can be stored and served as JSON. Durations
+// use time.ParseDuration syntax, and optional parameters left out keep the
+// strategy's defaults. Custom strategies cannot be parsed.
+func ParseBackoff(d BackoffDescription) (Backoff, error) {
+	p := &backoffParams{params: d.Params}
+	var backoff Backoff
+	switch d.Type {
+	case "constant":
+		backoff = Constant(p.duration("delay", true)).(*ConstantBackoff).
+			WithMinDelay(p.duration("min", false))
+	case "exponential":
+		b := Exponential(p.duration("initial", true)).(*ExponentialBackoff).
+			WithMinDelay(p.duration("min", false))
+		if p.has("max") {
+			b.WithMaxDelay(p.duration("max", false))
+		}
+		if p.has("factor") {
+			b.WithFactor(p.float("factor"))
+		}
+		if p.bool("first_exact") {
+			b.WithFirstDelayExact(true)
+		}
+		backoff = b
+	case "fibonacci":
+		b := Fibonacci(p.duration("initial", true)).(*FibonacciBackoff).
+			WithMinDelay(p.duration("min", false))
+		if p.has("max") {
+			b.WithMaxDelay(p.duration("max", false))
+		}
+		backoff = b
+	case "linear":
+		b := Linear(p.duration("initial", true), p.duration("increment", true)).(*LinearBackoff).
+			WithMinDelay(p.duration("min", false))
+		if p.has("max") {
+			b.WithMaxDelay(p.duration("max", false))
+		}
+		if p.bool("first_exact") {
+			b.WithFirstDelayExact(true)
+		}
+		backoff = b
+	case "elapsed":
+		backoff = Elapsed(p.duration("initial", true), p.duration("max", true), p.duration("ramp_up", true)).(*ElapsedBackoff).
+			WithMinDelay(p.duration("min", false))
+	case "jitter":
+		base, err := ParseBackoff(baseDescription(d))
+		if err != nil {
+			return nil, err
+		}
+		backoff = Jitter(base, p.float("fraction")).(*JitterBackoff).
+			WithMinDelay(p.duration("min", false))
+	case "capped":
+		base, err := ParseBackoff(baseDescription(d))
+		if err != nil {
+			return nil, err
+		}
+		backoff = CapBackoff(base, p.duration("max", true))
+	case "none":
+		backoff = NoDelay()
+	default:
+		return nil, fmt.Errorf("recur: unknown backoff type %q", d.Type)
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("recur: %s backoff: %w", d.Type, p.err)
+	}
+	return backoff, nil
+}
+
+// baseDescription extracts the description of a wrapped strategy from the
+// base-prefixed parameters of a wrapper's description
+func baseDescription(d BackoffDescription) BackoffDescription {
+	base := BackoffDescription{Type: d.Params["base"], Params: map[string]string{}}
+	for k, v := range d.Params {
+		if rest, ok := strings.CutPrefix(k, "base_"); ok {
+			base.Params[rest] = v
+		}
+	}
+	return base
+}
+
+// backoffParams reads typed parameters of a backoff description, keeping
+// the first error
+type backoffParams struct {
+	params map[string]string
+	err    error
+}
+
+func (p *backoffParams) has(key string) bool {
+	_, ok := p.params[key]
+	return ok
+}
+
+func (p *backoffParams) fail(key string, err error) {
+	if p.err == nil {
+		p.err = fmt.Errorf("parameter %q: %w", key, err)
+	}
+}
+
+func (p *backoffParams) duration(key string, required bool) time.Duration {
+	v, ok := p.params[key]
+	if !ok {
+		if required {
+			p.fail(key, errors.New("missing"))
+		}
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return d
+}
+
+func (p *backoffParams) float(key string) float64 {
+	f, err := strconv.ParseFloat(p.params[key], 64)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return f
+}
+
+func (p *backoffParams) bool(key string) bool {
+	v, ok := p.params[key]
+	if !ok {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		p.fail(key, err)
+	}
+	return b
+}
+
+func (b *ConstantBackoff) String() string    { return describeBackoff(b).String() }
+func (b *ExponentialBackoff) String() string { return describeBackoff(b).String() }
+func (b *FibonacciBackoff) String() string   { return describeBackoff(b).String() }
+func (b *LinearBackoff) String() string      { return describeBackoff(b).String() }
+func (b *ElapsedBackoff) String() string     { return describeBackoff(b).String() }
+func (b *JitterBackoff) String() string      { return describeBackoff(b).String() }
+func (b *CappedBackoff) String() string      { return describeBackoff(b).String() }
+func (b *NoBackoff) String() string          { return describeBackoff(b).String() }
+
+func (b *ConstantBackoff) MarshalJSON() ([]byte, error)    { return json.Marshal(describeBackoff(b)) }
+func (b *ExponentialBackoff) MarshalJSON() ([]byte, error) { return json.Marshal(describeBackoff(b)) }
+func (b *FibonacciBackoff) MarshalJSON() ([]byte, error)   { return json.Marshal(describeBackoff(b)) }
+func (b *LinearBackoff) MarshalJSON() ([]byte, error)      { return json.Marshal(describeBackoff(b)) }
+func (b *ElapsedBackoff) MarshalJSON() ([]byte, error)     { return json.Marshal(describeBackoff(b)) }
+func (b *JitterBackoff) MarshalJSON() ([]byte, error)      { return json.Marshal(describeBackoff(b)) }
+func (b *CappedBackoff) MarshalJSON() ([]byte, error)      { return json.Marshal(describeBackoff(b)) }
+func (b *NoBackoff) MarshalJSON() ([]byte, error)          { return json.Marshal(describeBackoff(b)) }
+
+func describeBackoff(backoff Backoff) BackoffDescription {
+	switch b := backoff.(type) {
+	case *ConstantBackoff:
+		return BackoffDescription{Type: "constant", Params: map[string]string{
+			"delay": b.delay.String(),
+			"min":   b.min.String(),
+		}}
+	case *ExponentialBackoff:
+		return BackoffDescription{Type: "exponential", Params: map[string]string{
+			"initial":     b.initial.String(),
+			"factor":      strconv.FormatFloat(b.factor, 'g', -1, 64),
+			"max":         b.max.String(),
+			"min":         b.min.String(),
+			"first_exact": strconv.FormatBool(b.firstExact),
+		}}
+	case *FibonacciBackoff:
+		return BackoffDescription{Type: "fibonacci", Params: map[string]string{
+			"initial": b.initial.String(),
+			"max":     b.max.String(),
+			"min":     b.min.String(),
+		}}
+	case *LinearBackoff:
+		return BackoffDescription{Type: "linear", Params: map[string]string{
+			"initial":     b.initial.String(),
+			"increment":   b.increment.String(),
+			"max":         b.max.String(),
+			"min":         b.min.String(),
+			"first_exact": strconv.FormatBool(b.firstExact),
+		}}
+	case *ElapsedBackoff:
+		return BackoffDescription{Type: "elapsed", Params: map[string]string{
+			"initial": b.initial.String(),
+			"max":     b.max.String(),
+			"min":     b.min.String(),
+			"ramp_up": b.rampUp.String(),
+		}}
+	case *JitterBackoff:
+		base := describeBackoff(b.base)
+		params := map[string]string{
+			"fraction": strconv.FormatFloat(b.fraction, 'g', -1, 64),
+			"min":      b.min.String(),
+			"base":     base.Type,
+		}
+		for k, v := range base.Params {
+			params["base_"+k] = v
+		}
+		return BackoffDescription{Type: "jitter", Params: params}
+	case *CappedBackoff:
+		base := describeBackoff(b.base)
+		params := map[string]string{
+			"max":  b.max.String(),
+			"base": base.Type,
+		}
+		for k, v := range base.Params {
+			params["base_"+k] = v
+		}
+		return BackoffDescription{Type: "capped", Params: params}
+	case *NoBackoff:
+		return BackoffDescription{Type: "none"}
+	case nil:
+		return BackoffDescription{}
+	default:
+		return BackoffDescription{Type: fmt.Sprintf("%T", backoff)}
+	}
+}
+
+// matcherName prefers a matcher's registered name over its function name
+func matcherName(matcher ErrorMatcher) string {
+	if name, ok := registeredName(matcher); ok {
+		return name
+	}
+	return funcName(matcher)
+}
+
+// funcName returns a readable name for a function value, such as
+// "recur.MatchAny" or "recur.MatchErrors.func1" for a closure
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.Replace(name, "go-recur.", "recur.", 1)
+}
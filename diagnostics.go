@@ -0,0 +1,78 @@
+package recur
+
+import "runtime/metrics"
+
+// AttemptDiagnostics holds runtime stats sampled around one attempt. The
+// stats are process-wide, so concurrent work shows up too; look for trends
+// across many attempts rather than exact per-attempt figures.
+type AttemptDiagnostics struct {
+	AllocBytes     uint64 // Heap bytes allocated while the attempt ran
+	Goroutines     int    // Goroutines alive when the attempt finished
+	GoroutineDelta int    // Goroutines started and not finished during the attempt
+}
+
+// Runtime metrics read around each attempt
+const (
+	metricHeapAllocs = "/gc/heap/allocs:bytes"
+	metricGoroutines = "/sched/goroutines:goroutines"
+)
+
+// WithDiagnostics samples allocation and goroutine counts around each
+// attempt and attaches them to hook events, attempt samples and
+// Attempt.Diagnostics. It helps find wrapped operations that leak memory or
+// goroutines on every retry. Reading runtime metrics costs about a
+// microsecond per attempt, so leave it off in hot paths.
+func (b *IteratorBuilder) WithDiagnostics(enabled bool) *IteratorBuilder {
+	b.diagnostics = enabled
+	return b
+}
+
+// Diagnostics returns the attempt's runtime stats once its loop body has
+// finished, or nil if diagnostics are disabled
+func (a *Attempt) Diagnostics() *AttemptDiagnostics {
+	return a.diag
+}
+
+type runtimeProbe struct {
+	allocs     uint64
+	goroutines int
+}
+
+func readRuntimeProbe() runtimeProbe {
+	samples := []metrics.Sample{{Name: metricHeapAllocs}, {Name: metricGoroutines}}
+	metrics.Read(samples)
+
+	var p runtimeProbe
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		p.allocs = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		p.goroutines = int(samples[1].Value.Uint64())
+	}
+	return p
+}
+
+func (s *iteratorState) startDiagnostics() runtimeProbe {
+	if !s.diagnosing() {
+		return runtimeProbe{}
+	}
+	return readRuntimeProbe()
+}
+
+// diagnosing reports whether to sample runtime stats for the cycle's
+// attempts
+func (s *iteratorState) diagnosing() bool {
+	return s.builder.diagnostics || s.trace != nil
+}
+
+func (s *iteratorState) finishDiagnostics(att *Attempt, before runtimeProbe) {
+	if !s.diagnosing() {
+		return
+	}
+	after := readRuntimeProbe()
+	att.diag = &AttemptDiagnostics{
+		AllocBytes:     after.allocs - before.allocs,
+		Goroutines:     after.goroutines,
+		GoroutineDelta: after.goroutines - before.goroutines,
+	}
+}
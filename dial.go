@@ -0,0 +1,105 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// DialFunc is the signature of net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// DialAttempt records one failed connection attempt
+type DialAttempt struct {
+	Attempt  int
+	Endpoint string // Remote address reported by the dialer, or the dialed address
+	Duration time.Duration
+	Err      error
+}
+
+// DialError is returned by DialContextRetry when no connection could be
+// established. It unwraps to the classified retry error.
+type DialError struct {
+	Network  string
+	Address  string
+	Attempts []DialAttempt
+	Err      error
+}
+
+func (e *DialError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "dial %s %s: %v", e.Network, e.Address, e.Err)
+	for _, a := range e.Attempts {
+		fmt.Fprintf(&b, "; attempt %d to %s after %v: %v", a.Attempt, a.Endpoint, a.Duration, a.Err)
+	}
+	return b.String()
+}
+
+func (e *DialError) Unwrap() error {
+	return e.Err
+}
+
+// DialContextRetry wraps dial to retry connection establishment with
+// exponential backoff from 100ms up to 5s, followed by any policies.
+// A nil dial uses a zero net.Dialer. Under WithSuccessThreshold, only the
+// last connection is returned and earlier ones are closed.
+//
+// Example:
+//
+//	dial := recur.DialContextRetry((&net.Dialer{Timeout: 2 * time.Second}).DialContext,
+//	    recur.MaxAttempts(5))
+//	transport := &http.Transport{DialContext: dial}
+func DialContextRetry(dial DialFunc, policies ...Policy) DialFunc {
+	if dial == nil {
+		var d net.Dialer
+		dial = d.DialContext
+	}
+	config := Iter().
+		WithBackoff(Exponential(100 * time.Millisecond).(*ExponentialBackoff).WithMaxDelay(5 * time.Second)).
+		WithPolicy(CombinePolicies(policies...))
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		var conn net.Conn
+		var attempts []DialAttempt
+		var final error
+
+		for attempt := range config.seq(ctx, &final) {
+			start := time.Now()
+			c, err := dial(attempt.Context(), network, address)
+			if err != nil {
+				attempts = append(attempts, DialAttempt{
+					Attempt:  attempt.Number,
+					Endpoint: dialEndpoint(err, address),
+					Duration: time.Since(start),
+					Err:      err,
+				})
+			} else {
+				if conn != nil {
+					_ = conn.Close() // Superseded under WithSuccessThreshold
+				}
+				conn = c
+			}
+			attempt.Result(err)
+		}
+
+		if final != nil {
+			if conn != nil {
+				_ = conn.Close()
+			}
+			return nil, &DialError{Network: network, Address: address, Attempts: attempts, Err: final}
+		}
+		return conn, nil
+	}
+}
+
+// dialEndpoint returns the resolved address a dial error refers to, if any
+func dialEndpoint(err error, address string) string {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Addr != nil {
+		return opErr.Addr.String()
+	}
+	return address
+}
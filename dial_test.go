@@ -0,0 +1,96 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestDialContextRetry_Reconnects(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	calls := 0
+	var d net.Dialer
+	dial := DialContextRetry(func(ctx context.Context, network, address string) (net.Conn, error) {
+		calls++
+		if calls < 3 {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: syscall.ECONNREFUSED}
+		}
+		return d.DialContext(ctx, network, address)
+	}, WithBackoff(NoDelay()))
+
+	conn, err := dial(context.Background(), "tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Expected connection, got %v", err)
+	}
+	conn.Close()
+	if calls != 3 {
+		t.Errorf("Expected 3 dials, got %d", calls)
+	}
+}
+
+func TestDialContextRetry_ReportsAttempts(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	dial := DialContextRetry(nil, MaxAttempts(2), WithBackoff(NoDelay()))
+	_, err = dial(context.Background(), "tcp", addr)
+
+	var dialErr *DialError
+	if !errors.As(err, &dialErr) {
+		t.Fatalf("Expected DialError, got %v", err)
+	}
+	if !IsMaxAttemptsExceeded(err) {
+		t.Errorf("Expected DialError to unwrap to MaxAttemptsExceededError, got %v", err)
+	}
+	if len(dialErr.Attempts) != 2 {
+		t.Fatalf("Expected 2 recorded attempts, got %d", len(dialErr.Attempts))
+	}
+	if dialErr.Attempts[0].Endpoint != addr {
+		t.Errorf("Expected endpoint %s, got %s", addr, dialErr.Attempts[0].Endpoint)
+	}
+}
+
+// trackedConn records whether it was closed
+type trackedConn struct {
+	net.Conn
+	closed bool
+}
+
+func (c *trackedConn) Close() error {
+	c.closed = true
+	return c.Conn.Close()
+}
+
+func TestDialContextRetry_SuccessThresholdClosesSuperseded(t *testing.T) {
+	var conns []*trackedConn
+	dial := DialContextRetry(func(ctx context.Context, network, address string) (net.Conn, error) {
+		client, server := net.Pipe()
+		server.Close()
+		c := &trackedConn{Conn: client}
+		conns = append(conns, c)
+		return c, nil
+	}, WithBackoff(NoDelay()), SuccessThreshold(3), MaxAttempts(3))
+
+	conn, err := dial(context.Background(), "tcp", "db:5432")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if len(conns) != 3 || conn != conns[2] {
+		t.Fatalf("Expected the third connection, got %d dials", len(conns))
+	}
+	if !conns[0].closed || !conns[1].closed || conns[2].closed {
+		t.Error("Expected superseded connections to be closed and the returned one open")
+	}
+}
@@ -34,7 +34,8 @@
 //
 // # Functions with Arguments
 //
-// Support for functions with 0-2 arguments:
+// Support for functions with 0-2 arguments (Func0, Func1, Func2 and the
+// value-returning FuncR, Func1R, Func2R):
 //
 //	processItem := func(item string) error {
 //	    return process(item)
@@ -80,8 +81,8 @@
 // Add hooks to monitor retry behavior:
 //
 //	retryFunc := recur.Func0(fn).
-//	    OnRetry(func(ctx context.Context, attempt int, err error, elapsed time.Duration) {
-//	        log.Printf("[RETRY] Attempt %d: %v", attempt, err)
+//	    OnRetry(func(ctx context.Context, e recur.RetryEvent) {
+//	        log.Printf("[RETRY] Attempt %d: %v (next in %v)", e.Attempt, e.Err, e.NextDelay)
 //	    }).
 //	    Build()
 //
@@ -106,4 +107,13 @@
 //	    BuildContext()
 //
 //	err := retryFunc(context.Background())
+//
+// # Iterators
+//
+// The same configuration drives Go 1.23 range-over-func iterators when the
+// retry logic needs to live inline:
+//
+//	for attempt := range recur.Iter().WithPolicy(standardRetry).Seq() {
+//	    attempt.Result(doSomethingRisky())
+//	}
 package recur
@@ -0,0 +1,47 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+// DoWhile inverts retrying for drain loops: it calls fn again after every
+// success, waiting the backoff delay between calls to throttle, and stops
+// on fn's first error, which it returns. It also stops, returning nil, when
+// the maximum attempts run out, or with the cycle's error when it ends
+// otherwise, such as the context error when ctx or a Timeout policy is done
+// or ErrKillSwitch. It returns the number of successful calls. Attempts are
+// unlimited unless a policy sets them, and calls are spaced by the default
+// Constant(100ms) backoff unless a policy replaces it.
+//
+// Example:
+//
+//	// Drain a queue at most 10 messages per second
+//	n, err := recur.DoWhile(ctx, func(ctx context.Context) error {
+//	    return queue.ProcessOne(ctx)
+//	}, recur.WithBackoff(recur.Constant(100*time.Millisecond)))
+//	if errors.Is(err, queue.ErrEmpty) {
+//	    err = nil
+//	}
+func DoWhile(ctx context.Context, fn func(ctx context.Context) error, policies ...Policy) (int, error) {
+	config := Iter().
+		WithMaxAttempts(math.MaxInt).
+		WithPolicy(CombinePolicies(policies...))
+
+	var succeeded int
+	var final error
+	for attempt := range config.seq(ctx, &final) {
+		if err := fn(attempt.Context()); err != nil {
+			attempt.Result(err)
+			return succeeded, err
+		}
+		// Leaving the result unset moves on to the next call
+		succeeded++
+	}
+	var exhausted *MaxAttemptsExceededError
+	if errors.As(final, &exhausted) {
+		return succeeded, nil
+	}
+	return succeeded, final
+}
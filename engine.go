@@ -0,0 +1,88 @@
+package recur
+
+import (
+	"context"
+	"time"
+)
+
+// AttemptExecutor runs the attempts of an Engine and waits out the delays
+// between them, so attempts can run on custom schedulers such as worker
+// pools, test harnesses or virtual clocks
+type AttemptExecutor interface {
+	// Wait blocks for the backoff delay before an attempt, returning an
+	// error to end the cycle, typically the context's
+	Wait(ctx context.Context, delay time.Duration) error
+
+	// Execute runs op for attempt and returns its result
+	Execute(ctx context.Context, attempt *Attempt, op func(ctx context.Context) error) error
+}
+
+// DirectExecutor runs attempts on the calling goroutine and waits with a
+// timer, like iterators and function retriers do
+type DirectExecutor struct{}
+
+// Wait implements AttemptExecutor
+func (DirectExecutor) Wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Execute implements AttemptExecutor
+func (DirectExecutor) Execute(ctx context.Context, _ *Attempt, op func(ctx context.Context) error) error {
+	return op(ctx)
+}
+
+// Engine is the retry loop behind iterators and function retriers with a
+// pluggable AttemptExecutor. Policies, backoff, matchers, hooks and metrics
+// work as they do elsewhere; only where attempts run and how delays are
+// waited out is up to the executor.
+//
+// Example:
+//
+//	// Run attempts on a bounded worker pool
+//	engine := recur.NewEngine(poolExecutor{pool}, recur.MaxAttempts(5))
+//	err := engine.Run(ctx, callBackend)
+type Engine struct {
+	config   *IteratorBuilder
+	executor AttemptExecutor
+}
+
+// NewEngine creates an engine running attempts with executor, configured
+// like Iter followed by any policies. A nil executor is a DirectExecutor.
+func NewEngine(executor AttemptExecutor, policies ...Policy) *Engine {
+	if executor == nil {
+		executor = DirectExecutor{}
+	}
+	config := Iter().WithPolicy(CombinePolicies(policies...))
+	config.wait = executor.Wait
+	return &Engine{config: config, executor: executor}
+}
+
+// WithPolicy applies a policy to the engine
+func (e *Engine) WithPolicy(policy Policy) *Engine {
+	e.config.WithPolicy(policy)
+	return e
+}
+
+// Run executes op with retries under ctx and returns nil on success, or
+// the same classified error reported in give-up events
+func (e *Engine) Run(ctx context.Context, op func(ctx context.Context) error) error {
+	var final error
+	for attempt := range e.config.seq(ctx, &final) {
+		attempt.Result(e.executor.Execute(attempt.Context(), attempt, func(ctx context.Context) error {
+			return e.config.execute(ctx, attempt, op)
+		}))
+	}
+	return final
+}
+
+// Describe returns the engine's effective configuration
+func (e *Engine) Describe() PolicyDescription {
+	return e.config.Describe()
+}
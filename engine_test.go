@@ -0,0 +1,100 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// virtualExecutor advances a virtual clock instead of sleeping and runs
+// attempts on a separate goroutine
+type virtualExecutor struct {
+	now   time.Duration
+	waits []time.Duration
+}
+
+func (v *virtualExecutor) Wait(_ context.Context, delay time.Duration) error {
+	v.now += delay
+	v.waits = append(v.waits, delay)
+	return nil
+}
+
+func (v *virtualExecutor) Execute(ctx context.Context, _ *Attempt, op func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() { done <- op(ctx) }()
+	return <-done
+}
+
+func TestEngine_CustomExecutor(t *testing.T) {
+	exec := &virtualExecutor{}
+	engine := NewEngine(exec,
+		MaxAttempts(4),
+		WithBackoff(Exponential(time.Second)),
+	)
+
+	var calls int
+	err := engine.Run(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 4 {
+			return ErrTemporary
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
+	if !slices.Equal(exec.waits, want) || exec.now != 14*time.Second {
+		t.Errorf("Expected virtual waits %v, got %v", want, exec.waits)
+	}
+}
+
+type failingWaitExecutor struct{ DirectExecutor }
+
+var errSchedulerClosed = errors.New("scheduler closed")
+
+func (failingWaitExecutor) Wait(context.Context, time.Duration) error {
+	return errSchedulerClosed
+}
+
+func TestEngine_WaitError(t *testing.T) {
+	engine := NewEngine(failingWaitExecutor{}, WithBackoff(Constant(time.Millisecond)))
+	err := engine.Run(context.Background(), func(ctx context.Context) error {
+		return ErrTemporary
+	})
+	if !errors.Is(err, errSchedulerClosed) {
+		t.Errorf("Expected the executor's wait error, got %v", err)
+	}
+}
+
+func TestEngine_DirectExecutor(t *testing.T) {
+	engine := NewEngine(nil, WithBackoff(NoDelay()), RetryIf(MatchErrors(ErrTemporary)))
+	err := engine.Run(context.Background(), func(ctx context.Context) error {
+		return ErrFatal
+	})
+	var nonRetryable *NonRetryableError
+	if !errors.As(err, &nonRetryable) {
+		t.Errorf("Expected non-retryable error, got %v", err)
+	}
+}
+
+func TestEngine_FixedRate(t *testing.T) {
+	engine := NewEngine(nil, MaxAttempts(5), WithBackoff(Constant(30*time.Millisecond))).
+		WithSchedulingMode(FixedRate)
+
+	var starts []time.Time
+	err := engine.Run(context.Background(), func(ctx context.Context) error {
+		starts = append(starts, time.Now())
+		time.Sleep(10 * time.Millisecond)
+		return ErrTemporary
+	})
+	if !errors.Is(err, ErrTemporary) {
+		t.Fatalf("Expected the last attempt's error, got %v", err)
+	}
+	if elapsed := starts[4].Sub(starts[0]); elapsed < 115*time.Millisecond || elapsed > 150*time.Millisecond {
+		t.Errorf("Expected attempts 30ms apart regardless of latency, got %v over 4 intervals", elapsed)
+	}
+}
@@ -0,0 +1,39 @@
+package recur
+
+import "context"
+
+// ErrorEnricher adds context to the error of a failed attempt, such as the
+// endpoint or request ID, typically by wrapping it with fmt.Errorf and %w
+type ErrorEnricher func(ctx context.Context, attempt int, err error) error
+
+// WithErrorEnricher passes every failed attempt's error through enrich
+// when it is reported with Result, before matchers, hooks and final errors
+// see it, instead of enriching errors in every operation. enrich receives
+// the attempt's context and number. Enriched errors must wrap the original
+// so matchers and explicit classification still find it; a nil return
+// keeps the original error.
+//
+// Example:
+//
+//	r.WithErrorEnricher(func(ctx context.Context, attempt int, err error) error {
+//	    return fmt.Errorf("%s (request %s, attempt %d): %w", endpoint, requestID(ctx), attempt, err)
+//	})
+func (b *IteratorBuilder) WithErrorEnricher(enrich ErrorEnricher) *IteratorBuilder {
+	b.enrich = enrich
+	return b
+}
+
+// EnrichErrors returns a policy enriching attempt errors, see
+// IteratorBuilder.WithErrorEnricher
+func EnrichErrors(enrich ErrorEnricher) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithErrorEnricher(enrich)
+	}
+}
+
+// WithErrorEnricher enriches attempt errors, see
+// IteratorBuilder.WithErrorEnricher
+func (r *Retrier[F, C]) WithErrorEnricher(enrich ErrorEnricher) *Retrier[F, C] {
+	r.config.WithErrorEnricher(enrich)
+	return r
+}
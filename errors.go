@@ -3,28 +3,177 @@ package recur
 import (
 	"errors"
 	"fmt"
+	"log"
+	"reflect"
+	"sync"
 )
 
+// Stable codes identifying library errors, suitable for log pipelines and
+// switch statements
+const (
+	CodeMaxAttemptsExceeded = "max_attempts_exceeded"
+	CodeNonRetryable        = "non_retryable"
+	CodeKillSwitch          = "kill_switch"
+	CodeAbandoned           = "abandoned"
+	CodeNegativeCached      = "negative_cached"
+	CodeRetryTimeout        = "retry_timeout"
+	CodeAttemptTimeout      = "attempt_timeout"
+	CodeLifecycleDone       = "lifecycle_done"
+	CodeCoordinated         = "coordinated"
+	CodeTimeoutConflict     = "timeout_conflict"
+	CodeHookPanic           = "hook_panic"
+	CodeHookEventDropped    = "hook_event_dropped"
+	CodeAttemptFailed       = "attempt_failed"
+	CodeAttemptFinished     = "attempt_finished"
+	CodeZeroValue           = "zero_value"
+	CodePostCondition       = "post_condition"
+	CodeConnClosed          = "conn_closed"
+	CodeNoHealthyTargets    = "no_healthy_targets"
+	CodeThresholdNotMet     = "success_threshold_not_met"
+	CodeHandlerStatus       = "handler_status"
+
+	CodeCoordinatorUnavailable = "coordinator_unavailable"
+)
+
+// RecurError is implemented by all errors produced by this library, except
+// wrappers such as DialError, which carry the code of the error they wrap,
+// and stand-ins for operation failures such as loadtest.ErrInjected
+type RecurError interface {
+	error
+	Code() string
+}
+
+// ErrorCode returns the code of the first RecurError in err's chain, or ""
+// if err didn't originate from this library
+func ErrorCode(err error) string {
+	if e, ok := asError[RecurError](err); ok {
+		return e.Code()
+	}
+	return ""
+}
+
+// asError is errors.As for an interface target. It walks the chain with
+// type assertions so the target stays off the heap, falling back to
+// errors.As only for errors with their own As method.
+func asError[T error](err error) (target T, ok bool) {
+	for err != nil {
+		if target, ok = err.(T); ok {
+			return target, true
+		}
+		switch x := err.(type) {
+		case interface{ As(any) bool }:
+			var t T
+			ok = errors.As(err, &t)
+			return t, ok
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				if target, ok = asError[T](err); ok {
+					return target, true
+				}
+			}
+			return target, false
+		default:
+			return target, false
+		}
+	}
+	return target, false
+}
+
+// codedError is a sentinel error with a stable code
+type codedError struct {
+	code string
+	msg  string
+}
+
+func (e *codedError) Error() string {
+	return e.msg
+}
+
+func (e *codedError) Code() string {
+	return e.code
+}
+
+// FailureInfo describes a final failure for custom error formatting
+type FailureInfo struct {
+	Code     string // Stable error code, see ErrorCode
+	Attempts int    // Number of attempts made
+	Err      error  // Error from the last attempt
+}
+
+// ErrorFormatter renders the message of a final failure error. It lets
+// organizations add ticket links or operation names, or redact upstream
+// error text, without changing the error's type or unwrapping behavior.
+type ErrorFormatter func(info FailureInfo) string
+
 // MaxAttemptsExceededError is returned when all retry attempts have been exhausted
 type MaxAttemptsExceededError struct {
-	Attempts int
+	Attempts int // Total attempts made, including the first
 	LastErr  error
+	CycleID  string // Retry cycle that gave up, see CycleIDFromContext
+	format   ErrorFormatter
 }
 
 func (e *MaxAttemptsExceededError) Error() string {
-	return fmt.Sprintf("max attempts (%d) exceeded: %v", e.Attempts, e.LastErr)
+	if e.format != nil {
+		return e.format(FailureInfo{Code: CodeMaxAttemptsExceeded, Attempts: e.Attempts, Err: e.LastErr})
+	}
+	return fmt.Sprintf("max attempts exceeded after %d total attempts (%d retries): %v", e.Attempts, max(e.Attempts-1, 0), e.LastErr)
 }
 
 func (e *MaxAttemptsExceededError) Unwrap() error {
 	return e.LastErr
 }
 
+func (e *MaxAttemptsExceededError) Code() string {
+	return CodeMaxAttemptsExceeded
+}
+
 // IsMaxAttemptsExceeded checks if the error is a MaxAttemptsExceededError
 func IsMaxAttemptsExceeded(err error) bool {
 	var e *MaxAttemptsExceededError
 	return errors.As(err, &e)
 }
 
+// ErrNonRetryable matches, via errors.Is, any failure that stopped retrying
+// because the error matcher rejected it
+var ErrNonRetryable error = &codedError{code: CodeNonRetryable, msg: "non-retryable error"}
+
+// NonRetryableError is returned when retrying stops early because the error
+// matcher rejected the attempt's error
+type NonRetryableError struct {
+	Attempt int
+	Err     error
+	CycleID string // Retry cycle that gave up, see CycleIDFromContext
+	format  ErrorFormatter
+}
+
+func (e *NonRetryableError) Error() string {
+	if e.format != nil {
+		return e.format(FailureInfo{Code: CodeNonRetryable, Attempts: e.Attempt, Err: e.Err})
+	}
+	return fmt.Sprintf("non-retryable error on attempt %d: %v", e.Attempt, e.Err)
+}
+
+func (e *NonRetryableError) Unwrap() error {
+	return e.Err
+}
+
+func (e *NonRetryableError) Code() string {
+	return CodeNonRetryable
+}
+
+// Is reports whether target is ErrNonRetryable
+func (e *NonRetryableError) Is(target error) bool {
+	return target == ErrNonRetryable
+}
+
+// IsNonRetryable checks if retrying stopped because the error was not retryable
+func IsNonRetryable(err error) bool {
+	return errors.Is(err, ErrNonRetryable)
+}
+
 // ErrorMatcher is a function that determines if an error should trigger a retry
 type ErrorMatcher func(error) bool
 
@@ -50,25 +199,70 @@ func MatchErrors(targets ...error) ErrorMatcher {
 	}
 }
 
-// MatchTypes creates a matcher that retries only for specific error types.
-// Note: Due to Go's type system limitations, this uses errors.Is for comparison.
-// For custom type matching, use MatchFunc with type assertions.
+// MatchTypes creates a matcher that retries only for specific error values.
+//
+// Deprecated: MatchTypes compares with errors.Is and therefore matches
+// error values, not types, exactly like MatchErrors. Use MatchErrors for
+// values, MatchAs for static type matching or MatchTypeOf for runtime
+// type matching. A warning is logged the first time it is called.
+func MatchTypes(targets ...error) ErrorMatcher {
+	matchTypesWarning.Do(func() {
+		log.Printf("recur: MatchTypes is deprecated and matches error values, not types; use MatchErrors, MatchAs or MatchTypeOf")
+	})
+	return MatchErrors(targets...)
+}
+
+var matchTypesWarning sync.Once
+
+// MatchAs creates a matcher that retries when any error in the chain can be
+// assigned to T, as determined by errors.As. T must be an interface type or
+// implement error.
 //
 // Example:
 //
-//	var netErr *net.OpError
-//	matcher := MatchFunc(func(err error) bool {
-//	    return errors.As(err, &netErr)
-//	})
-func MatchTypes(targets ...error) ErrorMatcher {
+//	recur.MatchAs[*net.OpError]()
+//	recur.MatchAs[net.Error]()
+func MatchAs[T any]() ErrorMatcher {
+	checkAsTarget(reflect.TypeFor[T]())
 	return func(err error) bool {
-		for _, target := range targets {
-			// Use Is instead of As since we can't properly use As with interface{} targets
-			if errors.Is(err, target) {
-				return true
-			}
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// MatchTypeOf creates a matcher that retries when any error in the chain has
+// the same type as target, as determined by errors.As. Pass a typed nil
+// pointer to match pointer error types, or a pointer to an interface to
+// match an interface type.
+//
+// Example:
+//
+//	recur.MatchTypeOf((*net.OpError)(nil))
+//	recur.MatchTypeOf((*net.Error)(nil)) // any net.Error
+func MatchTypeOf(target any) ErrorMatcher {
+	typ := reflect.TypeOf(target)
+	if typ == nil {
+		panic("recur: MatchTypeOf target must be non-nil")
+	}
+	if typ.Kind() == reflect.Pointer && typ.Elem().Kind() == reflect.Interface {
+		typ = typ.Elem()
+	}
+	checkAsTarget(typ)
+
+	return func(err error) bool {
+		if err == nil {
+			return false
 		}
-		return false
+		return errors.As(err, reflect.New(typ).Interface())
+	}
+}
+
+var errorType = reflect.TypeFor[error]()
+
+// checkAsTarget panics early for types errors.As would reject at match time
+func checkAsTarget(typ reflect.Type) {
+	if typ.Kind() != reflect.Interface && !typ.Implements(errorType) {
+		panic(fmt.Sprintf("recur: %v is not an interface and does not implement error", typ))
 	}
 }
 
@@ -0,0 +1,139 @@
+package recur
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Overlap decides what a Periodic does with a tick that arrives while the
+// previous tick's run, including its retries, is still in progress
+type Overlap int
+
+const (
+	// SkipOverlapping drops the tick
+	SkipOverlapping Overlap = iota
+	// QueueOverlapping runs once more right after the current run ends.
+	// At most one tick is queued.
+	QueueOverlapping
+)
+
+// queued returns how many ticks may wait for the current run under o
+func (o Overlap) queued() int {
+	switch o {
+	case QueueOverlapping:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Periodic runs a function on a fixed interval, retrying each tick's run
+// with the configured policy. Runs never overlap.
+type Periodic struct {
+	config   *IteratorBuilder
+	fn       func(ctx context.Context) error
+	interval time.Duration
+	overlap  Overlap
+	onError  func(error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Every creates a periodic runner calling fn every interval, with the
+// default iterator configuration followed by any policies applied to each
+// tick. Call Start to begin.
+//
+// Example:
+//
+//	sync := recur.Every(time.Minute, syncInventory, recur.MaxAttempts(3)).
+//	    OnError(func(err error) { log.Printf("inventory sync: %v", err) })
+//	sync.Start(ctx)
+//	defer sync.Stop()
+func Every(interval time.Duration, fn func(ctx context.Context) error, policies ...Policy) *Periodic {
+	return &Periodic{
+		config:   Iter().WithPolicy(CombinePolicies(policies...)),
+		fn:       fn,
+		interval: interval,
+	}
+}
+
+// WithOverlap sets how ticks arriving during a run are handled. The
+// default is SkipOverlapping.
+func (p *Periodic) WithOverlap(overlap Overlap) *Periodic {
+	p.overlap = overlap
+	return p
+}
+
+// OnError sets fn to receive the classified error of every tick whose run
+// gave up
+func (p *Periodic) OnError(fn func(error)) *Periodic {
+	p.onError = fn
+	return p
+}
+
+// Start begins running fn every interval, the first time one interval from
+// now, until ctx is done or Stop is called. It does nothing if the runner
+// is already started.
+func (p *Periodic) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		return
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	done := make(chan struct{})
+	p.done = done
+	ticks := make(chan struct{}, p.overlap.queued())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticks:
+				if err := p.config.run(ctx, p.fn); err != nil && ctx.Err() == nil && p.onError != nil {
+					p.onError(err)
+				}
+			}
+		}
+	}()
+
+	go func() {
+		defer close(done)
+		defer wg.Wait()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case ticks <- struct{}{}:
+				default: // A run is in progress and the tick is skipped or already queued
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the runner and waits for a run in progress to return. The
+// run's context is canceled, so its retries end promptly.
+func (p *Periodic) Stop() {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
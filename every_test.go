@@ -0,0 +1,106 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvery_RetriesEachTick(t *testing.T) {
+	var calls atomic.Int32
+	errs := make(chan error, 10)
+	p := Every(10*time.Millisecond, func(ctx context.Context) error {
+		if calls.Add(1)%2 == 1 {
+			return errors.New("flaky")
+		}
+		return nil
+	}, MaxAttempts(2), WithBackoff(Constant(time.Millisecond))).
+		OnError(func(err error) { errs <- err })
+
+	p.Start(context.Background())
+	time.Sleep(55 * time.Millisecond)
+	p.Stop()
+
+	if got := calls.Load(); got < 4 {
+		t.Errorf("expected each tick to retry once, got %d calls", got)
+	}
+	select {
+	case err := <-errs:
+		t.Errorf("expected every tick to succeed on retry, got %v", err)
+	default:
+	}
+}
+
+func TestEvery_SkipOverlapping(t *testing.T) {
+	var runs atomic.Int32
+	p := Every(5*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		select {
+		case <-time.After(40 * time.Millisecond):
+		case <-ctx.Done():
+		}
+		return nil
+	}, MaxAttempts(1))
+
+	p.Start(context.Background())
+	time.Sleep(60 * time.Millisecond)
+	p.Stop()
+
+	if got := runs.Load(); got > 2 {
+		t.Errorf("expected overlapping ticks to be skipped, got %d runs", got)
+	}
+}
+
+func TestEvery_QueueOverlapping(t *testing.T) {
+	var active, maxActive, runs atomic.Int32
+	p := Every(5*time.Millisecond, func(ctx context.Context) error {
+		n := active.Add(1)
+		defer active.Add(-1)
+		if n > maxActive.Load() {
+			maxActive.Store(n)
+		}
+		runs.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}, MaxAttempts(1)).WithOverlap(QueueOverlapping)
+
+	p.Start(context.Background())
+	time.Sleep(70 * time.Millisecond)
+	p.Stop()
+
+	if got := maxActive.Load(); got != 1 {
+		t.Errorf("expected runs never to overlap, got %d concurrent", got)
+	}
+	if got := runs.Load(); got < 3 {
+		t.Errorf("expected queued ticks to run back to back, got %d runs", got)
+	}
+}
+
+func TestEvery_StopCancelsRun(t *testing.T) {
+	started := make(chan struct{})
+	var once atomic.Bool
+	p := Every(time.Millisecond, func(ctx context.Context) error {
+		if once.CompareAndSwap(false, true) {
+			close(started)
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}, MaxAttempts(1))
+
+	p.Start(context.Background())
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		p.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not cancel the run in progress")
+	}
+	p.Stop() // Stopping twice is a no-op
+}
@@ -0,0 +1,83 @@
+package recur
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// ewmaAlpha weights the newest cycle in the moving averages, so they
+// reflect roughly the last 20 cycles
+const ewmaAlpha = 0.1
+
+// movingAverages tracks exponentially weighted moving averages of cycle
+// outcomes
+type movingAverages struct {
+	successRate        movingAverage
+	attemptsPerSuccess movingAverage
+}
+
+// movingAverage is an exponentially weighted moving average updated with
+// compare-and-swap, so recording cycles never takes a lock. The zero value
+// has no samples.
+type movingAverage struct {
+	bits atomic.Uint64 // Complemented float64 bits, zero until the first sample
+}
+
+// add folds sample into the average, starting from the first sample
+func (a *movingAverage) add(sample float64) {
+	for {
+		old := a.bits.Load()
+		next := sample
+		if old != 0 {
+			avg := math.Float64frombits(^old)
+			next = avg + ewmaAlpha*(sample-avg)
+		}
+		if a.bits.CompareAndSwap(old, ^math.Float64bits(next)) {
+			return
+		}
+	}
+}
+
+// load returns the average and whether it has a sample
+func (a *movingAverage) load() (float64, bool) {
+	bits := a.bits.Load()
+	if bits == 0 {
+		return 0, false
+	}
+	return math.Float64frombits(^bits), true
+}
+
+// recordSuccessRate folds one cycle outcome into the success rate
+func (m *MetricsCollector) recordSuccessRate(success bool) {
+	outcome := 0.0
+	if success {
+		outcome = 1
+	}
+	m.averages.successRate.add(outcome)
+}
+
+// recordCycle counts one completed cycle that made attempts attempts
+func (m *MetricsCollector) recordCycle(success bool, attempts int) {
+	m.RecordOutcome(success)
+	if success {
+		m.averages.attemptsPerSuccess.add(float64(attempts))
+	}
+}
+
+// SuccessRate returns the exponentially weighted moving average of cycle
+// outcomes, from 0 to 1, weighted towards roughly the last 20 cycles. It
+// is zero until a cycle completes. Cycles recorded with RecordOutcome
+// count too.
+func (m *MetricsCollector) SuccessRate() float64 {
+	rate, _ := m.averages.successRate.load()
+	return rate
+}
+
+// AttemptsPerSuccess returns the exponentially weighted moving average of
+// attempts successful cycles needed. It is zero until a cycle succeeds.
+// A value creeping above 1 shows a dependency degrading before failures
+// surface.
+func (m *MetricsCollector) AttemptsPerSuccess() float64 {
+	attempts, _ := m.averages.attemptsPerSuccess.load()
+	return attempts
+}
@@ -0,0 +1,115 @@
+package recur
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// WithPolicySelector consults selector at the start of every retry cycle
+// with the cycle's context. A non-nil result is applied on top of this
+// iterator's configuration for that cycle only, so a caller's feature-flag
+// system can route a fraction of traffic to an experimental policy. Wrap
+// experimental policies in Variant to compare them in metrics.
+//
+// Example:
+//
+//	experiment := recur.Variant("fast-backoff", recur.WithBackoff(recur.Constant(10*time.Millisecond)))
+//	r.WithPolicy(recur.PolicySelector(func(ctx context.Context) recur.Policy {
+//	    if flags.Enabled(ctx, "retry-experiment") {
+//	        return experiment
+//	    }
+//	    return nil
+//	}))
+func (b *IteratorBuilder) WithPolicySelector(selector func(ctx context.Context) Policy) *IteratorBuilder {
+	b.selector = selector
+	return b
+}
+
+// PolicySelector creates a policy that picks a policy per retry cycle, see
+// IteratorBuilder.WithPolicySelector
+func PolicySelector(selector func(ctx context.Context) Policy) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithPolicySelector(selector)
+	}
+}
+
+// Variant creates a policy applying policies under an experiment name. The
+// name is reported in Describe, and cycles running the variant record into
+// a per-variant collector of the iterator's metrics (see
+// MetricsCollector.Variant) instead of the collector itself, so the
+// variant can be compared against the rest of the traffic.
+func Variant(name string, policies ...Policy) Policy {
+	return func(b *IteratorBuilder) {
+		b.variant = name
+		CombinePolicies(policies...)(b)
+		if b.metrics != nil {
+			b.metrics = b.metrics.Variant(name)
+		}
+	}
+}
+
+// selectPolicy returns the builder to run one cycle with, applying the
+// policy chosen by the selector if there is one, the warm-up policy during
+// warm-up, then any override carried by ctx. Builders without any of these
+// run as they are, without copying.
+func (b *IteratorBuilder) selectPolicy(ctx context.Context) *IteratorBuilder {
+	if b.selected {
+		return b
+	}
+	var policy Policy
+	if b.selector != nil {
+		policy = b.selector(ctx)
+	}
+	warmup := b.warmingUp()
+	override, ok := PolicyOverrideFromContext(ctx)
+	if policy == nil && !warmup && !ok {
+		return b
+	}
+	selected := b.clone()
+	selected.selected = true
+	if policy != nil {
+		policy(selected)
+	}
+	if warmup {
+		b.warmupPolicy(selected)
+	}
+	if ok {
+		override(selected)
+	}
+	return selected
+}
+
+// Variant returns the collector recording cycles that ran the named policy
+// variant, creating it on first use. Variant collectors share the parent's
+// name and are exported alongside it with a variant label.
+func (m *MetricsCollector) Variant(name string) *MetricsCollector {
+	if m.variant != "" {
+		return m
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.variants[name]; ok {
+		return v
+	}
+	if m.variants == nil {
+		m.variants = make(map[string]*MetricsCollector)
+	}
+	v := &MetricsCollector{name: m.name, variant: name}
+	m.variants[name] = v
+	return v
+}
+
+// Variants returns the collector's variant collectors sorted by variant name
+func (m *MetricsCollector) Variants() []*MetricsCollector {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	variants := make([]*MetricsCollector, 0, len(m.variants))
+	for _, v := range m.variants {
+		variants = append(variants, v)
+	}
+	slices.SortFunc(variants, func(a, b *MetricsCollector) int {
+		return cmp.Compare(a.variant, b.variant)
+	})
+	return variants
+}
@@ -0,0 +1,117 @@
+package recur
+
+import "sync/atomic"
+
+// fencingTokens issues attempt tokens process-wide, so tokens from later
+// attempts are always greater, across cycles and iterators
+var fencingTokens atomic.Uint64
+
+// ProcessTokens returns the next fencing token from a counter shared by the
+// whole process. Its tokens only order attempts within one process; fleets
+// fencing a shared resource need a source shared by every instance, such
+// as a database sequence.
+func ProcessTokens() uint64 {
+	return fencingTokens.Add(1)
+}
+
+// WithTokenSource issues each attempt a fencing token from source, see
+// Attempt.Token. source must return increasing values across every caller
+// whose completions are fenced against each other; nil uses ProcessTokens.
+// Without it attempts have no tokens and are never stale.
+//
+// Example:
+//
+//	recur.Iter().WithTokenSource(func() uint64 { return db.NextVal("fence") })
+func (b *IteratorBuilder) WithTokenSource(source func() uint64) *IteratorBuilder {
+	if source == nil {
+		source = ProcessTokens
+	}
+	b.tokenSource = source
+	return b
+}
+
+// FencingTokens returns a policy issuing fencing tokens from source, see
+// IteratorBuilder.WithTokenSource
+func FencingTokens(source func() uint64) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithTokenSource(source)
+	}
+}
+
+// WithTokenSource issues attempts fencing tokens from source, see
+// IteratorBuilder.WithTokenSource
+func (r *Retrier[F, C]) WithTokenSource(source func() uint64) *Retrier[F, C] {
+	r.config.WithTokenSource(source)
+	return r
+}
+
+// Token returns the attempt's fencing token, or 0 without WithTokenSource.
+// Tokens increase in the order attempts start, so a downstream system can
+// pass it to a Fence, or compare it with the highest token it has seen, to
+// reject writes from an attempt that timed out but completed after a newer
+// one started.
+func (a *Attempt) Token() uint64 {
+	return a.token
+}
+
+// Stale reports whether a newer attempt of the same cycle has started. Work
+// that outlives its attempt, such as a subtask ignoring cancellation, should
+// check it before committing side effects. It requires WithTokenSource.
+func (a *Attempt) Stale() bool {
+	return a.latest != nil && a.latest.Load() != a.token
+}
+
+// issueToken draws att's fencing token, if the cycle issues them
+func (s *iteratorState) issueToken(att *Attempt) {
+	source := s.builder.tokenSource
+	if source == nil {
+		return
+	}
+	if s.latestToken == nil {
+		s.latestToken = new(atomic.Uint64)
+	}
+	att.token = source()
+	att.latest = s.latestToken
+	s.latestToken.Store(att.token)
+}
+
+// Fence rejects completions carrying a token older than the newest one it
+// has accepted. The zero value is ready to use and safe for concurrent use.
+//
+// Example:
+//
+//	var fence recur.Fence
+//
+//	for attempt := range recur.Iter().WithTokenSource(nil).WithTimeout(time.Second).Seq() {
+//	    token := attempt.Token()
+//	    attempt.Go(func(ctx context.Context) error {
+//	        result := compute(ctx)
+//	        if !fence.Accept(token) {
+//	            return nil // a newer attempt already committed
+//	        }
+//	        return store.Save(result)
+//	    })
+//	    attempt.Result(attempt.Wait())
+//	}
+type Fence struct {
+	highest atomic.Uint64
+}
+
+// Accept records token and reports whether it is at least as new as every
+// token accepted before
+func (f *Fence) Accept(token uint64) bool {
+	for {
+		highest := f.highest.Load()
+		if token < highest {
+			return false
+		}
+		if f.highest.CompareAndSwap(highest, token) {
+			return true
+		}
+	}
+}
+
+// Highest returns the newest token accepted so far
+func (f *Fence) Highest() uint64 {
+	return f.highest.Load()
+}
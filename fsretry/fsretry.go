@@ -0,0 +1,77 @@
+// Package fsretry retries file operations that fail transiently on network
+// filesystems and Windows, such as sharing violations while an antivirus
+// scanner holds a file open or EBUSY on an NFS mount.
+//
+// Each helper mirrors its os counterpart and retries with DefaultPolicy
+// followed by any extra policies:
+//
+//	err := fsretry.Rename(tmp, path, recur.MaxAttempts(10))
+package fsretry
+
+import (
+	"io/fs"
+	"os"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+// DefaultPolicy retries Transient errors with short exponential backoff
+var DefaultPolicy = recur.CombinePolicies(
+	recur.MaxAttempts(5),
+	recur.WithBackoff(recur.Exponential(20*time.Millisecond).(*recur.ExponentialBackoff).WithMaxDelay(time.Second)),
+	recur.RetryIf(Transient),
+)
+
+// Transient reports whether err is a file operation error that commonly
+// clears up on its own on the current platform
+func Transient(err error) bool {
+	return isTransient(err)
+}
+
+// Rename retries os.Rename
+func Rename(oldpath, newpath string, policies ...recur.Policy) error {
+	return do(func() error { return os.Rename(oldpath, newpath) }, policies)
+}
+
+// Remove retries os.Remove
+func Remove(name string, policies ...recur.Policy) error {
+	return do(func() error { return os.Remove(name) }, policies)
+}
+
+// RemoveAll retries os.RemoveAll
+func RemoveAll(path string, policies ...recur.Policy) error {
+	return do(func() error { return os.RemoveAll(path) }, policies)
+}
+
+// WriteFile retries os.WriteFile
+func WriteFile(name string, data []byte, perm fs.FileMode, policies ...recur.Policy) error {
+	return do(func() error { return os.WriteFile(name, data, perm) }, policies)
+}
+
+// ReadFile retries os.ReadFile
+func ReadFile(name string, policies ...recur.Policy) ([]byte, error) {
+	return doValue(func() ([]byte, error) { return os.ReadFile(name) }, policies)
+}
+
+// Open retries os.Open
+func Open(name string, policies ...recur.Policy) (*os.File, error) {
+	return doValue(func() (*os.File, error) { return os.Open(name) }, policies)
+}
+
+// OpenFile retries os.OpenFile
+func OpenFile(name string, flag int, perm fs.FileMode, policies ...recur.Policy) (*os.File, error) {
+	return doValue(func() (*os.File, error) { return os.OpenFile(name, flag, perm) }, policies)
+}
+
+func do(fn func() error, policies []recur.Policy) error {
+	return recur.Func0(fn).WithPolicy(policy(policies)).Build()()
+}
+
+func doValue[T any](fn func() (T, error), policies []recur.Policy) (T, error) {
+	return recur.FuncR(fn).WithPolicy(policy(policies)).Build()()
+}
+
+func policy(policies []recur.Policy) recur.Policy {
+	return recur.CombinePolicies(append([]recur.Policy{DefaultPolicy}, policies...)...)
+}
@@ -0,0 +1,51 @@
+package fsretry
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+func TestWriteReadRename(t *testing.T) {
+	dir := t.TempDir()
+	tmp := filepath.Join(dir, "file.tmp")
+	path := filepath.Join(dir, "file")
+
+	if err := WriteFile(tmp, []byte("data"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := Rename(tmp, path); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	data, err := ReadFile(path)
+	if err != nil || string(data) != "data" {
+		t.Fatalf("ReadFile: %q, %v", data, err)
+	}
+	if err := Remove(path); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+}
+
+func TestNotExistIsNotRetried(t *testing.T) {
+	attempts := 0
+	count := recur.OnRetry(func(_ context.Context, e recur.RetryEvent) { attempts = e.Attempt })
+
+	_, err := Open(filepath.Join(t.TempDir(), "missing"), count)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected ErrNotExist, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestTransient(t *testing.T) {
+	if Transient(os.ErrNotExist) || Transient(nil) {
+		t.Error("Expected non-errno errors not to be transient")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package fsretry
+
+// isTransient matches nothing on platforms without known transient file
+// errors; pass recur.RetryIf to opt in
+func isTransient(err error) bool {
+	return false
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package fsretry
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isTransient matches busy files and stale or interrupted network
+// filesystem calls
+func isTransient(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	switch errno {
+	case syscall.EBUSY, syscall.ETXTBSY, syscall.EAGAIN, syscall.EINTR, syscall.ESTALE:
+		return true
+	}
+	return false
+}
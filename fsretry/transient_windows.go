@@ -0,0 +1,29 @@
+//go:build windows
+
+package fsretry
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Windows error codes not exported by the syscall package
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// isTransient matches files held open by another process. Access denied is
+// included because antivirus scanners and indexers briefly lock newly
+// written files.
+func isTransient(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	switch errno {
+	case errorSharingViolation, errorLockViolation, syscall.ERROR_ACCESS_DENIED:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package recur
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+)
+
+// DefaultMaxBodyBytes is the request body size a RetryHandler buffers for replays
+const DefaultMaxBodyBytes = 1 << 20
+
+// StatusError reports that a handler attempt responded with a retryable status
+type StatusError struct {
+	Status int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("handler responded %d %s", e.Status, http.StatusText(e.Status))
+}
+
+func (e *StatusError) Code() string {
+	return CodeHandlerStatus
+}
+
+// RetryHandler is server middleware that retries idempotent handler logic,
+// such as a lookup hitting a briefly unavailable store, before a 5xx reaches
+// the client. Responses are buffered per attempt and only the final one is
+// written. Create one per route to configure routes independently.
+type RetryHandler struct {
+	next      http.Handler
+	config    *IteratorBuilder
+	maxBody   int64
+	retryable func(status int) bool
+}
+
+// NewRetryHandler wraps next. By default it retries 500, 502, 503 and 504
+// responses to GET, HEAD, OPTIONS, PUT and DELETE requests with the Iter
+// defaults.
+//
+// Example:
+//
+//	mux.Handle("/orders/", recur.NewRetryHandler(orders).
+//	    WithPolicy(recur.MaxAttempts(2)).
+//	    WithMaxBodyBytes(64 << 10))
+func NewRetryHandler(next http.Handler) *RetryHandler {
+	return &RetryHandler{
+		next:      next,
+		config:    Iter(),
+		maxBody:   DefaultMaxBodyBytes,
+		retryable: isRetryableStatus,
+	}
+}
+
+// WithPolicy applies a policy to handler retries
+func (h *RetryHandler) WithPolicy(policy Policy) *RetryHandler {
+	h.config.WithPolicy(policy)
+	return h
+}
+
+// WithMaxBodyBytes sets the largest request body buffered for replays.
+// Requests with larger bodies are served once without retries.
+func (h *RetryHandler) WithMaxBodyBytes(n int64) *RetryHandler {
+	h.maxBody = n
+	return h
+}
+
+// RetryStatus sets which response statuses count as failed attempts
+func (h *RetryHandler) RetryStatus(retryable func(status int) bool) *RetryHandler {
+	h.retryable = retryable
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *RetryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !isIdempotent(r.Method) {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		buf, err := io.ReadAll(io.LimitReader(r.Body, h.maxBody+1))
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		if int64(len(buf)) > h.maxBody {
+			// Too large to replay: serve the rest of the stream once
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+			h.next.ServeHTTP(w, r)
+			return
+		}
+		body = buf
+	}
+
+	var last *bufferedResponse
+	for attempt := range h.config.seq(r.Context(), nil) {
+		last = newBufferedResponse(w.Header())
+		h.next.ServeHTTP(last, h.replay(attempt.Context(), r, body))
+
+		if h.retryable(last.status) {
+			attempt.Result(&StatusError{Status: last.status})
+		} else {
+			attempt.Result(nil)
+		}
+	}
+
+	if last == nil {
+		// Cancelled before the first attempt ran
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	last.flush(w)
+}
+
+func (h *RetryHandler) replay(ctx context.Context, r *http.Request, body []byte) *http.Request {
+	req := r.Clone(ctx)
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+	return req
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// bufferedResponse records one attempt's response so failed attempts never
+// reach the client
+type bufferedResponse struct {
+	header http.Header
+	status int
+	wrote  bool
+	body   bytes.Buffer
+}
+
+func newBufferedResponse(base http.Header) *bufferedResponse {
+	return &bufferedResponse{header: base.Clone(), status: http.StatusOK}
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wrote = true
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if !b.wrote {
+		b.status, b.wrote = status, true
+	}
+}
+
+func (b *bufferedResponse) flush(w http.ResponseWriter) {
+	header := w.Header()
+	clear(header)
+	maps.Copy(header, b.header)
+	w.WriteHeader(b.status)
+	_, _ = w.Write(b.body.Bytes())
+}
@@ -0,0 +1,71 @@
+package recur
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRetryHandler_RetriesTransientFailure(t *testing.T) {
+	calls := 0
+	h := NewRetryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if calls < 3 {
+			w.Header().Set("X-Attempt", "failed")
+			http.Error(w, "store unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(body)
+	})).WithPolicy(WithBackoff(NoDelay()))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/item", strings.NewReader("payload")))
+
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if rec.Code != http.StatusOK || rec.Body.String() != "payload" {
+		t.Errorf("Expected replayed body with 200, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("X-Attempt") != "" {
+		t.Error("Expected headers from failed attempts to be discarded")
+	}
+}
+
+func TestRetryHandler_ReturnsLastFailure(t *testing.T) {
+	calls := 0
+	h := NewRetryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	})).WithPolicy(CombinePolicies(MaxAttempts(2), WithBackoff(NoDelay())))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if calls != 2 || rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 2 calls ending in 502, got %d calls and %d", calls, rec.Code)
+	}
+}
+
+func TestRetryHandler_SkipsUnsafeAndOversized(t *testing.T) {
+	calls := 0
+	h := NewRetryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if len(body) != 8 {
+			t.Errorf("Expected full body, got %d bytes", len(body))
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})).WithMaxBodyBytes(4).WithPolicy(WithBackoff(NoDelay()))
+
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		calls = 0
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/", strings.NewReader("12345678")))
+		if calls != 1 {
+			t.Errorf("%s: expected a single call, got %d", method, calls)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package recur
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers written by SetRetryHint. Retry-After is the standard header;
+// the others carry details it can't express.
+const (
+	HeaderRetryAfter   = "Retry-After"
+	HeaderRetryAfterMs = "X-Retry-After-Ms"
+	HeaderRetryNo      = "X-Retry-No"
+	HeaderRetryReason  = "X-Retry-Reason"
+)
+
+// RetryHint is advice from a server about how clients should retry
+type RetryHint struct {
+	After   time.Duration // Minimum delay before the next attempt
+	NoRetry bool          // Request must not be retried
+	Reason  string        // Short machine-readable reason, e.g. "overloaded"
+}
+
+// SetRetryAfter writes a standard Retry-After header (whole seconds, rounded
+// up) plus a millisecond-precision variant for clients that understand it
+func SetRetryAfter(w http.ResponseWriter, d time.Duration) {
+	SetRetryHint(w, RetryHint{After: d})
+}
+
+// SetRetryHint writes hint as response headers. Call it before WriteHeader.
+//
+// Example:
+//
+//	recur.SetRetryHint(w, recur.RetryHint{After: 2 * time.Second, Reason: "overloaded"})
+//	w.WriteHeader(http.StatusServiceUnavailable)
+func SetRetryHint(w http.ResponseWriter, hint RetryHint) {
+	h := w.Header()
+	if hint.After > 0 {
+		seconds := (hint.After + time.Second - 1) / time.Second
+		h.Set(HeaderRetryAfter, strconv.FormatInt(int64(seconds), 10))
+		h.Set(HeaderRetryAfterMs, strconv.FormatInt(hint.After.Milliseconds(), 10))
+	}
+	if hint.NoRetry {
+		h.Set(HeaderRetryNo, "true")
+	}
+	if hint.Reason != "" {
+		h.Set(HeaderRetryReason, hint.Reason)
+	}
+}
+
+// ParseRetryHint reads a hint written by SetRetryHint, or a bare standard
+// Retry-After header in either delay-seconds or HTTP-date form. It returns
+// false if the response carries no hint.
+func ParseRetryHint(h http.Header) (RetryHint, bool) {
+	var hint RetryHint
+	found := false
+
+	if ms, err := strconv.ParseInt(h.Get(HeaderRetryAfterMs), 10, 64); err == nil && ms >= 0 {
+		hint.After = time.Duration(ms) * time.Millisecond
+		found = true
+	} else if v := h.Get(HeaderRetryAfter); v != "" {
+		if seconds, err := strconv.ParseInt(v, 10, 64); err == nil && seconds >= 0 {
+			hint.After = time.Duration(seconds) * time.Second
+			found = true
+		} else if at, err := http.ParseTime(v); err == nil {
+			hint.After = max(time.Until(at), 0)
+			found = true
+		}
+	}
+
+	if v, err := strconv.ParseBool(h.Get(HeaderRetryNo)); err == nil && v {
+		hint.NoRetry = true
+		found = true
+	}
+	if v := h.Get(HeaderRetryReason); v != "" {
+		hint.Reason = v
+		found = true
+	}
+
+	return hint, found
+}
@@ -0,0 +1,370 @@
+package recur
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryEvent describes a failed attempt and what the iterator will do next
+type RetryEvent struct {
+	Attempt     int           // Number of the attempt that failed (1-based)
+	MaxAttempts int           // Configured maximum number of attempts
+	Err         error         // Error passed to Result
+	Elapsed     time.Duration // Time since the cycle started
+	NextDelay   time.Duration // Delay before the next attempt, zero if none
+	WillRetry   bool          // Whether another attempt will be made
+
+	// Diagnostics holds runtime stats for the failed attempt when
+	// WithDiagnostics is enabled
+	Diagnostics *AttemptDiagnostics
+
+	// Operation names the retried operation, see WithName
+	Operation string
+
+	// CycleID identifies the retry cycle, see CycleIDFromContext
+	CycleID string
+
+	// Policy is the iterator's effective configuration, so a global hook can
+	// log complete context. Treat it as read-only.
+	Policy PolicyDescription
+
+	// Final explains why the cycle stopped when WillRetry is false: a
+	// *MaxAttemptsExceededError, a *NonRetryableError, ErrKillSwitch or
+	// the context error
+	Final error
+}
+
+// Hook is called after every attempt that reported a non-nil error via Result
+//
+// Example:
+//
+//	recur.Iter().OnRetry(func(ctx context.Context, e recur.RetryEvent) {
+//	    if e.WillRetry {
+//	        log.Printf("%v; retrying in %v (attempt %d/%d)", e.Err, e.NextDelay, e.Attempt+1, e.MaxAttempts)
+//	    }
+//	})
+type Hook func(ctx context.Context, event RetryEvent)
+
+// hookEntry is a registered hook with its ordering metadata
+type hookEntry struct {
+	name     string
+	priority int
+	fn       Hook
+}
+
+// OnRetry registers a hook called after each failed attempt, with priority 0.
+//
+// Hooks run in descending priority order. Hooks with equal priority run
+// global hooks first, then in registration order.
+func (b *IteratorBuilder) OnRetry(hook Hook) *IteratorBuilder {
+	b.hooks = append(b.hooks, hookEntry{fn: hook})
+	return b
+}
+
+// AddHook registers a named hook with the given priority, replacing any
+// hook already registered under name. Naming hooks lets telemetry layers
+// swap or remove each other's hooks on a Clone of a shared builder.
+//
+// Example:
+//
+//	base := recur.Iter().AddHook("tracing", 100, traceHook).AddHook("logging", 0, logHook)
+//	quiet := base.Clone().RemoveHook("logging")
+func (b *IteratorBuilder) AddHook(name string, priority int, hook Hook) *IteratorBuilder {
+	entry := hookEntry{name: name, priority: priority, fn: hook}
+	for i := range b.hooks {
+		if name != "" && b.hooks[i].name == name {
+			b.hooks[i] = entry
+			return b
+		}
+	}
+	b.hooks = append(b.hooks, entry)
+	return b
+}
+
+// RemoveHook removes the hook registered under name with AddHook, if any
+func (b *IteratorBuilder) RemoveHook(name string) *IteratorBuilder {
+	b.hooks = slices.DeleteFunc(b.hooks, func(e hookEntry) bool {
+		return e.name != "" && e.name == name
+	})
+	return b
+}
+
+// HookPanicError reports a hook that panicked while handling an event
+type HookPanicError struct {
+	Hook  string // Name the hook was added under with AddHook, if any
+	Value any    // Value passed to panic
+	Stack []byte // Stack trace of the panicking goroutine
+}
+
+func (e *HookPanicError) Error() string {
+	if e.Hook != "" {
+		return fmt.Sprintf("recur: hook %q panicked: %v", e.Hook, e.Value)
+	}
+	return fmt.Sprintf("recur: hook panicked: %v", e.Value)
+}
+
+func (e *HookPanicError) Code() string {
+	return CodeHookPanic
+}
+
+// OnHookError sets fn to receive a *HookPanicError whenever a hook panics.
+// The panic is recovered and the cycle continues with the remaining hooks,
+// so an observability bug can't take down the retried operation. Without
+// fn, panics are logged with the standard logger. See WithStrictHooks to
+// let them propagate instead.
+//
+// Example:
+//
+//	recur.Iter().OnHookError(func(err error) {
+//	    metrics.Inc("retry_hook_failures")
+//	    log.Print(err)
+//	})
+func (b *IteratorBuilder) OnHookError(fn func(err error)) *IteratorBuilder {
+	b.hookError = fn
+	return b
+}
+
+// WithStrictHooks lets hook panics propagate to the code ranging over the
+// iterator, aborting the cycle, as in tests that should fail on broken
+// hooks. Hooks are recovered by default, see OnHookError.
+func (b *IteratorBuilder) WithStrictHooks(enabled bool) *IteratorBuilder {
+	b.strictHooks = enabled
+	return b
+}
+
+// EventSink receives retry events, typically forwarding them to a logging
+// or metrics backend
+type EventSink interface {
+	Emit(ctx context.Context, event RetryEvent)
+}
+
+var (
+	globalHooksMu sync.Mutex
+	globalHooks   atomic.Pointer[[]*hookEntry]
+)
+
+// RegisterGlobalHook attaches hook to every iterator in the binary, ahead of
+// hooks registered with OnRetry. It returns a function that unregisters it.
+// Global hooks let platform teams add organization-wide logging or metrics
+// without each call site opting in.
+func RegisterGlobalHook(hook Hook) (unregister func()) {
+	return RegisterGlobalHookPriority(0, hook)
+}
+
+// RegisterGlobalHookPriority attaches hook to every iterator in the binary
+// with the given priority, ordered against local hooks as described on
+// OnRetry. It returns a function that unregisters it.
+func RegisterGlobalHookPriority(priority int, hook Hook) (unregister func()) {
+	entry := &hookEntry{priority: priority, fn: hook}
+
+	globalHooksMu.Lock()
+	defer globalHooksMu.Unlock()
+
+	var hooks []*hookEntry
+	if current := globalHooks.Load(); current != nil {
+		hooks = append(hooks, *current...)
+	}
+	hooks = append(hooks, entry)
+	globalHooks.Store(&hooks)
+
+	return func() {
+		globalHooksMu.Lock()
+		defer globalHooksMu.Unlock()
+
+		var remaining []*hookEntry
+		for _, h := range *globalHooks.Load() {
+			if h != entry {
+				remaining = append(remaining, h)
+			}
+		}
+		globalHooks.Store(&remaining)
+	}
+}
+
+// RegisterGlobalEventSink attaches sink to every iterator in the binary.
+// It returns a function that unregisters it.
+func RegisterGlobalEventSink(sink EventSink) (unregister func()) {
+	return RegisterGlobalHook(sink.Emit)
+}
+
+// loadGlobalHooks returns the currently registered global hooks
+func loadGlobalHooks() []*hookEntry {
+	if hooks := globalHooks.Load(); hooks != nil {
+		return *hooks
+	}
+	return nil
+}
+
+// hasHooks reports whether any global or local hooks are registered
+func (s *iteratorState) hasHooks() bool {
+	return len(s.builder.hooks) > 0 || len(loadGlobalHooks()) > 0
+}
+
+// notifyRetry fires hooks for the previous attempt ahead of the next one
+func (s *iteratorState) notifyRetry(next *Attempt) {
+	if s.lastAttempt != nil {
+		s.audit(DecisionRetry, next.Delay, ErrorCode(s.lastAttempt.result))
+	}
+	if !s.sampled {
+		return
+	}
+	s.notify(RetryEvent{NextDelay: next.Delay, WillRetry: true})
+}
+
+// notifyGiveUp fires hooks if the cycle ends on a failed attempt
+func (s *iteratorState) notifyGiveUp(exhausted bool) {
+	last := s.lastAttempt
+	if last == nil {
+		s.abort()
+		return
+	}
+	if s.lastSucceeded() {
+		s.audit(DecisionSuccess, 0, "")
+		return
+	}
+	s.rememberFailure(exhausted)
+	if !s.hasHooks() && s.builder.audit == nil && s.final == nil && s.trace == nil {
+		return
+	}
+
+	final := s.finalError(exhausted)
+	if s.final != nil {
+		*s.final = final
+	}
+	s.traceFinal(final)
+	s.audit(DecisionGiveUp, 0, ErrorCode(final))
+	s.notify(RetryEvent{Final: final})
+}
+
+// abort records the context error as the outcome when the cycle is canceled
+// before an attempt could run, or the error an Engine's executor failed to
+// wait with
+func (s *iteratorState) abort() {
+	err := s.waitErr
+	if s.ctx.Err() != nil {
+		err = s.contextError()
+	}
+	if s.final != nil {
+		*s.final = err
+	}
+	s.traceFinal(err)
+}
+
+// finalError classifies why retrying stopped after the last attempt
+func (s *iteratorState) finalError(exhausted bool) error {
+	last := s.lastAttempt
+	lastErr := s.redact(last.result)
+	if last.resultSet && lastErr == nil {
+		lastErr = ErrSuccessThresholdNotMet
+	}
+	switch {
+	case s.builder.fixes(V2) && s.isContextDone():
+		return s.contextError()
+	case exhausted:
+		return &MaxAttemptsExceededError{Attempts: last.Number, LastErr: lastErr, CycleID: s.cycleID(), format: s.builder.formatter}
+	case s.isContextDone():
+		return s.contextError()
+	case KillSwitchEngaged() && lastErr == nil:
+		return ErrKillSwitch
+	case KillSwitchEngaged():
+		return fmt.Errorf("%w: %w", ErrKillSwitch, lastErr)
+	default:
+		return &NonRetryableError{Attempt: last.Number, Err: lastErr, CycleID: s.cycleID(), format: s.builder.formatter}
+	}
+}
+
+func (s *iteratorState) notify(event RetryEvent) {
+	if s.notified || !s.hasHooks() {
+		return
+	}
+	last := s.lastAttempt
+	if last == nil || !last.resultSet || last.result == nil {
+		return
+	}
+
+	event.Attempt = last.Number
+	event.MaxAttempts = s.builder.attemptLimit()
+	event.Err = s.redact(last.result)
+	event.Elapsed = time.Since(s.startTime)
+	event.Diagnostics = last.diag
+	event.Operation = s.builder.operationName()
+	event.CycleID = s.cycleID()
+	if s.policy == nil {
+		policy := s.builder.Describe()
+		s.policy = &policy
+	}
+	event.Policy = *s.policy
+	s.dispatch(event)
+	s.notified = true
+}
+
+// dispatch runs the hooks for event, on the builder's hook queue if hooks
+// are asynchronous
+func (s *iteratorState) dispatch(event RetryEvent) {
+	b, hooks := s.builder, s.orderedHooks()
+	if b.hookQueue == nil {
+		for _, hook := range hooks {
+			b.callHook(s.ctx, hook, event, b.strictHooks)
+		}
+		return
+	}
+	ctx := context.WithoutCancel(s.ctx)
+	queued := b.hookQueue.push(func() {
+		for _, hook := range hooks {
+			b.callHook(ctx, hook, event, false)
+		}
+	})
+	if !queued {
+		b.hookFailed(ErrHookEventDropped)
+	}
+}
+
+// callHook runs hook, recovering a panic unless strict
+func (b *IteratorBuilder) callHook(ctx context.Context, hook hookEntry, event RetryEvent, strict bool) {
+	if !strict {
+		defer func() {
+			if v := recover(); v != nil {
+				b.hookFailed(&HookPanicError{Hook: hook.name, Value: v, Stack: debug.Stack()})
+			}
+		}()
+	}
+	hook.fn(ctx, event)
+}
+
+// hookFailed reports a hook failure to OnHookError, or logs it
+func (b *IteratorBuilder) hookFailed(err error) {
+	if b.hookError != nil {
+		b.hookError(err)
+		return
+	}
+	log.Print(err)
+}
+
+// orderedHooks returns global and local hooks in execution order
+func (s *iteratorState) orderedHooks() []hookEntry {
+	global := loadGlobalHooks()
+	hooks := make([]hookEntry, 0, len(global)+len(s.builder.hooks))
+	prioritized := false
+	for _, h := range global {
+		hooks = append(hooks, *h)
+		prioritized = prioritized || h.priority != 0
+	}
+	for _, h := range s.builder.hooks {
+		hooks = append(hooks, h)
+		prioritized = prioritized || h.priority != 0
+	}
+	if prioritized {
+		slices.SortStableFunc(hooks, func(a, b hookEntry) int {
+			return cmp.Compare(b.priority, a.priority)
+		})
+	}
+
+	return hooks
+}
@@ -0,0 +1,69 @@
+package recur
+
+import (
+	"context"
+	"time"
+)
+
+// WithAttemptTimeout bounds each attempt to d, on top of the whole cycle's
+// WithTimeout. The attempt's context is canceled when d elapses; operations
+// that ignore their context can be cut off with WithIsolatedAttempts.
+func (b *IteratorBuilder) WithAttemptTimeout(d time.Duration) *IteratorBuilder {
+	b.attemptTimeout = d
+	return b
+}
+
+// AttemptTimeout returns a policy bounding each attempt, see
+// IteratorBuilder.WithAttemptTimeout
+func AttemptTimeout(d time.Duration) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithAttemptTimeout(d)
+	}
+}
+
+// IsolationGrace is how long an isolated attempt's operation may take to
+// return after its context is done before it is abandoned, so operations
+// that honor cancellation report their own error
+const IsolationGrace = 10 * time.Millisecond
+
+// WithIsolatedAttempts runs each attempt of a function retrier or Engine on
+// its own goroutine, so the retry loop regains control shortly after the
+// attempt's context is done, from WithAttemptTimeout, WithTimeout or the
+// caller, even if the operation ignores it. An operation still running
+// IsolationGrace later is abandoned: the attempt fails with an
+// ErrAbandoned error and the operation's goroutine is left to finish in the
+// background, counted by AbandonedOperations and the abandoned metric until
+// it returns. WaitAbandoned joins such goroutines on shutdown. Iterators run
+// attempts in the caller's loop body and are unaffected.
+func (b *IteratorBuilder) WithIsolatedAttempts(enabled bool) *IteratorBuilder {
+	b.isolated = enabled
+	return b
+}
+
+// IsolatedAttempts returns a policy running attempts on their own
+// goroutines, see IteratorBuilder.WithIsolatedAttempts
+func IsolatedAttempts(enabled bool) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithIsolatedAttempts(enabled)
+	}
+}
+
+// execute runs op for att under ctx, on its own goroutine if attempts are
+// isolated
+func (b *IteratorBuilder) execute(ctx context.Context, att *Attempt, op func(ctx context.Context) error) error {
+	if !b.isolated {
+		return op(ctx)
+	}
+	return runCancelable(ctx, op, IsolationGrace, att.metrics)
+}
+
+// limitAttempt applies the attempt timeout to att's context and returns
+// the function releasing it
+func (s *iteratorState) limitAttempt(att *Attempt) context.CancelFunc {
+	if s.builder.attemptTimeout <= 0 {
+		return func() {}
+	}
+	var cancel context.CancelFunc
+	att.ctx, cancel = context.WithTimeoutCause(att.ctx, s.builder.attemptTimeout, ErrAttemptTimeout)
+	return cancel
+}
@@ -3,6 +3,8 @@ package recur
 import (
 	"context"
 	"iter"
+	"math/rand/v2"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -17,15 +19,68 @@ type Attempt struct {
 	maxRetry  int
 	result    error
 	resultSet bool
+	scopeMu   sync.Mutex
+	scope     *attemptScope
+	token     uint64
+	latest    *atomic.Uint64
+	diag      *AttemptDiagnostics
+	metrics   *MetricsCollector
+	override  time.Duration
+	overrides bool
+	cycleAt   time.Time
+	startedAt time.Time
+	enrich    ErrorEnricher
 }
 
-// MetricsCollector collects retry metrics
+// MetricsCollector collects retry metrics. Despite its name, TotalAttempts
+// counts completed retry cycles; AttemptCount counts individual attempts.
+// Snapshot reads all counters with clearer names.
 type MetricsCollector struct {
-	TotalAttempts atomic.Int64
-	SuccessCount  atomic.Int64
-	FailureCount  atomic.Int64
-	TotalRetries  atomic.Int64
-	name          string
+	TotalAttempts  atomic.Int64 // Completed cycles
+	SuccessCount   atomic.Int64 // Cycles that succeeded
+	FailureCount   atomic.Int64 // Cycles that failed
+	TotalRetries   atomic.Int64 // Attempts after the first in a cycle
+	AttemptCount   atomic.Int64 // Attempts run, including first attempts
+	AbandonedCount atomic.Int64 // Operations abandoned by Attempt.RunCancelable
+	name           string
+	variant        string
+
+	mu       sync.Mutex
+	variants map[string]*MetricsCollector
+	averages movingAverages
+}
+
+// MetricsSnapshot is a point-in-time copy of a collector's counters
+type MetricsSnapshot struct {
+	Name      string `json:"name"`
+	Variant   string `json:"variant,omitempty"` // Policy variant, see Variant
+	Cycles    int64  `json:"cycles"`            // Completed retry cycles, one per loop or call
+	Attempts  int64  `json:"attempts"`          // Attempts run across all cycles
+	Retries   int64  `json:"retries"`           // Attempts after the first in a cycle
+	Successes int64  `json:"successes"`         // Cycles that succeeded
+	Failures  int64  `json:"failures"`          // Cycles that failed
+	Abandoned int64  `json:"abandoned"`         // Operations abandoned after their hard stop
+
+	SuccessRate        float64 `json:"success_rate"`         // Moving average of cycle outcomes, see SuccessRate
+	AttemptsPerSuccess float64 `json:"attempts_per_success"` // Moving average of attempts per successful cycle
+}
+
+// Snapshot returns the collector's current counters. Counters are read
+// individually, so a snapshot taken under load may be off by in-flight cycles.
+func (m *MetricsCollector) Snapshot() MetricsSnapshot {
+	return MetricsSnapshot{
+		Name:      m.name,
+		Variant:   m.variant,
+		Cycles:    m.TotalAttempts.Load(),
+		Attempts:  m.AttemptCount.Load(),
+		Retries:   m.TotalRetries.Load(),
+		Successes: m.SuccessCount.Load(),
+		Failures:  m.FailureCount.Load(),
+		Abandoned: m.AbandonedCount.Load(),
+
+		SuccessRate:        m.SuccessRate(),
+		AttemptsPerSuccess: m.AttemptsPerSuccess(),
+	}
 }
 
 // NewMetricsCollector creates a new metrics collector
@@ -38,14 +93,69 @@ func (m *MetricsCollector) Name() string {
 	return m.name
 }
 
+// RecordOutcome counts one completed retry cycle as a success or failure.
+// Iterators call it automatically; call it directly to record outcomes of
+// work the iterator can't observe.
+func (m *MetricsCollector) RecordOutcome(success bool) {
+	m.TotalAttempts.Add(1)
+	if success {
+		m.SuccessCount.Add(1)
+	} else {
+		m.FailureCount.Add(1)
+	}
+	m.recordSuccessRate(success)
+}
+
 // Result tells the iterator about the operation result.
 // The iterator will automatically stop on the next iteration if:
 // - err is nil (success)
 // - err is non-retryable (doesn't match the error matcher)
 // - max attempts have been reached
 func (a *Attempt) Result(err error) {
+	if err != nil && a.enrich != nil {
+		if enriched := a.enrich(a.ctx, a.Number, err); enriched != nil {
+			err = enriched
+		}
+	}
 	a.result = err
 	a.resultSet = true
+	if err != nil {
+		a.cancelSubtasks(err)
+	}
+}
+
+// Fail records err as the attempt's failure, for loop bodies that break on
+// success and continue on errors instead of calling Result. The next
+// iteration consults the matcher as it does after Result, so non-retryable
+// errors still stop the loop. A nil err is ignored.
+//
+// Example:
+//
+//	for attempt := range recur.Iter().RetryIf(recur.MatchErrors(ErrBusy)).Seq() {
+//	    if err := send(); err != nil {
+//	        attempt.Fail(err)
+//	        continue
+//	    }
+//	    break
+//	}
+func (a *Attempt) Fail(err error) {
+	if err != nil {
+		a.Result(err)
+	}
+}
+
+// SetNextDelay overrides the backoff delay before the next attempt, for
+// example to honor a server's Retry-After hint. It takes precedence over
+// the backoff strategy and RateLimited errors; a MinDelay floor still applies.
+//
+// Example:
+//
+//	if hint, ok := recur.ParseRetryHint(resp.Header); ok {
+//	    attempt.SetNextDelay(hint.After)
+//	}
+func (a *Attempt) SetNextDelay(d time.Duration) {
+	a.override = d
+	a.overrides = true
 }
 
 // ShouldRetry returns true if the error should be retried
@@ -58,7 +168,31 @@ func (a *Attempt) ShouldRetry(err error) bool {
 	if a.Number >= a.maxRetry {
 		return false
 	}
-	return a.matcher(err)
+	return retryable(a.matcher, err)
+}
+
+// Classify returns the verdict for err from explicit classification
+// (Transient, RateLimited, Fatal) and the iterator's matcher, so loop
+// bodies can branch on the kind of failure. Unlike ShouldRetry it ignores
+// how many attempts are left.
+//
+// Example:
+//
+//	switch attempt.Classify(err) {
+//	case recur.ClassRateLimited:
+//	    limiter.Slow()
+//	case recur.ClassFatal:
+//	    alert(err)
+//	}
+func (a *Attempt) Classify(err error) ErrorClass {
+	return classify(a.matcher, err)
+}
+
+// Retryable reports whether err is worth retrying, like ShouldRetry but
+// regardless of how many attempts are left
+func (a *Attempt) Retryable(err error) bool {
+	c := a.Classify(err)
+	return c == ClassRetryable || c == ClassRateLimited
 }
 
 // Context returns the attempt's context
@@ -66,14 +200,99 @@ func (a *Attempt) Context() context.Context {
 	return a.ctx
 }
 
+// StartedAt returns when this attempt started running, after its backoff
+// delay
+func (a *Attempt) StartedAt() time.Time {
+	return a.startedAt
+}
+
+// Elapsed returns the time since the retry cycle started, including
+// earlier attempts and backoff delays
+func (a *Attempt) Elapsed() time.Duration {
+	return time.Since(a.cycleAt)
+}
+
+// Deadline returns the attempt's deadline, if its context has one. Compare
+// it against the current time to adapt work to the time left, e.g. by
+// requesting a smaller batch.
+func (a *Attempt) Deadline() (time.Time, bool) {
+	return a.ctx.Deadline()
+}
+
 // IteratorBuilder configures an iterator-based retrier
 type IteratorBuilder struct {
 	maxAttempts int
 	backoff     Backoff
 	matcher     ErrorMatcher
+	limits      []*atMost
 	timeout     time.Duration
 	ctx         context.Context
+	lifecycle   context.Context
 	metrics     *MetricsCollector
+	sampler     func(AttemptSample)
+	limiter     *AdaptiveLimiter
+	debugf      func(format string, args ...any)
+	debugRate   float64
+	debugEmit   func(DebugTrace)
+	hooks       []hookEntry
+	hookError   func(err error)
+	strictHooks bool
+	hookQueue   *hookQueue
+	failFast    bool
+	sampleRate  float64
+	formatter   ErrorFormatter
+	redactor    func(error) error
+	audit       *AuditLog
+	auditOp     string
+	diagnostics bool
+	minDelay    time.Duration
+	maxDelay    time.Duration
+	name        string
+	gate        *Gate
+	selector    func(ctx context.Context) Policy
+	selected    bool
+	behavior    BehaviorVersion
+	variant     string
+	negative    *negativeCache
+	profile     bool
+	resources   []provisioner
+	isolated    bool
+	preflight   func(ctx context.Context) error
+	tokenSource func() uint64
+
+	successThreshold     int
+	attemptTimeout       time.Duration
+	delayIncludesAttempt bool
+	scheduling           SchedulingMode
+	coordinator          Coordinator
+	coordinationKey      string
+	warmup               time.Duration
+	warmupPolicy         Policy
+	enrich               ErrorEnricher
+	timeoutMode          TimeoutMode
+	preflightBackoff     Backoff
+	retryOnZero          bool
+	wait                 func(ctx context.Context, delay time.Duration) error
+	resumed              bool // First attempt waits out a retry delay, see StreamRetrier
+}
+
+// AttemptSample describes the latency and outcome of a single attempt
+type AttemptSample struct {
+	Attempt int
+	Latency time.Duration
+	Err     error // Error passed to Result, nil on success or if Result wasn't called
+
+	// Diagnostics holds runtime stats for the attempt when WithDiagnostics is enabled
+	Diagnostics *AttemptDiagnostics
+}
+
+// sleeping counts iterators currently waiting out a backoff delay
+var sleeping atomic.Int64
+
+// SleepingRetriers returns the number of iterators currently sleeping
+// between attempts. Useful for shutdown diagnostics.
+func SleepingRetriers() int64 {
+	return sleeping.Load()
 }
 
 // Iter creates a new iterator builder
@@ -83,15 +302,25 @@ func Iter() *IteratorBuilder {
 		backoff:     Constant(100 * time.Millisecond),
 		matcher:     MatchAny,
 		ctx:         context.Background(),
+		failFast:    true,
+		sampleRate:  1,
 	}
 }
 
-// WithMaxAttempts sets the maximum number of attempts
+// WithMaxAttempts sets the maximum number of attempts, counting the first
+// one: WithMaxAttempts(3) makes 1 attempt and up to 2 retries
 func (b *IteratorBuilder) WithMaxAttempts(n int) *IteratorBuilder {
 	b.maxAttempts = n
 	return b
 }
 
+// WithMaxRetries sets the maximum number of retries after the first
+// attempt: WithMaxRetries(3) makes up to 4 attempts in total
+func (b *IteratorBuilder) WithMaxRetries(n int) *IteratorBuilder {
+	b.maxAttempts = n + 1
+	return b
+}
+
 // WithBackoff sets the backoff strategy
 func (b *IteratorBuilder) WithBackoff(backoff Backoff) *IteratorBuilder {
 	b.backoff = backoff
@@ -104,18 +333,134 @@ func (b *IteratorBuilder) WithTimeout(d time.Duration) *IteratorBuilder {
 	return b
 }
 
-// RetryIf sets the error matcher
+// WithName names the operation being retried. The name is reported on hook
+// events and in Describe; without it, the metrics or audit name is used.
+func (b *IteratorBuilder) WithName(name string) *IteratorBuilder {
+	b.name = name
+	return b
+}
+
+// operationName returns the explicit name, falling back to the metrics and
+// audit names
+func (b *IteratorBuilder) operationName() string {
+	switch {
+	case b.name != "":
+		return b.name
+	case b.metrics != nil:
+		return b.metrics.Name()
+	default:
+		return b.auditOp
+	}
+}
+
+// WithMinDelay clamps every delay between attempts to at least d, whatever
+// the backoff strategy returns
+func (b *IteratorBuilder) WithMinDelay(d time.Duration) *IteratorBuilder {
+	b.minDelay = d
+	return b
+}
+
+// WithMaxDelay caps every delay the backoff strategy returns at d, for
+// strategies whose maximum isn't configurable. Explicit delays from
+// RateLimited errors and Attempt.SetNextDelay are not capped, and
+// WithMinDelay takes precedence.
+func (b *IteratorBuilder) WithMaxDelay(d time.Duration) *IteratorBuilder {
+	b.maxDelay = d
+	return b
+}
+
+// RetryIf sets the error matcher. Errors marked with Transient, RateLimited
+// or Fatal bypass it.
 func (b *IteratorBuilder) RetryIf(matcher ErrorMatcher) *IteratorBuilder {
 	b.matcher = matcher
 	return b
 }
 
+// WithFailFastOnNonRetryable controls whether a non-retryable error passed to
+// Result ends the loop immediately (default true). The matcher always runs
+// before any backoff delay is computed or waited, so no time is spent
+// sleeping for an attempt that won't happen. When disabled, non-retryable
+// errors only affect ShouldRetry and the loop body decides when to break.
+func (b *IteratorBuilder) WithFailFastOnNonRetryable(enabled bool) *IteratorBuilder {
+	b.failFast = enabled
+	return b
+}
+
 // WithContext sets the context
 func (b *IteratorBuilder) WithContext(ctx context.Context) *IteratorBuilder {
 	b.ctx = ctx
 	return b
 }
 
+// Bind ties the iterator to a parent lifecycle such as a service's root context.
+// When lifecycle is canceled, sleeping iterators wake and stop immediately,
+// independently of the per-call context set with WithContext.
+func (b *IteratorBuilder) Bind(lifecycle context.Context) *IteratorBuilder {
+	b.lifecycle = lifecycle
+	return b
+}
+
+// WithSampler registers fn to receive the latency and outcome of every
+// attempt, measured from the start of the loop body until it returns.
+// Samples are suitable for feeding adaptive concurrency limiters.
+func (b *IteratorBuilder) WithSampler(fn func(AttemptSample)) *IteratorBuilder {
+	b.sampler = fn
+	return b
+}
+
+// WithAdaptiveLimit gates every attempt on limiter, acquiring a slot before
+// the loop body runs and releasing it with the attempt's sample afterwards.
+// A limiter is typically shared by all iterators calling the same dependency.
+func (b *IteratorBuilder) WithAdaptiveLimit(limiter *AdaptiveLimiter) *IteratorBuilder {
+	b.limiter = limiter
+	return b
+}
+
+// WithDebugLog logs the realized retry schedule through logf, one line per
+// attempt with its backoff delay and elapsed time. Pass log.Printf or t.Logf.
+func (b *IteratorBuilder) WithDebugLog(logf func(format string, args ...any)) *IteratorBuilder {
+	b.debugf = logf
+	return b
+}
+
+// WithDelayIncludesAttempt makes backoff delays intervals between attempt
+// starts rather than pauses after attempts end: the wait before a retry is
+// the backoff delay minus the time the previous attempt took, and no wait
+// at all if it took longer. This keeps polling at a fixed cadence with a
+// Constant backoff. Delays from RateLimited errors and Attempt.SetNextDelay
+// are not reduced, and WithMinDelay still applies to the wait. The FixedRate
+// scheduling mode also makes up for time spent between attempts.
+func (b *IteratorBuilder) WithDelayIncludesAttempt(enabled bool) *IteratorBuilder {
+	b.delayIncludesAttempt = enabled
+	return b
+}
+
+// WithTelemetrySampling emits hooks, samples and debug logs for only a rate
+// fraction (0 to 1) of cycles, keeping observability overhead bounded for
+// high-QPS iterators. Give-up events are always emitted, and metrics counters
+// are always updated so that rates computed from them stay unbiased. The
+// adaptive limiter, if any, still sees every attempt.
+func (b *IteratorBuilder) WithTelemetrySampling(rate float64) *IteratorBuilder {
+	b.sampleRate = rate
+	return b
+}
+
+// WithErrorFormatter customizes the message of final failure errors
+// reported in give-up events
+func (b *IteratorBuilder) WithErrorFormatter(format ErrorFormatter) *IteratorBuilder {
+	b.formatter = format
+	return b
+}
+
+// WithErrorRedactor sets a function applied to attempt errors before they
+// reach hooks, samplers, debug logs and final failure errors, so secrets
+// embedded in upstream error strings never leak into telemetry. The loop
+// body, Attempt.LastErr and the error matcher still see the original error.
+func (b *IteratorBuilder) WithErrorRedactor(redact func(error) error) *IteratorBuilder {
+	b.redactor = redact
+	return b
+}
+
 // WithMetrics enables automatic metrics collection
 func (b *IteratorBuilder) WithMetrics(name string) *IteratorBuilder {
 	b.metrics = NewMetricsCollector(name)
@@ -130,50 +475,168 @@ func (b *IteratorBuilder) WithMetricsCollector(m *MetricsCollector) *IteratorBui
 
 // Seq returns an iterator for use in for...range loops
 // If metrics are enabled, they are automatically tracked
+//
+// A cycle refused before its first attempt, by WithNegativeCache,
+// WithCoordinator or TimeoutStrict, yields a single attempt whose context
+// is already canceled with the refusal as its cause and whose LastErr is
+// the refusal, so the loop body sees the failure rather than an empty
+// loop. Bodies should not run the operation when the first attempt has a
+// LastErr.
 func (b *IteratorBuilder) Seq() iter.Seq[*Attempt] {
 	return func(yield func(*Attempt) bool) {
-		ctx, cancel := b.prepareContext()
-		if cancel != nil {
-			defer cancel()
-		}
+		b.iterate(b.ctx, nil, yield)
+	}
+}
+
+// refuse ends a cycle that may not make any attempt with err. Retriers
+// receive err as the cycle's outcome; Seq bodies, which have no other way
+// to see it, get a single attempt already failed with err.
+func refuse(ctx context.Context, err error, final *error, yield func(*Attempt) bool) {
+	if final != nil {
+		*final = err
+		return
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	cancel(err)
+	yield(&Attempt{Number: 1, LastErr: err, ctx: ctx, maxRetry: 1, result: err, resultSet: true})
+}
 
-		state := &iteratorState{
-			ctx:         ctx,
-			builder:     b,
-			startTime:   time.Now(),
-			lastAttempt: nil,
+// seq returns an iterator running under parent. If final is non-nil, it
+// receives the cycle's outcome when the iterator stops on its own: nil on
+// success, otherwise the same classified error reported in give-up events.
+func (b *IteratorBuilder) seq(parent context.Context, final *error) iter.Seq[*Attempt] {
+	return func(yield func(*Attempt) bool) {
+		b.iterate(parent, final, yield)
+	}
+}
+
+// iterate runs one cycle, handing each attempt to yield. Seq and seq stay
+// small enough to inline, so the closures ranging over them need no heap.
+func (b *IteratorBuilder) iterate(parent context.Context, final *error, yield func(*Attempt) bool) {
+	if selected := b.selectPolicy(parent); selected != b {
+		selected.iterate(parent, final, yield)
+		return
+	}
+
+	if err := b.timeoutConflict(parent); err != nil {
+		refuse(parent, err, final, yield)
+		return
+	}
+
+	ctx, cancel := b.prepareContext(parent)
+	if cancel != nil {
+		defer cancel()
+	}
+	outcome, _ := OutcomeFromContext(ctx)
+	state := &iteratorState{
+		outcome:     outcome,
+		builder:     b,
+		startTime:   time.Now(),
+		lastAttempt: nil,
+		final:       final,
+		sampled:     b.sampleRate >= 1 || rand.Float64() < b.sampleRate, //nolint:gosec // sampling needs no crypto randomness
+	}
+	state.ctx = state.withCycleID(ctx)
+	ctx = state.ctx
+	defer state.stopTimer()
+	state.startTrace()
+	defer state.emitTrace()
+
+	if err := state.cachedFailure(); err != nil {
+		refuse(ctx, err, final, yield)
+		return
+	}
+	releaseKey, err := state.coordinate()
+	if err != nil {
+		refuse(ctx, err, final, yield)
+		return
+	}
+	defer releaseKey()
+
+	for attempt := 1; attempt <= b.attemptLimit(); attempt++ {
+		if !state.checkContinue(attempt) {
+			state.notifyGiveUp(false)
+			return
 		}
 
-		for attempt := 1; attempt <= b.maxAttempts; attempt++ {
-			if !state.checkContinue(attempt) {
-				return
-			}
+		att := state.createAttempt(attempt)
+		state.notifyRetry(att)
 
-			att := state.createAttempt(attempt)
+		if !state.waitForBackoff(att) {
+			state.abort()
+			return
+		}
 
-			if !state.waitForBackoff(att) {
-				return
-			}
+		if !state.waitForGate() {
+			state.abort()
+			return
+		}
 
-			state.operationStarted = true
-			state.lastAttempt = att
+		if !state.passPreflight() {
+			state.abort()
+			return
+		}
 
-			if !yield(att) {
-				state.recordFinalMetrics()
-				return
-			}
+		if !state.acquire() {
+			state.abort()
+			return
 		}
 
-		state.recordExhaustedMetrics()
+		state.operationStarted = true
+		if b.metrics != nil {
+			b.metrics.AttemptCount.Add(1)
+		}
+		state.issueToken(att)
+		state.outcome.recordAttempt(att)
+		state.lastAttempt = att
+		state.notified = false
+		state.debug(att)
+
+		cancelAttempt := state.limitAttempt(att)
+		probe := state.startDiagnostics()
+		more := state.runAttempt(att, yield)
+		cancelAttempt()
+		state.finishDiagnostics(att, probe)
+		latency := time.Since(att.startedAt)
+		state.sample(att, latency)
+		state.traceAttempt(att, latency)
+		state.countSuccess(att)
+		state.countLimited(att)
+		if !more {
+			state.auditBreak()
+			state.recordFinalMetrics()
+			return
+		}
 	}
+
+	state.notifyGiveUp(true)
+	state.recordExhaustedMetrics()
 }
 
-// prepareContext sets up the context with timeout if configured
-func (b *IteratorBuilder) prepareContext() (context.Context, context.CancelFunc) {
+// prepareContext sets up the context with lifecycle and timeout if configured
+func (b *IteratorBuilder) prepareContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if b.lifecycle == nil {
+		if b.timeout > 0 {
+			return b.withTimeout(parent)
+		}
+		return parent, nil
+	}
+
+	ctx, cancel := context.WithCancelCause(parent)
+	stop := context.AfterFunc(b.lifecycle, func() { cancel(ErrLifecycleDone) })
 	if b.timeout > 0 {
-		return context.WithTimeout(b.ctx, b.timeout)
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = b.withTimeout(ctx)
+		return ctx, func() {
+			cancelTimeout()
+			stop()
+			cancel(nil)
+		}
+	}
+	return ctx, func() {
+		stop()
+		cancel(nil)
 	}
-	return b.ctx, nil
 }
 
 // iteratorState holds the state during iteration
@@ -183,6 +646,21 @@ type iteratorState struct {
 	startTime        time.Time
 	lastAttempt      *Attempt
 	operationStarted bool
+	notified         bool
+	timer            *time.Timer
+	firstFailure     time.Time
+	sampled          bool
+	final            *error
+	latestToken      *atomic.Uint64
+	policy           *PolicyDescription
+	cycle            cycleContext
+	successes        int
+	waitErr          error
+	trace            *DebugTrace
+	limited          []int // Errors counted against each retry limit
+	outcome          *Outcome
+	scheduled        time.Time
+	first            Attempt // Storage for attempt 1, saving an allocation per cycle
 }
 
 // checkContinue checks if iteration should continue
@@ -193,6 +671,12 @@ func (s *iteratorState) checkContinue(attempt int) bool {
 		return false
 	}
 
+	// Global kill-switch allows first attempts only
+	if attempt > 1 && KillSwitchEngaged() {
+		s.recordStopMetrics()
+		return false
+	}
+
 	// Track retry metrics (not on first attempt)
 	if s.builder.metrics != nil && attempt > 1 {
 		s.builder.metrics.TotalRetries.Add(1)
@@ -216,7 +700,16 @@ func (s *iteratorState) shouldRetryLastAttempt() bool {
 		return true
 	}
 	if s.lastAttempt.result == nil {
-		return false // Success - don't retry
+		return !s.thresholdMet() // Success - don't retry unless more are required
+	}
+	if s.limitExceeded(s.lastAttempt.result) {
+		return false
+	}
+	if r, ok := Classify(s.lastAttempt.result); ok {
+		return r // Explicit classification wins over matcher and fail-fast
+	}
+	if !s.builder.failFast {
+		return true
 	}
 	return s.builder.matcher(s.lastAttempt.result)
 }
@@ -236,97 +729,219 @@ func (s *iteratorState) createAttempt(attempt int) *Attempt {
 	var delay time.Duration
 	var lastErr error
 
-	if attempt > 1 {
-		delay = s.builder.backoff.Next(attempt - 1)
+	if attempt > 1 || s.builder.resumed {
+		delay = s.nextDelay(max(attempt-1, 1))
+		if s.builder.maxDelay > 0 {
+			delay = min(delay, s.builder.maxDelay)
+		}
+		if s.builder.scheduling == FixedRate {
+			delay = s.untilSlot(delay)
+		} else if s.builder.delayIncludesAttempt && s.lastAttempt != nil {
+			delay = max(delay-time.Since(s.lastAttempt.startedAt), 0)
+		}
 		if s.lastAttempt != nil {
 			lastErr = s.lastAttempt.result
 		}
+		if after, ok := RetryAfter(lastErr); ok && after > delay {
+			delay = after
+		}
+		if s.lastAttempt != nil && s.lastAttempt.overrides {
+			delay = s.lastAttempt.override
+		}
+		delay = max(delay, s.builder.minDelay)
+	}
+	if s.builder.scheduling == FixedRate {
+		s.scheduled = time.Now().Add(delay)
 	}
 
-	return &Attempt{
+	att := &s.first
+	if attempt > 1 {
+		att = new(Attempt)
+	}
+	*att = Attempt{
 		Number:   attempt,
 		LastErr:  lastErr,
 		Delay:    delay,
 		ctx:      s.ctx,
 		matcher:  s.builder.matcher,
-		maxRetry: s.builder.maxAttempts,
+		maxRetry: s.builder.attemptLimit(),
+		metrics:  s.builder.metrics,
+		cycleAt:  s.startTime,
+		enrich:   s.builder.enrich,
 	}
+	return att
+}
+
+// nextDelay asks the backoff for the delay before the given retry
+func (s *iteratorState) nextDelay(retry int) time.Duration {
+	backoff := s.builder.backoff
+	if s.builder.fixes(V2) {
+		if delay, ok := exactDelay(backoff, retry); ok {
+			return delay
+		}
+	}
+	eb, ok := backoff.(ElapsedBackoffer)
+	if !ok {
+		return backoff.Next(retry)
+	}
+	if s.firstFailure.IsZero() {
+		s.firstFailure = time.Now()
+	}
+	return eb.NextElapsed(retry, time.Since(s.firstFailure))
 }
 
 // waitForBackoff waits for the backoff delay or context cancellation
 func (s *iteratorState) waitForBackoff(att *Attempt) bool {
-	if att.Number <= 1 || att.Delay <= 0 {
+	if (att.Number <= 1 && !s.builder.resumed) || att.Delay <= 0 {
 		return true
 	}
+	return s.sleep(att.Delay)
+}
+
+// sleep waits out delay, returning false if the cycle's context is done
+// first
+func (s *iteratorState) sleep(delay time.Duration) bool {
+	if s.builder.wait != nil {
+		return s.waitWith(delay)
+	}
+
+	// Reuse a single timer for the whole cycle instead of allocating one
+	// per sleep with time.After, which also lingers until it fires when the
+	// context is canceled first.
+	if s.timer == nil {
+		s.timer = time.NewTimer(delay)
+	} else {
+		s.timer.Reset(delay)
+	}
+
+	sleeping.Add(1)
+	defer sleeping.Add(-1)
 
 	select {
-	case <-time.After(att.Delay):
+	case <-s.timer.C:
 		return true
 	case <-s.ctx.Done():
+		s.timer.Stop()
 		s.recordFailureMetrics()
 		return false
 	}
 }
 
-// recordFailureMetrics records failure metrics if enabled
-func (s *iteratorState) recordFailureMetrics() {
-	if s.builder.metrics != nil && s.operationStarted {
-		s.builder.metrics.TotalAttempts.Add(1)
-		s.builder.metrics.FailureCount.Add(1)
+// waitWith waits out delay with an Engine's executor
+func (s *iteratorState) waitWith(delay time.Duration) bool {
+	sleeping.Add(1)
+	defer sleeping.Add(-1)
+	if err := s.builder.wait(s.ctx, delay); err != nil {
+		s.waitErr = err
+		s.recordFailureMetrics()
+		return false
 	}
+	return true
 }
 
-// recordStopMetrics records metrics when auto-stopping (success or non-retryable error)
-func (s *iteratorState) recordStopMetrics() {
-	if s.builder.metrics == nil || !s.operationStarted {
-		return
+// redact applies the configured error redactor to errors bound for telemetry
+func (s *iteratorState) redact(err error) error {
+	if err == nil || s.builder.redactor == nil {
+		return err
 	}
+	return s.builder.redactor(err)
+}
 
-	s.builder.metrics.TotalAttempts.Add(1)
+// debug logs the attempt's realized delay if debug logging is enabled
+func (s *iteratorState) debug(att *Attempt) {
+	if s.builder.debugf == nil || !s.sampled {
+		return
+	}
+	s.builder.debugf("recur: attempt %d/%d after delay %v (elapsed %v, cycle %s, last error: %v)",
+		att.Number, s.builder.attemptLimit(), att.Delay, time.Since(s.startTime), s.cycleID(), s.redact(att.LastErr))
+}
 
-	// Determine success vs failure based on last attempt result
-	if s.lastAttempt != nil && s.lastAttempt.resultSet {
-		if s.lastAttempt.result == nil {
-			s.builder.metrics.SuccessCount.Add(1)
-		} else {
-			s.builder.metrics.FailureCount.Add(1)
-		}
-	} else {
-		// No result set - assume failure
-		s.builder.metrics.FailureCount.Add(1)
+// acquire takes a slot from the adaptive limiter if one is configured
+func (s *iteratorState) acquire() bool {
+	if s.builder.limiter == nil {
+		return true
+	}
+	if err := s.builder.limiter.Acquire(s.ctx); err != nil {
+		s.recordFailureMetrics()
+		return false
 	}
+	return true
 }
 
-// recordFinalMetrics records metrics when iterator is stopped by user
-func (s *iteratorState) recordFinalMetrics() {
-	if s.builder.metrics == nil {
+// sample reports the attempt's latency and outcome to the sampler and limiter
+func (s *iteratorState) sample(att *Attempt, latency time.Duration) {
+	if s.builder.sampler == nil && s.builder.limiter == nil {
 		return
 	}
 
-	s.builder.metrics.TotalAttempts.Add(1)
+	sample := AttemptSample{
+		Attempt:     att.Number,
+		Latency:     latency,
+		Err:         att.result,
+		Diagnostics: att.diag,
+	}
+	if s.builder.limiter != nil {
+		s.builder.limiter.Release(sample)
+	}
+	if s.builder.sampler != nil && s.sampled {
+		sample.Err = s.redact(sample.Err)
+		s.builder.sampler(sample)
+	}
+}
+
+// stopTimer releases the backoff timer when the cycle ends
+func (s *iteratorState) stopTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
 
-	// Determine success based on last attempt result
-	if s.lastAttempt != nil && s.lastAttempt.resultSet && s.lastAttempt.result != nil {
-		s.builder.metrics.FailureCount.Add(1)
-	} else {
-		s.builder.metrics.SuccessCount.Add(1)
+// recordFailureMetrics records a failed cycle if an attempt ran
+func (s *iteratorState) recordFailureMetrics() {
+	if s.builder.metrics != nil && s.operationStarted {
+		s.recordOutcome(false)
 	}
 }
 
-// recordExhaustedMetrics records metrics when all attempts are exhausted
-func (s *iteratorState) recordExhaustedMetrics() {
-	if s.builder.metrics == nil || !s.operationStarted {
+// recordStopMetrics records metrics when auto-stopping (success or
+// non-retryable error). Only an explicit Result(nil) counts as success.
+func (s *iteratorState) recordStopMetrics() {
+	if s.builder.metrics != nil && s.operationStarted {
+		s.recordOutcome(s.lastSucceeded())
+	}
+}
+
+// recordFinalMetrics records metrics when iterator is stopped by user.
+// Breaking out without reporting a failure counts as success.
+func (s *iteratorState) recordFinalMetrics() {
+	if s.builder.metrics == nil {
 		return
 	}
+	failed := s.lastAttempt != nil && s.lastAttempt.resultSet && s.lastAttempt.result != nil
+	s.recordOutcome(!failed)
+}
 
-	s.builder.metrics.TotalAttempts.Add(1)
+// recordExhaustedMetrics records metrics when all attempts are exhausted.
+// Running out of attempts is a failure unless the last one reported success.
+func (s *iteratorState) recordExhaustedMetrics() {
+	if s.builder.metrics != nil && s.operationStarted {
+		s.recordOutcome(s.lastSucceeded())
+	}
+}
 
-	// Determine success based on last attempt result
-	if s.lastAttempt != nil && s.lastAttempt.resultSet && s.lastAttempt.result != nil {
-		s.builder.metrics.FailureCount.Add(1)
-	} else {
-		s.builder.metrics.SuccessCount.Add(1)
+// recordOutcome records the cycle's outcome and attempt count
+func (s *iteratorState) recordOutcome(success bool) {
+	attempts := 0
+	if s.lastAttempt != nil {
+		attempts = s.lastAttempt.Number
 	}
+	s.builder.metrics.recordCycle(success, attempts)
+}
+
+// lastSucceeded reports whether the last attempt explicitly reported success,
+// completing any success threshold
+func (s *iteratorState) lastSucceeded() bool {
+	return s.lastAttempt != nil && s.lastAttempt.resultSet && s.lastAttempt.result == nil && s.thresholdMet()
 }
 
 // Metrics returns the metrics collector if metrics are enabled
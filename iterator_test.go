@@ -1,8 +1,18 @@
 package recur
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -183,9 +193,7 @@ func TestIterator_MetricsFailure(t *testing.T) {
 	}
 
 	metrics := builder.Metrics()
-	// Note: metrics track based on whether the iterator completes with/without error
-	// Since we don't track lastErr in the iterator, it assumes success
-	// This is a limitation of automatic metrics tracking
+	// Running out of attempts without reporting success is a failure
 	if metrics.TotalAttempts.Load() != 1 {
 		t.Errorf("Expected 1 total attempt, got %d", metrics.TotalAttempts.Load())
 	}
@@ -193,6 +201,46 @@ func TestIterator_MetricsFailure(t *testing.T) {
 	if metrics.TotalRetries.Load() != 2 {
 		t.Errorf("Expected 2 retries, got %d", metrics.TotalRetries.Load())
 	}
+
+	if metrics.FailureCount.Load() != 1 || metrics.SuccessCount.Load() != 0 {
+		t.Errorf("Expected 1 failure and no successes, got %d and %d",
+			metrics.FailureCount.Load(), metrics.SuccessCount.Load())
+	}
+}
+
+func TestIterator_MetricsExhaustedWithResult(t *testing.T) {
+	builder := Iter().
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		WithMetrics("exhausted")
+
+	for attempt := range builder.Seq() {
+		if attempt.Number == 2 {
+			attempt.Result(nil)
+		} else {
+			attempt.Result(ErrTemporary)
+		}
+	}
+	for attempt := range builder.Seq() {
+		attempt.Result(ErrTemporary)
+	}
+
+	metrics := builder.Metrics()
+	if metrics.SuccessCount.Load() != 1 || metrics.FailureCount.Load() != 1 {
+		t.Errorf("Expected 1 success and 1 failure, got %d and %d",
+			metrics.SuccessCount.Load(), metrics.FailureCount.Load())
+	}
+}
+
+func TestMetricsCollector_RecordOutcome(t *testing.T) {
+	m := NewMetricsCollector("manual")
+	m.RecordOutcome(true)
+	m.RecordOutcome(false)
+
+	if m.TotalAttempts.Load() != 2 || m.SuccessCount.Load() != 1 || m.FailureCount.Load() != 1 {
+		t.Errorf("Unexpected counts: total %d, success %d, failure %d",
+			m.TotalAttempts.Load(), m.SuccessCount.Load(), m.FailureCount.Load())
+	}
 }
 
 func TestIterator_ErrorMatching(t *testing.T) {
@@ -388,6 +436,135 @@ func TestIterator_AutoStopOnNonRetryableError(t *testing.T) {
 	}
 }
 
+func TestIterator_Fail(t *testing.T) {
+	errs := []error{ErrTemporary, nil, ErrFatal, ErrTemporary}
+	counter := 0
+
+	for attempt := range Iter().
+		WithMaxAttempts(5).
+		WithBackoff(NoDelay()).
+		RetryIf(MatchErrors(ErrTemporary)).
+		Seq() {
+		err := errs[counter]
+		counter++
+		attempt.Fail(err) // nil leaves the attempt unresolved and the loop going
+	}
+
+	if counter != 3 {
+		t.Errorf("Expected the fatal error to stop the loop after 3 attempts, got %d", counter)
+	}
+}
+
+func TestAttempt_Classify(t *testing.T) {
+	for attempt := range Iter().WithMaxAttempts(1).RetryIf(MatchErrors(ErrTemporary)).Seq() {
+		tests := []struct {
+			err  error
+			want ErrorClass
+		}{
+			{nil, ClassNone},
+			{ErrTemporary, ClassRetryable},
+			{ErrFatal, ClassFatal},
+			{Transient(ErrFatal), ClassRetryable},
+			{Fatal(ErrTemporary), ClassFatal},
+			{RateLimited(ErrFatal, time.Second), ClassRateLimited},
+		}
+		for _, tt := range tests {
+			if got := attempt.Classify(tt.err); got != tt.want {
+				t.Errorf("Expected %v for %v, got %v", tt.want, tt.err, got)
+			}
+		}
+		if !attempt.Retryable(ErrTemporary) || attempt.ShouldRetry(ErrTemporary) {
+			t.Error("Expected Retryable to ignore the attempt limit that ShouldRetry applies")
+		}
+		attempt.Result(nil)
+	}
+}
+
+// lockCoordinator is an in-process Coordinator recording acquisitions
+type lockCoordinator struct {
+	mu       sync.Mutex
+	held     map[string]bool
+	acquired []string
+	err      error // Returned by TryAcquire when set
+}
+
+func (c *lockCoordinator) TryAcquire(_ context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return false, c.err
+	}
+	if c.held[key] {
+		return false, nil
+	}
+	if c.held == nil {
+		c.held = make(map[string]bool)
+	}
+	c.held[key] = true
+	c.acquired = append(c.acquired, key)
+	return true, nil
+}
+
+func (c *lockCoordinator) Release(_ context.Context, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.held, key)
+}
+
+func TestIterator_Coordinator(t *testing.T) {
+	coord := &lockCoordinator{}
+	config := Iter().WithMaxAttempts(3).WithBackoff(NoDelay()).WithName("cache-rebuild").WithPolicy(Coordinated(coord, ""))
+
+	var outer, inner int
+	var innerErr error
+	_ = config.run(context.Background(), func(ctx context.Context) error {
+		outer++
+		// A concurrent cycle elsewhere in the fleet finds the key held
+		innerErr = config.run(context.Background(), func(ctx context.Context) error {
+			inner++
+			return nil
+		})
+		return ErrTemporary
+	})
+	if outer != 3 || inner != 0 {
+		t.Errorf("Expected only the holder to retry, got %d and %d attempts", outer, inner)
+	}
+	if !errors.Is(innerErr, ErrCoordinated) || ErrorCode(innerErr) != CodeCoordinated {
+		t.Errorf("Expected ErrCoordinated, got %v", innerErr)
+	}
+
+	if err := config.run(context.Background(), func(ctx context.Context) error { return nil }); err != nil {
+		t.Errorf("Expected the key to be released after the cycle, got %v", err)
+	}
+	if !slices.Equal(coord.acquired, []string{"cache-rebuild", "cache-rebuild"}) {
+		t.Errorf("Expected the operation name as key, got %v", coord.acquired)
+	}
+
+	// Held elsewhere, a Seq loop sees the refusal instead of an empty loop
+	coord.TryAcquire(context.Background(), "cache-rebuild")
+	var err error
+	calls := 0
+	for attempt := range config.Seq() {
+		if err = attempt.LastErr; err == nil {
+			calls++
+		}
+		attempt.Result(err)
+	}
+	if !errors.Is(err, ErrCoordinated) || calls != 0 {
+		t.Errorf("Expected Seq to see ErrCoordinated without a call, got %v after %d", err, calls)
+	}
+
+	errDown := errors.New("lock service unreachable")
+	down := &lockCoordinator{err: errDown}
+	err = Iter().WithName("cache-rebuild").WithCoordinator(down, "").run(context.Background(), func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	if !errors.Is(err, ErrCoordinatorUnavailable) || !errors.Is(err, errDown) || errors.Is(err, ErrCoordinated) || calls != 0 {
+		t.Errorf("Expected the coordinator failure surfaced without a call, got %v after %d", err, calls)
+	}
+}
+
 func TestIterator_ResultWithMetrics(t *testing.T) {
 	builder := Iter().
 		WithMaxAttempts(5).
@@ -421,3 +598,1842 @@ func TestIterator_ResultWithMetrics(t *testing.T) {
 		t.Errorf("Expected 2 retries, got %d", metrics.TotalRetries.Load())
 	}
 }
+
+func BenchmarkIterator_SuccessFirstAttempt(b *testing.B) {
+	builder := Iter().WithMaxAttempts(3)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for attempt := range builder.Seq() {
+			attempt.Result(nil)
+		}
+	}
+}
+
+func BenchmarkIterator_SuccessAfterRetries(b *testing.B) {
+	builder := Iter().WithMaxAttempts(3).WithBackoff(NoDelay())
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for attempt := range builder.Seq() {
+			if attempt.Number < 3 {
+				attempt.Result(ErrTemporary)
+				continue
+			}
+			attempt.Result(nil)
+		}
+	}
+}
+
+func BenchmarkIterator_RetryWithBackoff(b *testing.B) {
+	builder := Iter().WithMaxAttempts(5).WithBackoff(Constant(time.Microsecond))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for attempt := range builder.Seq() {
+			attempt.Result(ErrTemporary)
+		}
+	}
+}
+
+func TestIterator_SuccessPathAllocs(t *testing.T) {
+	first := Iter().WithMaxAttempts(3)
+	retried := Iter().WithMaxAttempts(3).WithBackoff(NoDelay())
+
+	tests := []struct {
+		name    string
+		builder *IteratorBuilder
+		fails   int
+		max     float64
+	}{
+		{"first attempt", first, 0, 1},
+		{"after retries", retried, 2, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				for attempt := range tt.builder.Seq() {
+					if attempt.Number <= tt.fails {
+						attempt.Result(ErrTemporary)
+						continue
+					}
+					attempt.Result(nil)
+				}
+			})
+			if allocs > tt.max {
+				t.Errorf("Expected at most %v allocations per cycle, got %v", tt.max, allocs)
+			}
+		})
+	}
+}
+
+func TestIterator_BindLifecycle(t *testing.T) {
+	lifecycle, shutdown := context.WithCancel(context.Background())
+	done := make(chan int)
+
+	go func() {
+		counter := 0
+		for attempt := range Iter().
+			Bind(lifecycle).
+			WithMaxAttempts(3).
+			WithBackoff(Constant(time.Minute)).
+			Seq() {
+			counter++
+			attempt.Result(ErrTemporary)
+		}
+		done <- counter
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for SleepingRetriers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected iterator to be sleeping")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	shutdown()
+
+	select {
+	case counter := <-done:
+		if counter != 1 {
+			t.Errorf("Expected 1 attempt before shutdown, got %d", counter)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected iterator to stop promptly after lifecycle cancellation")
+	}
+
+	if n := SleepingRetriers(); n != 0 {
+		t.Errorf("Expected no sleeping retriers, got %d", n)
+	}
+}
+
+func TestIterator_Subtasks(t *testing.T) {
+	var canceled atomic.Int32
+	counter := 0
+
+	for attempt := range Iter().
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		Seq() {
+		counter++
+
+		if counter == 1 {
+			// A slow subtask is abandoned when its sibling fails
+			attempt.Go(func(ctx context.Context) error {
+				<-ctx.Done()
+				canceled.Add(1)
+				return ctx.Err()
+			})
+			attempt.Go(func(ctx context.Context) error {
+				return ErrTemporary
+			})
+		} else {
+			attempt.Go(func(ctx context.Context) error {
+				return nil
+			})
+		}
+
+		attempt.Result(attempt.Wait())
+	}
+
+	if counter != 2 {
+		t.Errorf("Expected 2 attempts, got %d", counter)
+	}
+	if canceled.Load() != 1 {
+		t.Errorf("Expected abandoned subtask to be canceled, got %d", canceled.Load())
+	}
+}
+
+func TestIterator_SubtasksJoinedOnAdvance(t *testing.T) {
+	var running atomic.Int32
+
+	for attempt := range Iter().
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		Seq() {
+		if n := running.Load(); n != 0 {
+			t.Errorf("Expected previous subtasks to have exited, %d still running", n)
+		}
+
+		running.Add(1)
+		attempt.Go(func(ctx context.Context) error {
+			defer running.Add(-1)
+			<-ctx.Done()
+			return nil
+		})
+
+		// Fail without waiting; the iterator must cancel and join the subtask
+		attempt.Result(ErrTemporary)
+	}
+
+	if n := running.Load(); n != 0 {
+		t.Errorf("Expected no running subtasks after the loop, got %d", n)
+	}
+}
+
+func TestAttempt_BudgetRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	header := http.Header{}
+	for attempt := range Iter().WithContext(ctx).WithMaxAttempts(3).Seq() {
+		InjectBudget(header.Set, attempt.Budget())
+		break
+	}
+
+	budget, ok := ExtractBudget(header.Get)
+	if !ok {
+		t.Fatal("Expected budget to be extracted")
+	}
+	if budget.Attempt != 1 || budget.Remaining != 2 {
+		t.Errorf("Expected attempt 1 with 2 remaining, got %+v", budget)
+	}
+	if budget.Deadline.IsZero() {
+		t.Error("Expected deadline to be propagated")
+	}
+	if !budget.UpstreamWillRetry() {
+		t.Error("Expected upstream to still have retries")
+	}
+
+	got, ok := BudgetFromContext(ContextWithBudget(context.Background(), budget))
+	if !ok || got != budget {
+		t.Errorf("Expected budget from context, got %+v", got)
+	}
+
+	if _, ok := ExtractBudget(http.Header{}.Get); ok {
+		t.Error("Expected no budget without headers")
+	}
+}
+
+func TestRetryHint_RoundTrip(t *testing.T) {
+	w := httptest.NewRecorder()
+	SetRetryHint(w, RetryHint{After: 1500 * time.Millisecond, NoRetry: true, Reason: "overloaded"})
+
+	if got := w.Header().Get(HeaderRetryAfter); got != "2" {
+		t.Errorf("Expected Retry-After rounded up to 2, got %q", got)
+	}
+
+	hint, ok := ParseRetryHint(w.Header())
+	if !ok {
+		t.Fatal("Expected hint to be parsed")
+	}
+	if hint.After != 1500*time.Millisecond || !hint.NoRetry || hint.Reason != "overloaded" {
+		t.Errorf("Unexpected hint: %+v", hint)
+	}
+
+	standard := http.Header{}
+	standard.Set(HeaderRetryAfter, "3")
+	hint, ok = ParseRetryHint(standard)
+	if !ok || hint.After != 3*time.Second {
+		t.Errorf("Expected 3s from standard header, got %+v", hint)
+	}
+
+	if _, ok := ParseRetryHint(http.Header{}); ok {
+		t.Error("Expected no hint without headers")
+	}
+}
+
+type temporaryError struct{ msg string }
+
+func (e *temporaryError) Error() string   { return e.msg }
+func (e *temporaryError) Temporary() bool { return true }
+
+func TestMatchAs(t *testing.T) {
+	wrapped := fmt.Errorf("call failed: %w", &temporaryError{msg: "busy"})
+
+	tests := []struct {
+		name     string
+		matcher  ErrorMatcher
+		err      error
+		expected bool
+	}{
+		{"pointer type", MatchAs[*temporaryError](), wrapped, true},
+		{"pointer type mismatch", MatchAs[*temporaryError](), ErrTemporary, false},
+		{"interface type", MatchAs[interface{ Temporary() bool }](), wrapped, true},
+		{"nil error", MatchAs[*temporaryError](), nil, false},
+		{"runtime pointer type", MatchTypeOf((*temporaryError)(nil)), wrapped, true},
+		{"runtime pointer type mismatch", MatchTypeOf((*temporaryError)(nil)), ErrTemporary, false},
+		{"runtime interface type", MatchTypeOf((*interface{ Temporary() bool })(nil)), wrapped, true},
+		{"runtime nil error", MatchTypeOf((*temporaryError)(nil)), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matcher(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v for error: %v", tt.expected, got, tt.err)
+			}
+		})
+	}
+}
+
+func TestMatchAs_InvalidTarget(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for non-error target type")
+		}
+	}()
+	MatchTypeOf("not an error")
+}
+
+func TestBackoff_FirstDelayExact(t *testing.T) {
+	linear := Linear(100*time.Millisecond, 50*time.Millisecond).(*LinearBackoff).
+		WithFirstDelayExact(true)
+	expected := []time.Duration{100 * time.Millisecond, 150 * time.Millisecond, 200 * time.Millisecond}
+	if got := Schedule(linear, 3); !slices.Equal(got, expected) {
+		t.Errorf("Expected linear schedule %v, got %v", expected, got)
+	}
+
+	exponential := Exponential(100 * time.Millisecond).(*ExponentialBackoff).
+		WithFirstDelayExact(true)
+	expected = []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}
+	if got := Schedule(exponential, 3); !slices.Equal(got, expected) {
+		t.Errorf("Expected exponential schedule %v, got %v", expected, got)
+	}
+}
+
+func TestIterator_DebugLog(t *testing.T) {
+	var lines []string
+	logf := func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	for attempt := range Iter().
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		WithDebugLog(logf).
+		Seq() {
+		attempt.Result(ErrTemporary)
+	}
+
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 schedule lines, got %d: %v", len(lines), lines)
+	}
+	if !strings.HasPrefix(lines[1], "recur: attempt 2/3 after delay 0s") {
+		t.Errorf("Unexpected schedule line: %q", lines[1])
+	}
+}
+
+func TestIterator_OnRetry(t *testing.T) {
+	var events []RetryEvent
+
+	for attempt := range Iter().
+		WithMaxAttempts(3).
+		WithBackoff(Linear(time.Millisecond, time.Millisecond)).
+		OnRetry(func(ctx context.Context, e RetryEvent) {
+			events = append(events, e)
+		}).
+		Seq() {
+		attempt.Result(ErrTemporary)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d: %+v", len(events), events)
+	}
+
+	expected := []struct {
+		delay     time.Duration
+		willRetry bool
+	}{
+		{2 * time.Millisecond, true},
+		{3 * time.Millisecond, true},
+		{0, false},
+	}
+	if !IsMaxAttemptsExceeded(events[2].Final) || IsNonRetryable(events[2].Final) {
+		t.Errorf("Expected exhaustion as final error, got %v", events[2].Final)
+	}
+
+	for i, e := range events {
+		if e.Attempt != i+1 || e.MaxAttempts != 3 || e.Err != ErrTemporary {
+			t.Errorf("Event %d: unexpected %+v", i, e)
+		}
+		if e.NextDelay != expected[i].delay || e.WillRetry != expected[i].willRetry {
+			t.Errorf("Event %d: expected delay %v retry %v, got %+v",
+				i, expected[i].delay, expected[i].willRetry, e)
+		}
+	}
+}
+
+func TestIterator_OnRetryNonRetryable(t *testing.T) {
+	var events []RetryEvent
+
+	for attempt := range Iter().
+		WithMaxAttempts(5).
+		RetryIf(MatchErrors(ErrTemporary)).
+		OnRetry(func(ctx context.Context, e RetryEvent) {
+			events = append(events, e)
+		}).
+		Seq() {
+		attempt.Result(ErrFatal)
+	}
+
+	if len(events) != 1 || events[0].WillRetry || events[0].Err != ErrFatal {
+		t.Fatalf("Expected a single give-up event, got %+v", events)
+	}
+
+	final := events[0].Final
+	if !IsNonRetryable(final) || IsMaxAttemptsExceeded(final) {
+		t.Errorf("Expected non-retryable final error, got %v", final)
+	}
+	if !errors.Is(final, ErrFatal) {
+		t.Errorf("Expected final error to wrap the attempt error, got %v", final)
+	}
+}
+
+func TestIterator_NoSleepAfterFinalAttempt(t *testing.T) {
+	tests := []struct {
+		name     string
+		builder  *IteratorBuilder
+		err      error
+		attempts int
+	}{
+		{"exhausted", Iter().WithMaxAttempts(2), ErrTemporary, 2},
+		{"non-retryable", Iter().WithMaxAttempts(5).RetryIf(MatchErrors(ErrTemporary)), ErrFatal, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only the retry between attempts 1 and 2 may sleep
+			tt.builder.WithBackoff(Constant(50 * time.Millisecond))
+
+			start := time.Now()
+			counter := 0
+			for attempt := range tt.builder.Seq() {
+				counter++
+				attempt.Result(tt.err)
+			}
+			elapsed := time.Since(start)
+
+			if counter != tt.attempts {
+				t.Errorf("Expected %d attempts, got %d", tt.attempts, counter)
+			}
+			maxElapsed := time.Duration(tt.attempts-1)*50*time.Millisecond + 40*time.Millisecond
+			if elapsed > maxElapsed {
+				t.Errorf("Expected no sleep after the final attempt, took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestIterator_WithoutFailFast(t *testing.T) {
+	counter := 0
+
+	for attempt := range Iter().
+		WithMaxAttempts(5).
+		WithBackoff(NoDelay()).
+		RetryIf(MatchErrors(ErrTemporary)).
+		WithFailFastOnNonRetryable(false).
+		Seq() {
+		counter++
+		if attempt.ShouldRetry(ErrFatal) {
+			t.Error("Expected ShouldRetry to still reject fatal error")
+		}
+		attempt.Result(ErrFatal)
+		if counter == 3 {
+			break
+		}
+	}
+
+	if counter != 3 {
+		t.Errorf("Expected loop body to decide when to stop, got %d attempts", counter)
+	}
+}
+
+type bindClient struct {
+	calls int
+}
+
+func (c *bindClient) Get(id int) (string, error) {
+	c.calls++
+	if c.calls < 2 {
+		return "", ErrTemporary
+	}
+	return fmt.Sprintf("user-%d", id), nil
+}
+
+func (c *bindClient) Put(id int, name string) error {
+	c.calls++
+	return nil
+}
+
+func TestBind(t *testing.T) {
+	client := &bindClient{}
+	fetch := Bind1R(client, (*bindClient).Get, 42)
+
+	var user string
+	for attempt := range Iter().WithBackoff(NoDelay()).Seq() {
+		var err error
+		user, err = fetch()
+		attempt.Result(err)
+	}
+
+	if user != "user-42" || client.calls != 2 {
+		t.Errorf("Expected user-42 after 2 calls, got %q after %d", user, client.calls)
+	}
+
+	if err := Bind2(client, (*bindClient).Put, 1, "a")(); err != nil || client.calls != 3 {
+		t.Errorf("Expected Bind2 to call the method, err=%v calls=%d", err, client.calls)
+	}
+}
+
+func TestBackoff_Elapsed(t *testing.T) {
+	backoff := Elapsed(100*time.Millisecond, 10*time.Second, 10*time.Minute).(*ElapsedBackoff)
+
+	if d := backoff.NextElapsed(1, 0); d != 100*time.Millisecond {
+		t.Errorf("Expected initial delay at start, got %v", d)
+	}
+	if d := backoff.NextElapsed(1, 5*time.Minute); d != time.Second {
+		t.Errorf("Expected 1s halfway through ramp-up, got %v", d)
+	}
+	// Attempt count doesn't matter, only elapsed time
+	if d := backoff.NextElapsed(50, 5*time.Minute); d != time.Second {
+		t.Errorf("Expected delay independent of attempt count, got %v", d)
+	}
+	if d := backoff.NextElapsed(2, time.Hour); d != 10*time.Second {
+		t.Errorf("Expected max delay after ramp-up, got %v", d)
+	}
+}
+
+func TestIterator_ElapsedBackoff(t *testing.T) {
+	var delays []time.Duration
+
+	for attempt := range Iter().
+		WithMaxAttempts(3).
+		WithBackoff(Elapsed(time.Millisecond, 20*time.Millisecond, 10*time.Millisecond)).
+		Seq() {
+		delays = append(delays, attempt.Delay)
+		time.Sleep(10 * time.Millisecond)
+		attempt.Result(ErrTemporary)
+	}
+
+	if delays[1] < time.Millisecond || delays[1] > 2*time.Millisecond {
+		t.Errorf("Expected first retry near initial delay, got %v", delays[1])
+	}
+	if delays[2] != 20*time.Millisecond {
+		t.Errorf("Expected max delay once ramp-up elapsed, got %v", delays[2])
+	}
+}
+
+func TestIterator_TelemetrySampling(t *testing.T) {
+	var retries, giveUps int
+
+	builder := Iter().
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		WithMetrics("sampled").
+		WithTelemetrySampling(0).
+		OnRetry(func(ctx context.Context, e RetryEvent) {
+			if e.WillRetry {
+				retries++
+			} else {
+				giveUps++
+			}
+		})
+
+	for i := 0; i < 10; i++ {
+		for attempt := range builder.Seq() {
+			attempt.Result(ErrTemporary)
+		}
+	}
+
+	if retries != 0 {
+		t.Errorf("Expected retry events to be sampled out, got %d", retries)
+	}
+	if giveUps != 10 {
+		t.Errorf("Expected every give-up to be emitted, got %d", giveUps)
+	}
+	if n := builder.Metrics().TotalRetries.Load(); n != 20 {
+		t.Errorf("Expected metrics to count every retry, got %d", n)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{"max attempts", &MaxAttemptsExceededError{Attempts: 3, LastErr: ErrTemporary}, CodeMaxAttemptsExceeded},
+		{"non-retryable", &NonRetryableError{Attempt: 1, Err: ErrFatal}, CodeNonRetryable},
+		{"non-retryable sentinel", ErrNonRetryable, CodeNonRetryable},
+		{"kill-switch", fmt.Errorf("%w: %w", ErrKillSwitch, ErrTemporary), CodeKillSwitch},
+		{"wrapped", fmt.Errorf("query: %w", &MaxAttemptsExceededError{Attempts: 2}), CodeMaxAttemptsExceeded},
+		{"joined", errors.Join(ErrTemporary, ErrAttemptFinished), CodeAttemptFinished},
+		{"subtask cause", fmt.Errorf("%w: %w", ErrAttemptFailed, ErrTemporary), CodeAttemptFailed},
+		{"zero value", Transient(ErrZeroValue), CodeZeroValue},
+		{"post-condition", &PostConditionError{Err: ErrTemporary}, CodePostCondition},
+		{"connection closed", ErrConnClosed, CodeConnClosed},
+		{"no healthy targets", ErrNoHealthyTargets, CodeNoHealthyTargets},
+		{"threshold", ErrSuccessThresholdNotMet, CodeThresholdNotMet},
+		{"handler status", &StatusError{Status: 503}, CodeHandlerStatus},
+		{"foreign error", ErrTemporary, ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCode(tt.err); got != tt.code {
+				t.Errorf("Expected code %q, got %q", tt.code, got)
+			}
+		})
+	}
+}
+
+func TestIterator_ErrorFormatter(t *testing.T) {
+	var final error
+
+	for attempt := range Iter().
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		WithErrorFormatter(func(info FailureInfo) string {
+			return fmt.Sprintf("payments unavailable after %d attempts [%s], see OPS-123", info.Attempts, info.Code)
+		}).
+		OnRetry(func(ctx context.Context, e RetryEvent) {
+			final = e.Final
+		}).
+		Seq() {
+		attempt.Result(errors.New("secret-token rejected"))
+	}
+
+	expected := "payments unavailable after 2 attempts [max_attempts_exceeded], see OPS-123"
+	if final == nil || final.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, final)
+	}
+	if !IsMaxAttemptsExceeded(final) {
+		t.Error("Expected formatted error to keep its type")
+	}
+}
+
+func TestIterator_ErrorRedactor(t *testing.T) {
+	secret := errors.New("auth failed for token=abc123")
+	redacted := errors.New("auth failed for token=[REDACTED]")
+
+	var hookErrs []error
+	var sampleErrs []error
+	var logged []string
+
+	for attempt := range Iter().
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		WithErrorRedactor(func(err error) error { return redacted }).
+		OnRetry(func(ctx context.Context, e RetryEvent) {
+			hookErrs = append(hookErrs, e.Err)
+			if e.Final != nil {
+				hookErrs = append(hookErrs, errors.Unwrap(e.Final))
+			}
+		}).
+		WithSampler(func(s AttemptSample) { sampleErrs = append(sampleErrs, s.Err) }).
+		WithDebugLog(func(format string, args ...any) { logged = append(logged, fmt.Sprintf(format, args...)) }).
+		Seq() {
+		if attempt.Number > 1 && attempt.LastErr != secret {
+			t.Error("Expected loop body to see the original error")
+		}
+		attempt.Result(secret)
+	}
+
+	for _, err := range append(hookErrs, sampleErrs...) {
+		if err != redacted {
+			t.Errorf("Expected redacted error in telemetry, got %v", err)
+		}
+	}
+	for _, line := range logged {
+		if strings.Contains(line, "abc123") {
+			t.Errorf("Expected secret to be redacted from debug log: %q", line)
+		}
+	}
+}
+
+func TestIterator_AuditLog(t *testing.T) {
+	var buf strings.Builder
+	audit := NewAuditLog(&buf)
+
+	for attempt := range Iter().
+		WithMaxAttempts(3).
+		WithBackoff(Constant(time.Millisecond)).
+		WithAuditLog(audit, "charge_card").
+		Seq() {
+		if attempt.Number < 2 {
+			attempt.Result(ErrTemporary)
+			continue
+		}
+		attempt.Result(nil)
+	}
+
+	for attempt := range Iter().
+		WithMaxAttempts(1).
+		WithAuditLog(audit, "refund").
+		Seq() {
+		attempt.Result(ErrFatal)
+	}
+
+	// Loop bodies that break out are audited like their metrics
+	for attempt := range Iter().
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		WithAuditLog(audit, "capture").
+		Seq() {
+		if attempt.Number < 2 {
+			attempt.Fail(ErrTemporary)
+			continue
+		}
+		break
+	}
+	for attempt := range Iter().
+		WithMaxAttempts(3).
+		WithAuditLog(audit, "void").
+		Seq() {
+		attempt.Result(&codedError{code: CodeRetryTimeout, msg: "timed out"})
+		break
+	}
+
+	if err := audit.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Expected 6 records, got %d: %q", len(lines), buf.String())
+	}
+
+	expected := []AuditRecord{
+		{Operation: "charge_card", Attempt: 1, Decision: DecisionRetry, DelayMs: 1},
+		{Operation: "charge_card", Attempt: 2, Decision: DecisionSuccess},
+		{Operation: "refund", Attempt: 1, Decision: DecisionGiveUp, Code: CodeMaxAttemptsExceeded},
+		{Operation: "capture", Attempt: 1, Decision: DecisionRetry},
+		{Operation: "capture", Attempt: 2, Decision: DecisionSuccess},
+		{Operation: "void", Attempt: 1, Decision: DecisionGiveUp, Code: CodeRetryTimeout},
+	}
+	for i, line := range lines {
+		var rec AuditRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		if rec.Time.IsZero() {
+			t.Errorf("Record %d: missing timestamp", i)
+		}
+		if rec.CycleID == "" {
+			t.Errorf("Record %d: missing cycle ID", i)
+		}
+		rec.Time = time.Time{}
+		rec.CycleID = ""
+		if rec != expected[i] {
+			t.Errorf("Record %d: expected %+v, got %+v", i, expected[i], rec)
+		}
+	}
+}
+
+type recordingSink struct {
+	events []RetryEvent
+}
+
+func (r *recordingSink) Emit(ctx context.Context, e RetryEvent) {
+	r.events = append(r.events, e)
+}
+
+func TestIterator_GlobalHooks(t *testing.T) {
+	var order []string
+	unregisterHook := RegisterGlobalHook(func(ctx context.Context, e RetryEvent) {
+		order = append(order, "global")
+	})
+	sink := &recordingSink{}
+	unregisterSink := RegisterGlobalEventSink(sink)
+
+	run := func() {
+		for attempt := range Iter().
+			WithMaxAttempts(1).
+			OnRetry(func(ctx context.Context, e RetryEvent) {
+				order = append(order, "local")
+			}).
+			Seq() {
+			attempt.Result(ErrTemporary)
+		}
+	}
+
+	run()
+	if !slices.Equal(order, []string{"global", "local"}) {
+		t.Errorf("Expected global hooks before local ones, got %v", order)
+	}
+	if len(sink.events) != 1 {
+		t.Errorf("Expected sink to receive 1 event, got %d", len(sink.events))
+	}
+
+	unregisterHook()
+	unregisterSink()
+	order = nil
+	run()
+	if !slices.Equal(order, []string{"local"}) {
+		t.Errorf("Expected only local hook after unregistering, got %v", order)
+	}
+	if len(sink.events) != 1 {
+		t.Errorf("Expected sink to be unregistered, got %d events", len(sink.events))
+	}
+}
+
+func TestJitter(t *testing.T) {
+	b := Jitter(Constant(100*time.Millisecond), 0.5)
+	for i := range 100 {
+		if d := b.Next(i); d < 50*time.Millisecond || d > 100*time.Millisecond {
+			t.Fatalf("Expected delay in [50ms, 100ms], got %v", d)
+		}
+	}
+}
+
+func TestBackoff_StringAndJSON(t *testing.T) {
+	b := Exponential(100 * time.Millisecond).(*ExponentialBackoff).WithMaxDelay(time.Second)
+	want := "exponential(factor=2, first_exact=false, initial=100ms, max=1s, min=0s)"
+	if got := fmt.Sprint(b); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	data, err := json.Marshal(Jitter(Constant(time.Second), 0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var desc BackoffDescription
+	if err := json.Unmarshal(data, &desc); err != nil {
+		t.Fatal(err)
+	}
+	if desc.Type != "jitter" || desc.Params["base"] != "constant" || desc.Params["base_delay"] != "1s" {
+		t.Errorf("Unexpected JSON description: %s", data)
+	}
+
+	if got := NoDelay().(fmt.Stringer).String(); got != "none()" {
+		t.Errorf("Expected none(), got %q", got)
+	}
+}
+
+func TestAttempt_FencingTokens(t *testing.T) {
+	var attempts []*Attempt
+	for attempt := range Iter().WithBackoff(NoDelay()).WithTokenSource(nil).Seq() {
+		attempts = append(attempts, attempt)
+		attempt.Result(ErrTemporary)
+	}
+
+	for i := 1; i < len(attempts); i++ {
+		if attempts[i].Token() <= attempts[i-1].Token() {
+			t.Errorf("Expected increasing tokens, got %d after %d", attempts[i].Token(), attempts[i-1].Token())
+		}
+		if !attempts[i-1].Stale() {
+			t.Errorf("Expected attempt %d to be stale", i)
+		}
+	}
+	if attempts[len(attempts)-1].Stale() {
+		t.Error("Expected latest attempt not to be stale")
+	}
+
+	var next uint64 = 100
+	source := func() uint64 {
+		next += 10
+		return next
+	}
+	var tokens []uint64
+	for attempt := range Iter().WithBackoff(NoDelay()).WithMaxAttempts(2).WithTokenSource(source).Seq() {
+		tokens = append(tokens, attempt.Token())
+		attempt.Result(ErrTemporary)
+	}
+	if !slices.Equal(tokens, []uint64{110, 120}) {
+		t.Errorf("Expected tokens from the source, got %v", tokens)
+	}
+
+	for attempt := range Iter().WithBackoff(NoDelay()).WithMaxAttempts(2).Seq() {
+		if attempt.Token() != 0 || attempt.Stale() {
+			t.Errorf("Expected no tokens without a source, got %d", attempt.Token())
+		}
+		attempt.Result(ErrTemporary)
+	}
+}
+
+func TestFence(t *testing.T) {
+	var fence Fence
+	if !fence.Accept(2) || !fence.Accept(2) || !fence.Accept(5) {
+		t.Error("Expected current and newer tokens to be accepted")
+	}
+	if fence.Accept(3) {
+		t.Error("Expected stale token to be rejected")
+	}
+	if fence.Highest() != 5 {
+		t.Errorf("Expected highest 5, got %d", fence.Highest())
+	}
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	m := NewMetricsCollector(`fetch "user"`)
+	m.RecordOutcome(true)
+	m.RecordOutcome(false)
+	m.TotalRetries.Add(3)
+
+	var buf bytes.Buffer
+	if err := m.WriteOpenMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE recur_cycles counter\n",
+		`recur_cycles_total{name="fetch \"user\""} 2` + "\n",
+		`recur_failures_total{name="fetch \"user\""} 1` + "\n",
+		`recur_retries_total{name="fetch \"user\""} 3` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Error("Expected output to end with # EOF")
+	}
+
+	var withNil bytes.Buffer
+	if err := WriteOpenMetrics(&withNil, nil, m, Iter().Metrics()); err != nil {
+		t.Fatal(err)
+	}
+	if withNil.String() != out {
+		t.Errorf("Expected nil collectors to be skipped, got:\n%s", withNil.String())
+	}
+}
+
+func TestMetricsCollector_MovingAverages(t *testing.T) {
+	builder := Iter().WithBackoff(NoDelay()).WithMetrics("payments")
+
+	var buf bytes.Buffer
+	if err := builder.Metrics().WriteOpenMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "recur_success_rate{") {
+		t.Errorf("Expected no success rate before any cycle, got:\n%s", buf.String())
+	}
+
+	for i := range 10 {
+		for attempt := range builder.Seq() {
+			// Every other cycle fails outright; the rest succeed on the 3rd attempt
+			if i%2 == 0 || attempt.Number < 3 {
+				attempt.Result(ErrTemporary)
+			} else {
+				attempt.Result(nil)
+			}
+		}
+	}
+
+	m := builder.Metrics()
+	if rate := m.SuccessRate(); rate < 0.3 || rate > 0.7 {
+		t.Errorf("Expected success rate near 0.5, got %v", rate)
+	}
+	if got := m.AttemptsPerSuccess(); got != 3 {
+		t.Errorf("Expected 3 attempts per success, got %v", got)
+	}
+
+	buf.Reset()
+	if err := m.WriteOpenMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# TYPE recur_success_rate gauge\n",
+		`recur_attempts_per_success{name="payments"} 3` + "\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	// Concurrent updates never lose the average's bounds
+	concurrent := NewMetricsCollector("concurrent")
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				concurrent.RecordOutcome(true)
+			}
+		}()
+	}
+	wg.Wait()
+	if rate := concurrent.SuccessRate(); rate != 1 {
+		t.Errorf("Expected success rate 1 after only successes, got %v", rate)
+	}
+}
+
+func TestIterator_Diagnostics(t *testing.T) {
+	var events []RetryEvent
+	stop := make(chan struct{})
+	defer close(stop)
+
+	var sink [][]byte
+	for attempt := range Iter().
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		WithDiagnostics(true).
+		OnRetry(func(ctx context.Context, e RetryEvent) { events = append(events, e) }).
+		Seq() {
+		// Leak a goroutine and allocate on every attempt
+		go func() { <-stop }()
+		sink = append(sink, make([]byte, 1<<20))
+		attempt.Result(ErrTemporary)
+	}
+	_ = sink
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	for _, e := range events {
+		d := e.Diagnostics
+		if d == nil {
+			t.Fatal("Expected diagnostics on event")
+		}
+		if d.GoroutineDelta < 1 {
+			t.Errorf("Expected leaked goroutine to show up, got delta %d", d.GoroutineDelta)
+		}
+		if d.AllocBytes < 1<<20 {
+			t.Errorf("Expected at least 1MiB allocated, got %d", d.AllocBytes)
+		}
+	}
+}
+
+func TestIterator_ClassifiedErrors(t *testing.T) {
+	t.Run("transient overrides matcher", func(t *testing.T) {
+		counter := 0
+		for attempt := range Iter().WithBackoff(NoDelay()).RetryIf(MatchNone).Seq() {
+			counter++
+			attempt.Result(Transient(ErrFatal))
+		}
+		if counter != 3 {
+			t.Errorf("Expected 3 attempts, got %d", counter)
+		}
+	})
+
+	t.Run("fatal stops even without fail-fast", func(t *testing.T) {
+		counter := 0
+		for attempt := range Iter().WithBackoff(NoDelay()).WithFailFastOnNonRetryable(false).Seq() {
+			counter++
+			attempt.Result(Fatal(ErrTemporary))
+		}
+		if counter != 1 {
+			t.Errorf("Expected 1 attempt, got %d", counter)
+		}
+	})
+
+	t.Run("rate limited raises delay", func(t *testing.T) {
+		var delays []time.Duration
+		for attempt := range Iter().WithMaxAttempts(2).WithBackoff(Constant(time.Millisecond)).Seq() {
+			delays = append(delays, attempt.Delay)
+			attempt.Result(RateLimited(ErrTemporary, 20*time.Millisecond))
+		}
+		if len(delays) != 2 || delays[1] != 20*time.Millisecond {
+			t.Errorf("Expected second delay of 20ms, got %v", delays)
+		}
+	})
+}
+
+func TestClassify(t *testing.T) {
+	if r, ok := Classify(fmt.Errorf("wrapped: %w", Fatal(ErrTemporary))); !ok || r {
+		t.Errorf("Expected wrapped Fatal to classify as non-retryable, got %v, %v", r, ok)
+	}
+	if _, ok := Classify(ErrTemporary); ok {
+		t.Error("Expected unmarked error not to be classified")
+	}
+	if !errors.Is(Transient(ErrTemporary), ErrTemporary) {
+		t.Error("Expected marked error to unwrap")
+	}
+	if Transient(nil) != nil || Fatal(nil) != nil || RateLimited(nil, time.Second) != nil {
+		t.Error("Expected nil errors to stay nil")
+	}
+	if d, ok := RetryAfter(RateLimited(ErrTemporary, time.Second)); !ok || d != time.Second {
+		t.Errorf("Expected RetryAfter 1s, got %v, %v", d, ok)
+	}
+}
+
+func TestBackoff_MinDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+	}{
+		{"constant", Constant(time.Millisecond).(*ConstantBackoff).WithMinDelay(10 * time.Millisecond)},
+		{"exponential", Exponential(time.Millisecond).(*ExponentialBackoff).WithMaxDelay(5 * time.Millisecond).WithMinDelay(10 * time.Millisecond)},
+		{"fibonacci", Fibonacci(time.Millisecond).(*FibonacciBackoff).WithMinDelay(10 * time.Millisecond)},
+		{"linear", Linear(time.Millisecond, time.Millisecond).(*LinearBackoff).WithMinDelay(10 * time.Millisecond)},
+		{"elapsed", Elapsed(time.Millisecond, 5*time.Millisecond, time.Hour).(*ElapsedBackoff).WithMinDelay(10 * time.Millisecond)},
+		{"jitter", Jitter(Constant(12*time.Millisecond), 1).(*JitterBackoff).WithMinDelay(10 * time.Millisecond)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 1; i <= 4; i++ {
+				if d := tt.backoff.Next(i); d < 10*time.Millisecond {
+					t.Errorf("Next(%d) = %v, below the 10ms floor", i, d)
+				}
+			}
+		})
+	}
+}
+
+func TestIterator_MinDelayPolicy(t *testing.T) {
+	var delays []time.Duration
+	for attempt := range Iter().WithPolicy(CombinePolicies(MinDelay(5*time.Millisecond), WithBackoff(NoDelay()))).Seq() {
+		delays = append(delays, attempt.Delay)
+		attempt.Result(ErrTemporary)
+	}
+	if !slices.Equal(delays, []time.Duration{0, 5 * time.Millisecond, 5 * time.Millisecond}) {
+		t.Errorf("Expected clamped delays, got %v", delays)
+	}
+	if desc := Describe(MinDelay(5 * time.Millisecond)); desc.MinDelay != "5ms" {
+		t.Errorf("Expected min delay in description, got %q", desc.MinDelay)
+	}
+}
+
+func TestIterator_DelayIncludesAttempt(t *testing.T) {
+	var delays []time.Duration
+	config := Iter().WithMaxRetries(2).WithBackoff(Constant(50 * time.Millisecond)).WithDelayIncludesAttempt(true)
+	for attempt := range config.Seq() {
+		delays = append(delays, attempt.Delay)
+		if attempt.Number == 1 {
+			time.Sleep(20 * time.Millisecond)
+		} else {
+			time.Sleep(60 * time.Millisecond)
+		}
+		attempt.Result(ErrTemporary)
+	}
+	if len(delays) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(delays))
+	}
+	if delays[1] <= 0 || delays[1] > 30*time.Millisecond {
+		t.Errorf("Expected delay reduced by the attempt duration, got %v", delays[1])
+	}
+	if delays[2] != 0 {
+		t.Errorf("Expected no delay after a slow attempt, got %v", delays[2])
+	}
+
+	var floored []time.Duration
+	config = Iter().WithMaxRetries(1).WithBackoff(Constant(time.Millisecond)).WithMinDelay(5 * time.Millisecond).WithPolicy(DelayIncludesAttempt(true))
+	for attempt := range config.Seq() {
+		floored = append(floored, attempt.Delay)
+		time.Sleep(2 * time.Millisecond)
+		attempt.Result(ErrTemporary)
+	}
+	if floored[1] != 5*time.Millisecond {
+		t.Errorf("Expected min delay to apply to the wait, got %v", floored[1])
+	}
+}
+
+func TestIterator_FixedRateOverrun(t *testing.T) {
+	var delays []time.Duration
+	config := Iter().WithMaxRetries(2).WithBackoff(Constant(20 * time.Millisecond)).WithPolicy(Scheduling(FixedRate))
+	for attempt := range config.Seq() {
+		delays = append(delays, attempt.Delay)
+		if attempt.Number == 1 {
+			time.Sleep(30 * time.Millisecond)
+		}
+		attempt.Result(ErrTemporary)
+	}
+	if delays[1] != 0 {
+		t.Errorf("Expected an overrunning attempt to be followed immediately, got %v", delays[1])
+	}
+	if delays[2] < 15*time.Millisecond {
+		t.Errorf("Expected the schedule to restart after an overrun, got %v", delays[2])
+	}
+	if FixedRate.String() != "fixed-rate" || FixedDelay.String() != "fixed-delay" {
+		t.Errorf("Unexpected mode names %q, %q", FixedRate, FixedDelay)
+	}
+}
+
+func TestIterator_MaxDelayPolicy(t *testing.T) {
+	var delays []time.Duration
+	for attempt := range Iter().WithPolicy(CombinePolicies(MaxDelay(time.Millisecond), WithBackoff(Constant(time.Hour)))).Seq() {
+		delays = append(delays, attempt.Delay)
+		attempt.Result(ErrTemporary)
+	}
+	if !slices.Equal(delays, []time.Duration{0, time.Millisecond, time.Millisecond}) {
+		t.Errorf("Expected capped delays, got %v", delays)
+	}
+	if desc := Describe(MaxDelay(time.Second)); desc.MaxDelay != "1s" {
+		t.Errorf("Expected max delay in description, got %q", desc.MaxDelay)
+	}
+}
+
+func TestCapBackoff(t *testing.T) {
+	b := CapBackoff(Exponential(100*time.Millisecond), time.Second)
+	want := []time.Duration{200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	if got := Schedule(b, 5); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if desc := DescribeBackoff(b); desc.Type != "capped" || desc.Params["max"] != "1s" || desc.Params["base"] != "exponential" {
+		t.Errorf("Unexpected description: %v", desc)
+	}
+}
+
+func TestAttempt_SetNextDelay(t *testing.T) {
+	var delays []time.Duration
+	for attempt := range Iter().WithBackoff(Constant(time.Hour)).Seq() {
+		delays = append(delays, attempt.Delay)
+		attempt.SetNextDelay(time.Millisecond)
+		attempt.Result(RateLimited(ErrTemporary, time.Hour))
+	}
+	if !slices.Equal(delays, []time.Duration{0, time.Millisecond, time.Millisecond}) {
+		t.Errorf("Expected overridden delays, got %v", delays)
+	}
+}
+
+func TestMetricsCollector_Snapshot(t *testing.T) {
+	builder := Iter().WithBackoff(NoDelay()).WithMetrics("snapshot")
+	for range 2 {
+		for attempt := range builder.Seq() {
+			if attempt.Number == 2 {
+				attempt.Result(nil)
+			} else {
+				attempt.Result(ErrTemporary)
+			}
+		}
+	}
+
+	want := MetricsSnapshot{
+		Name: "snapshot", Cycles: 2, Attempts: 4, Retries: 2, Successes: 2,
+		SuccessRate: 1, AttemptsPerSuccess: 2,
+	}
+	if got := builder.Metrics().Snapshot(); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestAttempt_RunCancelable(t *testing.T) {
+	release := make(chan struct{})
+	builder := Iter().WithMaxAttempts(1).WithTimeout(10 * time.Millisecond).WithMetrics("cancelable")
+
+	var err error
+	for attempt := range builder.Seq() {
+		// Ignores its context until released
+		err = attempt.RunCancelable(func(ctx context.Context) error {
+			<-release
+			return nil
+		}, 10*time.Millisecond)
+		attempt.Result(err)
+	}
+
+	if !IsAbandoned(err) || !errors.Is(err, context.DeadlineExceeded) || ErrorCode(err) != CodeAbandoned {
+		t.Errorf("Expected abandoned deadline error, got %v", err)
+	}
+	if n := builder.Metrics().Snapshot().Abandoned; n != 1 {
+		t.Errorf("Expected 1 abandoned operation, got %d", n)
+	}
+	if n := AbandonedOperations(); n != 1 {
+		t.Errorf("Expected 1 running abandoned operation, got %d", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := WaitAbandoned(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected waiting to time out while the operation runs, got %v", err)
+	}
+
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := WaitAbandoned(ctx); err != nil {
+		t.Errorf("Expected the abandoned operation to be joined, got %v", err)
+	}
+	if n := AbandonedOperations(); n != 0 {
+		t.Errorf("Expected abandoned operation to be released, got %d", n)
+	}
+}
+
+func TestRunCancelable_CooperativeOperation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := RunCancelable(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, time.Second)
+	if !errors.Is(err, context.Canceled) || IsAbandoned(err) {
+		t.Errorf("Expected operation's own error, got %v", err)
+	}
+}
+
+func TestIterator_HookPriorities(t *testing.T) {
+	var order []string
+	record := func(name string) Hook {
+		return func(ctx context.Context, e RetryEvent) {
+			if e.Operation == "priorities" {
+				order = append(order, name)
+			}
+		}
+	}
+
+	unregister := RegisterGlobalHookPriority(50, record("global"))
+	defer unregister()
+
+	base := Iter().
+		WithName("priorities").
+		WithMaxAttempts(1).
+		OnRetry(record("plain")).
+		AddHook("logging", -10, record("logging")).
+		AddHook("tracing", 100, record("tracing"))
+
+	for attempt := range base.Seq() {
+		attempt.Result(ErrTemporary)
+	}
+	if want := []string{"tracing", "global", "plain", "logging"}; !slices.Equal(order, want) {
+		t.Errorf("Expected order %v, got %v", want, order)
+	}
+
+	order = nil
+	quiet := base.Clone().RemoveHook("logging").AddHook("tracing", 100, record("tracing-v2"))
+	for attempt := range quiet.Seq() {
+		attempt.Result(ErrTemporary)
+	}
+	if want := []string{"tracing-v2", "global", "plain"}; !slices.Equal(order, want) {
+		t.Errorf("Expected order %v on clone, got %v", want, order)
+	}
+	if n := base.Describe().Hooks; n != 3 {
+		t.Errorf("Expected original builder to keep 3 hooks, got %d", n)
+	}
+}
+
+func TestIterator_HookPanics(t *testing.T) {
+	var hookErrs []error
+	var later, calls int
+	builder := Iter().
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		AddHook("broken", 10, func(context.Context, RetryEvent) { panic("nil map") }).
+		OnRetry(func(context.Context, RetryEvent) { later++ }).
+		OnHookError(func(err error) { hookErrs = append(hookErrs, err) })
+
+	for attempt := range builder.Seq() {
+		calls++
+		attempt.Result(ErrTemporary)
+	}
+	if calls != 3 || later != 3 {
+		t.Errorf("Expected the cycle and later hooks to continue, got %d calls and %d hook runs", calls, later)
+	}
+	var panicked *HookPanicError
+	if len(hookErrs) != 3 || !errors.As(hookErrs[0], &panicked) || panicked.Hook != "broken" || panicked.Value != "nil map" {
+		t.Fatalf("Expected hook panics reported, got %v", hookErrs)
+	}
+	if ErrorCode(panicked) != CodeHookPanic || len(panicked.Stack) == 0 {
+		t.Errorf("Expected a coded error with a stack, got %q", ErrorCode(panicked))
+	}
+
+	defer func() {
+		if v := recover(); v != "nil map" {
+			t.Errorf("Expected strict hooks to propagate the panic, got %v", v)
+		}
+	}()
+	for attempt := range builder.Clone().WithStrictHooks(true).Seq() {
+		attempt.Result(ErrTemporary)
+	}
+	t.Error("Expected the strict cycle to panic")
+}
+
+func TestIterator_AsyncHooks(t *testing.T) {
+	started, release := make(chan struct{}, 4), make(chan struct{})
+	handled := make(chan error, 4)
+	var dropped atomic.Int32
+	builder := Iter().
+		WithMaxAttempts(4).
+		WithBackoff(NoDelay()).
+		WithAsyncHooks(1).
+		OnRetry(func(ctx context.Context, e RetryEvent) {
+			started <- struct{}{}
+			<-release
+			handled <- ctx.Err()
+		}).
+		OnHookError(func(err error) {
+			if errors.Is(err, ErrHookEventDropped) {
+				dropped.Add(1)
+			}
+		})
+
+	for attempt := range builder.Seq() {
+		if attempt.Number == 2 {
+			<-started // The first event occupies the worker
+		}
+		attempt.Result(ErrTemporary)
+	}
+	// The second event waits in the queue and the last two are dropped,
+	// all without the blocked hook holding up the loop
+	if n := dropped.Load(); n != 2 {
+		t.Errorf("Expected 2 dropped events, got %d", n)
+	}
+
+	close(release)
+	for range 2 {
+		if err := <-handled; err != nil {
+			t.Errorf("Expected hooks to run with a live context, got %v", err)
+		}
+	}
+}
+
+func TestIterator_CycleID(t *testing.T) {
+	var ids []string
+	var eventID string
+	var final error
+	for attempt := range Iter().
+		WithMaxAttempts(2).
+		WithBackoff(Constant(0)).
+		OnRetry(func(_ context.Context, e RetryEvent) {
+			eventID = e.CycleID
+			final = e.Final
+		}).
+		Seq() {
+		ids = append(ids, attempt.CycleID())
+		attempt.Result(ErrTemporary)
+	}
+
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] {
+		t.Fatalf("Expected one cycle ID shared by both attempts, got %q", ids)
+	}
+	if eventID != ids[0] {
+		t.Errorf("Expected event cycle ID %q, got %q", ids[0], eventID)
+	}
+	var maxErr *MaxAttemptsExceededError
+	if !errors.As(final, &maxErr) || maxErr.CycleID != ids[0] {
+		t.Errorf("Expected final error to carry cycle ID %q, got %v", ids[0], final)
+	}
+
+	for attempt := range Iter().Seq() {
+		if attempt.CycleID() == ids[0] {
+			t.Error("Expected a new cycle ID per cycle")
+		}
+		attempt.Result(nil)
+	}
+
+	// IDs are generated on first use, which may race between subtasks
+	for attempt := range Iter().Seq() {
+		var seen [4]string
+		for i := range seen {
+			attempt.Go(func(ctx context.Context) error {
+				seen[i], _ = CycleIDFromContext(ctx)
+				return nil
+			})
+		}
+		attempt.Result(attempt.Wait())
+		if seen[0] == "" || seen != [4]string{seen[0], seen[0], seen[0], seen[0]} {
+			t.Errorf("Expected subtasks to share one cycle ID, got %q", seen)
+		}
+	}
+
+	ctx := ContextWithCycleID(context.Background(), "upstream")
+	header := http.Header{}
+	for attempt := range Iter().WithContext(ctx).Seq() {
+		InjectCycleID(header.Set, attempt.Context())
+		attempt.Result(nil)
+	}
+	if got := header.Get(HeaderRetryCycleID); got != "upstream" {
+		t.Errorf("Expected propagated cycle ID %q, got %q", "upstream", got)
+	}
+}
+
+func TestAttempt_StopRequested(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 1
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for attempt := range Iter().WithContext(ctx).WithMaxAttempts(1).Seq() {
+		actx := attempt.AttemptContext()
+		if actx.StopRequested() {
+			t.Fatal("Expected no stop before cancellation")
+		}
+		v, ok, err := Select(actx, ch)
+		if v != 1 || !ok || err != nil {
+			t.Fatalf("Expected to receive 1, got %d, %v, %v", v, ok, err)
+		}
+
+		cancel()
+		if !attempt.StopRequested() || !actx.StopRequested() {
+			t.Error("Expected stop after cancellation")
+		}
+		if _, _, err := Select(actx, ch); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled from Select, got %v", err)
+		}
+		if err := SelectSend(actx, make(chan int), 2); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled from SelectSend, got %v", err)
+		}
+		attempt.Result(nil)
+	}
+}
+
+func TestAttempt_Timing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	var starts []time.Time
+	for attempt := range Iter().WithContext(ctx).WithMaxAttempts(2).WithBackoff(Constant(10 * time.Millisecond)).Seq() {
+		starts = append(starts, attempt.StartedAt())
+		if d, ok := attempt.Deadline(); !ok || !d.Equal(deadline) {
+			t.Errorf("Expected deadline %v, got %v, %v", deadline, d, ok)
+		}
+		if attempt.Number == 2 && attempt.Elapsed() < 10*time.Millisecond {
+			t.Errorf("Expected elapsed to include the backoff delay, got %v", attempt.Elapsed())
+		}
+		attempt.Result(ErrTemporary)
+	}
+
+	if len(starts) != 2 || starts[1].Sub(starts[0]) < 10*time.Millisecond {
+		t.Errorf("Expected second attempt to start after the delay, got %v", starts)
+	}
+}
+
+func TestIterator_ProfilerLabels(t *testing.T) {
+	var labels [][2]string
+	for attempt := range Iter().
+		WithName("fetch").
+		WithBackoff(NoDelay()).
+		WithMaxAttempts(2).
+		WithProfilerLabels(true).
+		Seq() {
+		op, _ := pprof.Label(attempt.Context(), LabelOperation)
+		n, _ := pprof.Label(attempt.Context(), LabelAttempt)
+		labels = append(labels, [2]string{op, n})
+		attempt.Result(ErrTemporary)
+	}
+
+	want := [][2]string{{"fetch", "1"}, {"fetch", "2"}}
+	if !slices.Equal(labels, want) {
+		t.Errorf("Expected labels %v, got %v", want, labels)
+	}
+}
+
+func TestIterator_SuccessThreshold(t *testing.T) {
+	// Succeeds, fails, then succeeds three times in a row
+	results := []error{nil, ErrTemporary, nil, nil, nil, nil}
+	builder := Iter().
+		WithMaxAttempts(len(results)).
+		WithBackoff(NoDelay()).
+		WithSuccessThreshold(3).
+		WithMetrics("probe")
+	var attempts int
+	for attempt := range builder.Seq() {
+		attempts++
+		attempt.Result(results[attempt.Number-1])
+	}
+	if attempts != 5 {
+		t.Errorf("Expected 5 attempts, got %d", attempts)
+	}
+	if snap := builder.Metrics().Snapshot(); snap.Successes != 1 {
+		t.Errorf("Expected the cycle to count as a success, got %+v", snap)
+	}
+
+	err := Func0(func() error { return nil }).
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		WithPolicy(SuccessThreshold(3)).
+		BuildContext()(context.Background())
+	var maxErr *MaxAttemptsExceededError
+	if !errors.As(err, &maxErr) || !errors.Is(err, ErrSuccessThresholdNotMet) {
+		t.Errorf("Expected unmet threshold after exhausting attempts, got %v", err)
+	}
+}
+
+func TestOutcomeFromContext(t *testing.T) {
+	ctx, outcome := ContextWithOutcome(context.Background())
+	if o, ok := OutcomeFromContext(ctx); !ok || o != outcome {
+		t.Fatal("Expected the installed outcome")
+	}
+
+	call := Func0(func() error { return nil }).BuildContext()
+	if err := call(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Retried() {
+		t.Error("Expected no retries after a first-attempt success")
+	}
+
+	for attempt := range Iter().WithContext(ctx).WithBackoff(Constant(time.Millisecond)).Seq() {
+		attempt.Result(ErrTemporary)
+	}
+
+	want := OutcomeSummary{Cycles: 2, Attempts: 4, Retries: 2, TotalDelay: 2 * time.Millisecond}
+	if got := outcome.Summary(); got != want || !outcome.Retried() {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestWithAttemptResource(t *testing.T) {
+	var setups, cleanups int
+	var seen []int
+	builder := Iter().
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		WithPolicy(WithAttemptResource(func(ctx context.Context) (int, func(), error) {
+			setups++
+			id := setups
+			return id, func() { cleanups++ }, nil
+		}))
+
+	for attempt := range builder.Seq() {
+		id, ok := AttemptResource[int](attempt.Context())
+		if !ok {
+			t.Fatal("Expected attempt resource in context")
+		}
+		if cleanups != setups-1 {
+			t.Errorf("Expected previous resources cleaned up before attempt %d, got %d cleanups", attempt.Number, cleanups)
+		}
+		seen = append(seen, id)
+		attempt.Result(ErrTemporary)
+	}
+
+	if !slices.Equal(seen, []int{1, 2, 3}) {
+		t.Errorf("Expected a fresh resource per attempt, got %v", seen)
+	}
+	if cleanups != 3 {
+		t.Errorf("Expected every resource cleaned up, got %d", cleanups)
+	}
+}
+
+func TestWithAttemptResource_SetupFailure(t *testing.T) {
+	errNoSpace := errors.New("no space left on device")
+	var calls, cleanups int
+	err := Func0(func() error {
+		calls++
+		return nil
+	}).
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		WithPolicy(CombinePolicies(
+			WithAttemptResource(func(ctx context.Context) (string, func(), error) {
+				return "dir", func() { cleanups++ }, nil
+			}),
+			WithAttemptResource(func(ctx context.Context) (int, func(), error) {
+				return 0, nil, errNoSpace
+			}),
+		)).
+		BuildContext()(context.Background())
+
+	if !errors.Is(err, errNoSpace) {
+		t.Errorf("Expected setup error after exhausting attempts, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected the operation not to run, got %d calls", calls)
+	}
+	if cleanups != 3 {
+		t.Errorf("Expected resources set up before the failure to be released, got %d cleanups", cleanups)
+	}
+}
+
+func TestWithAttemptResource_SetupFailureSeq(t *testing.T) {
+	errNoSpace := errors.New("no space left on device")
+	builder := Iter().
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		WithPolicy(WithAttemptResource(func(ctx context.Context) (int, func(), error) {
+			return 0, nil, errNoSpace
+		}))
+
+	var final error
+	builder.OnRetry(func(_ context.Context, e RetryEvent) { final = e.Final })
+	var iterations int
+	for attempt := range builder.Seq() {
+		iterations++
+		if cause := context.Cause(attempt.Context()); !errors.Is(cause, errNoSpace) {
+			t.Errorf("Expected the attempt context canceled with the setup error, got %v", cause)
+		}
+		attempt.Result(attempt.Context().Err())
+	}
+
+	if iterations != 3 {
+		t.Errorf("Expected every failed setup to reach the loop body, got %d iterations", iterations)
+	}
+	if !errors.Is(final, errNoSpace) {
+		t.Errorf("Expected the setup error to stay the attempt's outcome, got %v", final)
+	}
+}
+
+func TestWithAttemptResource_SetupFailureReleasesLimiter(t *testing.T) {
+	limiter := NewAdaptiveLimiter(1, 1)
+	failing := WithAttemptResource(func(ctx context.Context) (int, func(), error) {
+		return 0, nil, ErrTemporary
+	})
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := Iter().WithMaxAttempts(2).WithBackoff(NoDelay()).WithAdaptiveLimit(limiter).WithPolicy(failing).run(ctx, func(ctx context.Context) error {
+			return nil
+		})
+		cancel()
+		if !errors.Is(err, ErrTemporary) {
+			t.Fatalf("Expected the setup error, got %v", err)
+		}
+	}
+	if n := limiter.Inflight(); n != 0 {
+		t.Errorf("Expected failed setups to release their limiter slots, got %d in flight", n)
+	}
+}
+
+func TestWithAttemptResource_ReleasedOnPanic(t *testing.T) {
+	var cleanups int
+	builder := Iter().WithPolicy(WithAttemptResource(func(ctx context.Context) (string, func(), error) {
+		return "dir", func() { cleanups++ }, nil
+	}))
+	func() {
+		defer func() { _ = recover() }()
+		for range builder.Seq() {
+			panic("boom")
+		}
+	}()
+	if cleanups != 1 {
+		t.Errorf("Expected the resource released when the loop body panics, got %d cleanups", cleanups)
+	}
+}
+
+func TestMatchAtMost(t *testing.T) {
+	errThrottled := errors.New("429 too many requests")
+	errReset := errors.New("connection reset")
+
+	var matched []error
+	config := Iter().
+		WithMaxAttempts(10).
+		WithBackoff(NoDelay()).
+		RetryIf(MatchFunc(func(err error) bool {
+			matched = append(matched, err)
+			return errors.Is(err, errThrottled) || errors.Is(err, errReset)
+		})).
+		WithPolicy(MatchAtMost(4, MatchErrors(errThrottled))).
+		WithRetryLimit(1, MatchErrors(errReset))
+
+	run := func(errs ...error) (int, error) {
+		var calls int
+		var final error
+		builder := config.clone().OnRetry(func(_ context.Context, e RetryEvent) { final = e.Final })
+		for attempt := range builder.Seq() {
+			calls++
+			if calls > len(errs) {
+				attempt.Result(nil)
+				continue
+			}
+			attempt.Result(errs[calls-1])
+		}
+		return calls, final
+	}
+
+	if calls, _ := run(errThrottled, errReset, errThrottled, errThrottled, errThrottled); calls != 6 {
+		t.Errorf("Expected each error class within its allowance to be retried, got %d calls", calls)
+	}
+	if calls, err := run(errReset, errThrottled, errReset); calls != 3 || !errors.Is(err, errReset) {
+		t.Errorf("Expected a second connection reset to stop retrying, got %d calls and %v", calls, err)
+	}
+	if calls, _ := run(errThrottled, errThrottled, errThrottled, errThrottled, errThrottled); calls != 5 {
+		t.Errorf("Expected a fifth 429 to stop retrying, got %d calls", calls)
+	}
+	for _, err := range matched {
+		if err != errThrottled && err != errReset {
+			t.Errorf("Expected the matcher to see only attempt errors, got %v", err)
+		}
+	}
+}
+
+func TestWithDebugSampling(t *testing.T) {
+	var traces []DebugTrace
+	var events int
+	builder := Iter().
+		WithName("sync").
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		WithTelemetrySampling(0).
+		OnRetry(func(context.Context, RetryEvent) { events++ }).
+		WithDebugSampling(1, func(trace DebugTrace) { traces = append(traces, trace) })
+
+	for attempt := range builder.Seq() {
+		attempt.Result(ErrTemporary)
+	}
+
+	if len(traces) != 1 {
+		t.Fatalf("Expected one trace, got %d", len(traces))
+	}
+	trace := traces[0]
+	if trace.Operation != "sync" || trace.CycleID == "" || len(trace.Attempts) != 3 {
+		t.Errorf("Expected full history of the cycle, got %+v", trace)
+	}
+	var maxErr *MaxAttemptsExceededError
+	if !errors.As(trace.Final, &maxErr) || trace.Attempts[2].Err != ErrTemporary || trace.Attempts[0].Diagnostics == nil {
+		t.Errorf("Expected attempt errors, diagnostics and final error, got %+v", trace)
+	}
+	if events != 3 {
+		t.Errorf("Expected traced cycles to emit every event despite telemetry sampling, got %d", events)
+	}
+
+	traces = nil
+	for attempt := range builder.WithDebugSampling(0, func(trace DebugTrace) { traces = append(traces, trace) }).Seq() {
+		attempt.Result(nil)
+	}
+	if len(traces) != 0 {
+		t.Errorf("Expected no traces at rate 0, got %d", len(traces))
+	}
+}
+
+func TestCancellationCauses(t *testing.T) {
+	for attempt := range Iter().WithMaxAttempts(1).WithAttemptTimeout(time.Millisecond).Seq() {
+		<-attempt.Context().Done()
+		if cause := context.Cause(attempt.Context()); !errors.Is(cause, ErrAttemptTimeout) {
+			t.Errorf("Expected attempt timeout cause, got %v", cause)
+		}
+	}
+
+	for attempt := range Iter().WithMaxAttempts(1).WithTimeout(time.Millisecond).Seq() {
+		<-attempt.Context().Done()
+		if cause := context.Cause(attempt.Context()); ErrorCode(cause) != CodeRetryTimeout {
+			t.Errorf("Expected retry timeout cause, got %v", cause)
+		}
+	}
+
+	lifecycle, shutdown := context.WithCancel(context.Background())
+	defer shutdown()
+	for attempt := range Iter().WithMaxAttempts(1).Bind(lifecycle).Seq() {
+		shutdown()
+		<-attempt.Context().Done()
+		if cause := context.Cause(attempt.Context()); !errors.Is(cause, ErrLifecycleDone) {
+			t.Errorf("Expected lifecycle cause, got %v", cause)
+		}
+	}
+
+	causes := make(chan error, 1)
+	for attempt := range Iter().WithMaxAttempts(1).Seq() {
+		attempt.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			causes <- context.Cause(ctx)
+			return nil
+		})
+		attempt.Result(ErrTemporary)
+		attempt.Wait()
+	}
+	if cause := <-causes; !errors.Is(cause, ErrAttemptFailed) || !errors.Is(cause, ErrTemporary) {
+		t.Errorf("Expected attempt failure cause, got %v", cause)
+	}
+}
+
+func TestIterator_NegativeCacheSeq(t *testing.T) {
+	errNotFound := errors.New("not found")
+	ctx := ContextWithCacheKey(context.Background(), "user:1")
+	b := Iter().WithContext(ctx).WithMaxAttempts(3).RetryIf(MatchErrors(ErrTemporary)).WithNegativeCache(time.Minute)
+
+	run := func() (error, int) {
+		var err error
+		calls := 0
+		for attempt := range b.Seq() {
+			if attempt.LastErr == nil {
+				calls++
+				err = errNotFound
+			} else {
+				err = attempt.LastErr
+			}
+			attempt.Result(err)
+		}
+		return err, calls
+	}
+
+	if err, calls := run(); !errors.Is(err, errNotFound) || calls != 1 {
+		t.Fatalf("Expected one failed call, got %v after %d", err, calls)
+	}
+
+	var cached error
+	for attempt := range b.Seq() {
+		cached = attempt.LastErr
+		if attempt.Context().Err() == nil || !errors.Is(context.Cause(attempt.Context()), ErrNegativeCached) {
+			t.Errorf("Expected context canceled with the cached failure, got %v", context.Cause(attempt.Context()))
+		}
+	}
+	if !errors.Is(cached, ErrNegativeCached) || !errors.Is(cached, errNotFound) {
+		t.Errorf("Expected cached failure as LastErr, got %v", cached)
+	}
+
+	if err, calls := run(); !errors.Is(err, ErrNegativeCached) || calls != 0 {
+		t.Errorf("Expected cached failure without a call, got %v after %d", err, calls)
+	}
+}
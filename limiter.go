@@ -0,0 +1,102 @@
+package recur
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// AdaptiveLimiter is a basic gradient-style concurrency limiter. It compares
+// each attempt's latency with a slowly moving long-term average: when latency
+// rises the limit shrinks, and when latency is stable the limit grows by
+// roughly its square root. Failed attempts are treated as a latency spike.
+type AdaptiveLimiter struct {
+	mu        sync.Mutex
+	limit     float64
+	minLimit  float64
+	maxLimit  float64
+	smoothing float64
+	longRTT   float64
+	inflight  int
+	released  chan struct{}
+}
+
+// NewAdaptiveLimiter creates a limiter starting at initial concurrent
+// attempts and never exceeding maxLimit
+func NewAdaptiveLimiter(initial, maxLimit int) *AdaptiveLimiter {
+	return &AdaptiveLimiter{
+		limit:     float64(initial),
+		minLimit:  1,
+		maxLimit:  float64(maxLimit),
+		smoothing: 0.2,
+		released:  make(chan struct{}),
+	}
+}
+
+// Acquire blocks until an attempt slot is available or ctx is done
+func (l *AdaptiveLimiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if float64(l.inflight) < l.limit {
+			l.inflight++
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Release returns a slot and updates the limit from the attempt's sample
+func (l *AdaptiveLimiter) Release(sample AttemptSample) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+	l.update(sample)
+
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// Limit returns the current concurrency limit
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// Inflight returns the number of attempts currently holding a slot
+func (l *AdaptiveLimiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inflight
+}
+
+func (l *AdaptiveLimiter) update(sample AttemptSample) {
+	rtt := float64(sample.Latency)
+	if rtt <= 0 {
+		rtt = float64(time.Nanosecond)
+	}
+	if l.longRTT == 0 {
+		l.longRTT = rtt
+	}
+
+	gradient := math.Max(0.5, math.Min(1.0, l.longRTT/rtt))
+	if sample.Err != nil {
+		gradient = 0.5
+	} else {
+		l.longRTT = l.longRTT*0.95 + rtt*0.05
+	}
+
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	newLimit = l.limit*(1-l.smoothing) + newLimit*l.smoothing
+	l.limit = math.Max(l.minLimit, math.Min(l.maxLimit, newLimit))
+}
@@ -0,0 +1,87 @@
+package recur
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveLimiter_GrowsOnStableLatency(t *testing.T) {
+	limiter := NewAdaptiveLimiter(4, 100)
+
+	for i := 0; i < 50; i++ {
+		if err := limiter.Acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		limiter.Release(AttemptSample{Attempt: 1, Latency: 10 * time.Millisecond})
+	}
+
+	if limiter.Limit() <= 4 {
+		t.Errorf("Expected limit to grow above 4, got %d", limiter.Limit())
+	}
+	if limiter.Inflight() != 0 {
+		t.Errorf("Expected 0 inflight, got %d", limiter.Inflight())
+	}
+}
+
+func TestAdaptiveLimiter_ShrinksOnFailures(t *testing.T) {
+	limiter := NewAdaptiveLimiter(50, 100)
+
+	for i := 0; i < 50; i++ {
+		if err := limiter.Acquire(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		limiter.Release(AttemptSample{Attempt: 1, Latency: 10 * time.Millisecond, Err: ErrTemporary})
+	}
+
+	if limiter.Limit() >= 50 {
+		t.Errorf("Expected limit to shrink below 50, got %d", limiter.Limit())
+	}
+}
+
+func TestAdaptiveLimiter_AcquireBlocksAtLimit(t *testing.T) {
+	limiter := NewAdaptiveLimiter(1, 1)
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Acquire(ctx); err == nil {
+		t.Error("Expected Acquire to block until context deadline")
+	}
+}
+
+func TestIterator_AdaptiveLimitAndSampler(t *testing.T) {
+	limiter := NewAdaptiveLimiter(2, 10)
+	var samples []AttemptSample
+
+	for attempt := range Iter().
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		WithAdaptiveLimit(limiter).
+		WithSampler(func(s AttemptSample) {
+			samples = append(samples, s)
+		}).
+		Seq() {
+		if limiter.Inflight() != 1 {
+			t.Errorf("Expected attempt to hold a slot, inflight=%d", limiter.Inflight())
+		}
+		if attempt.Number < 2 {
+			attempt.Result(ErrTemporary)
+			continue
+		}
+		attempt.Result(nil)
+	}
+
+	if len(samples) != 2 {
+		t.Fatalf("Expected 2 samples, got %d", len(samples))
+	}
+	if samples[0].Err != ErrTemporary || samples[1].Err != nil {
+		t.Errorf("Unexpected sample outcomes: %+v", samples)
+	}
+	if limiter.Inflight() != 0 {
+		t.Errorf("Expected all slots released, inflight=%d", limiter.Inflight())
+	}
+}
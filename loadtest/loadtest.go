@@ -0,0 +1,210 @@
+// Package loadtest soaks a retry policy against a synthetic flaky
+// dependency and reports what the policy achieves and what it costs, to
+// guide tuning before a policy meets a real outage.
+//
+//	report := loadtest.Run(ctx, loadtest.Config{
+//	    Policy:   recur.CombinePolicies(recur.MaxAttempts(4), recur.WithBackoff(recur.Exponential(10*time.Millisecond))),
+//	    Requests: 1000,
+//	    Dependency: loadtest.Dependency{
+//	        FailureRate: 0.2,
+//	        Latency:     loadtest.UniformLatency(time.Millisecond, 5*time.Millisecond),
+//	        Outages:     []loadtest.Outage{{Start: 200 * time.Millisecond, Duration: 100 * time.Millisecond}},
+//	    },
+//	})
+//	fmt.Println(report)
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+// ErrInjected is the error returned by failed calls to the synthetic dependency
+var ErrInjected = errors.New("loadtest: injected failure")
+
+// LatencyFunc draws the latency of one call
+type LatencyFunc func(r *rand.Rand) time.Duration
+
+// FixedLatency makes every call take d
+func FixedLatency(d time.Duration) LatencyFunc {
+	return func(*rand.Rand) time.Duration { return d }
+}
+
+// UniformLatency draws latencies uniformly from [lo, hi)
+func UniformLatency(lo, hi time.Duration) LatencyFunc {
+	return func(r *rand.Rand) time.Duration {
+		if hi <= lo {
+			return lo
+		}
+		return lo + time.Duration(r.Int64N(int64(hi-lo)))
+	}
+}
+
+// ExponentialLatency draws latencies from an exponential distribution with
+// the given mean, giving the long tail typical of real services
+func ExponentialLatency(mean time.Duration) LatencyFunc {
+	return func(r *rand.Rand) time.Duration {
+		return time.Duration(r.ExpFloat64() * float64(mean))
+	}
+}
+
+// Outage is a window, relative to the start of the run, during which every
+// call fails
+type Outage struct {
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// Dependency models the flaky service the policy retries against
+type Dependency struct {
+	FailureRate float64     // Probability that a call outside an outage fails
+	Latency     LatencyFunc // Call latency, zero if nil
+	Outages     []Outage
+}
+
+// Config describes a load test run
+type Config struct {
+	Policy      recur.Policy // Applied on top of the recur.Iter defaults
+	Dependency  Dependency
+	Requests    int    // Logical operations to perform
+	Concurrency int    // Operations in flight at once, 1 if zero
+	Seed        uint64 // Seed for reproducible failure and latency draws
+}
+
+// Report summarizes a run
+type Report struct {
+	Requests      int
+	Succeeded     int
+	Calls         int           // Calls made to the dependency, including retries
+	SuccessRate   float64       // Succeeded / Requests
+	Amplification float64       // Calls / Requests; the extra load retries add
+	P50           time.Duration // Per-request latency percentiles, including backoff
+	P90           time.Duration
+	P99           time.Duration
+	Max           time.Duration
+	Duration      time.Duration // Wall time of the run
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("requests=%d success=%.2f%% amplification=%.2fx p50=%v p90=%v p99=%v max=%v duration=%v",
+		r.Requests, r.SuccessRate*100, r.Amplification, r.P50, r.P90, r.P99, r.Max, r.Duration)
+}
+
+// Run performs cfg.Requests operations against the synthetic dependency
+// with cfg.Policy and reports the outcome. It stops early if ctx is done.
+func Run(ctx context.Context, cfg Config) Report {
+	concurrency := max(cfg.Concurrency, 1)
+	start := time.Now()
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		succeeded int
+		calls     int
+	)
+
+	jobs := make(chan struct{})
+	var wg sync.WaitGroup
+	for worker := range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(cfg.Seed, uint64(worker))) //nolint:gosec // simulation needs no crypto randomness
+			for range jobs {
+				began := time.Now()
+				ok, n := runRequest(ctx, cfg, rng, start)
+
+				mu.Lock()
+				latencies = append(latencies, time.Since(began))
+				calls += n
+				if ok {
+					succeeded++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for range cfg.Requests {
+		select {
+		case jobs <- struct{}{}:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := Report{
+		Requests:  len(latencies),
+		Succeeded: succeeded,
+		Calls:     calls,
+		Duration:  time.Since(start),
+	}
+	if report.Requests > 0 {
+		report.SuccessRate = float64(succeeded) / float64(report.Requests)
+		report.Amplification = float64(calls) / float64(report.Requests)
+
+		slices.Sort(latencies)
+		report.P50 = percentile(latencies, 0.50)
+		report.P90 = percentile(latencies, 0.90)
+		report.P99 = percentile(latencies, 0.99)
+		report.Max = latencies[len(latencies)-1]
+	}
+	return report
+}
+
+// runRequest performs one logical operation and returns whether it
+// succeeded and how many calls it made
+func runRequest(ctx context.Context, cfg Config, rng *rand.Rand, start time.Time) (bool, int) {
+	builder := recur.Iter().WithContext(ctx)
+	if cfg.Policy != nil {
+		builder.WithPolicy(cfg.Policy)
+	}
+
+	calls := 0
+	succeeded := false
+	for attempt := range builder.Seq() {
+		calls++
+		err := call(attempt.Context(), cfg.Dependency, rng, time.Since(start))
+		succeeded = err == nil
+		attempt.Result(err)
+	}
+	return succeeded, calls
+}
+
+// call simulates one call to the dependency at offset into the run
+func call(ctx context.Context, dep Dependency, rng *rand.Rand, offset time.Duration) error {
+	if dep.Latency != nil {
+		timer := time.NewTimer(dep.Latency(rng))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	for _, o := range dep.Outages {
+		if offset >= o.Start && offset < o.Start+o.Duration {
+			return ErrInjected
+		}
+	}
+	if rng.Float64() < dep.FailureRate {
+		return ErrInjected
+	}
+	return nil
+}
+
+// percentile returns the p-th percentile of sorted
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted)-1) * p)
+	return sorted[i]
+}
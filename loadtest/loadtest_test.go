@@ -0,0 +1,58 @@
+package loadtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+func TestRun_RetriesImproveSuccessRate(t *testing.T) {
+	dep := Dependency{FailureRate: 0.3}
+
+	single := Run(context.Background(), Config{
+		Policy:     recur.MaxAttempts(1),
+		Requests:   2000,
+		Seed:       1,
+		Dependency: dep,
+	})
+	retried := Run(context.Background(), Config{
+		Policy:      recur.CombinePolicies(recur.MaxAttempts(4), recur.WithBackoff(recur.NoDelay())),
+		Requests:    2000,
+		Concurrency: 4,
+		Seed:        1,
+		Dependency:  dep,
+	})
+
+	if single.Amplification != 1 {
+		t.Errorf("Expected no amplification without retries, got %.2f", single.Amplification)
+	}
+	if single.SuccessRate < 0.6 || single.SuccessRate > 0.8 {
+		t.Errorf("Expected ~70%% success without retries, got %.2f", single.SuccessRate)
+	}
+	if retried.SuccessRate < 0.98 {
+		t.Errorf("Expected ~99%% success with 4 attempts, got %.2f", retried.SuccessRate)
+	}
+	if retried.Amplification <= 1.2 || retried.Amplification > 1.6 {
+		t.Errorf("Expected ~1.4x amplification, got %.2f", retried.Amplification)
+	}
+}
+
+func TestRun_OutageAndLatency(t *testing.T) {
+	report := Run(context.Background(), Config{
+		Policy:   recur.CombinePolicies(recur.MaxAttempts(2), recur.WithBackoff(recur.NoDelay())),
+		Requests: 20,
+		Dependency: Dependency{
+			Latency: FixedLatency(time.Millisecond),
+			Outages: []Outage{{Start: 0, Duration: time.Hour}},
+		},
+	})
+
+	if report.Succeeded != 0 || report.Calls != 40 {
+		t.Errorf("Expected every request to fail twice during the outage, got %+v", report)
+	}
+	if report.P50 < 2*time.Millisecond {
+		t.Errorf("Expected latency to include both calls, got p50 %v", report.P50)
+	}
+}
@@ -0,0 +1,224 @@
+// Package migrate runs ordered database migration steps with retries.
+// Each step is retried on its own, and a step that finds another process
+// holding the migration lock waits on a separate, longer backoff instead of
+// burning through its retries. Run returns a Report of every step's
+// attempts for deployment logs.
+//
+//	report, err := migrate.NewRunner(
+//	    migrate.Step{Name: "001_create_users", Up: createUsers},
+//	    migrate.Step{Name: "002_add_email_index", Up: addEmailIndex},
+//	).WithPolicy(recur.MaxAttempts(5)).Run(ctx)
+//	for _, step := range report.Steps {
+//	    log.Printf("%s: %d attempts, %d lock waits, %v", step.Name, step.Attempts, step.LockWaits, step.Err)
+//	}
+//
+// Steps are rerun after a failed attempt, so each must be idempotent or
+// run in a transaction that rolls back on failure.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+// ErrLocked reports that another migration holds the migration lock.
+// Steps may return it, wrapped or not, to have the attempt treated as a
+// lock wait.
+var ErrLocked error = lockedError{}
+
+// CodeLocked is the code of ErrLocked, see recur.RecurError
+const CodeLocked = "migrate_locked"
+
+// lockedError is the type of ErrLocked
+type lockedError struct{}
+
+func (lockedError) Error() string { return "migrate: another migration in progress" }
+
+func (lockedError) Code() string { return CodeLocked }
+
+// Step is a single named migration
+type Step struct {
+	Name string
+	Up   func(ctx context.Context) error
+}
+
+// DefaultPolicy retries each step with exponential backoff
+var DefaultPolicy = recur.CombinePolicies(
+	recur.MaxAttempts(10),
+	recur.WithBackoff(recur.Exponential(100*time.Millisecond).(*recur.ExponentialBackoff).WithMaxDelay(5*time.Second)),
+)
+
+// DefaultLockBackoff is the wait between attempts that found the
+// migration lock held
+var DefaultLockBackoff = recur.Jitter(recur.Exponential(time.Second).(*recur.ExponentialBackoff).WithMaxDelay(30*time.Second), 0.2)
+
+// Messages of common migration tools and databases when the lock is held
+var lockMessages = []string{
+	"another migration in progress",
+	"another migration is in progress",
+	"migration lock",
+	"database is locked",
+	"could not obtain lock",
+	"lock wait timeout exceeded",
+}
+
+// SQLSTATE lock_not_available
+const lockNotAvailable = "55P03"
+
+type sqlStater interface {
+	SQLState() string
+}
+
+// Locked reports whether err means another process holds the migration
+// lock: ErrLocked, SQLSTATE 55P03, or a lock message from a common
+// migration tool or database
+func Locked(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrLocked) {
+		return true
+	}
+	var stater sqlStater
+	if errors.As(err, &stater) && stater.SQLState() == lockNotAvailable {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return slices.ContainsFunc(lockMessages, func(s string) bool { return strings.Contains(msg, s) })
+}
+
+// StepReport describes how a step ran
+type StepReport struct {
+	Name      string
+	Attempts  int
+	LockWaits int // Attempts that found the migration lock held
+	Duration  time.Duration
+	Err       error // Final error, nil if the step was applied
+}
+
+// Report describes a migration run
+type Report struct {
+	// Steps that ran, in order. The run stops at the first step that fails,
+	// so later steps are absent.
+	Steps []StepReport
+}
+
+// Applied returns the names of the steps that were applied
+func (r *Report) Applied() []string {
+	var names []string
+	for _, step := range r.Steps {
+		if step.Err == nil {
+			names = append(names, step.Name)
+		}
+	}
+	return names
+}
+
+// Failed returns the step that stopped the run, if any
+func (r *Report) Failed() (StepReport, bool) {
+	if len(r.Steps) == 0 || r.Steps[len(r.Steps)-1].Err == nil {
+		return StepReport{}, false
+	}
+	return r.Steps[len(r.Steps)-1], true
+}
+
+// Runner applies migration steps in order
+type Runner struct {
+	steps       []Step
+	policies    []recur.Policy
+	lockBackoff recur.Backoff
+}
+
+// NewRunner creates a runner applying steps in order with DefaultPolicy
+// and DefaultLockBackoff
+func NewRunner(steps ...Step) *Runner {
+	return &Runner{
+		steps:       steps,
+		policies:    []recur.Policy{DefaultPolicy},
+		lockBackoff: DefaultLockBackoff,
+	}
+}
+
+// WithPolicy applies a policy to every step after DefaultPolicy
+func (r *Runner) WithPolicy(policy recur.Policy) *Runner {
+	r.policies = append(r.policies, policy)
+	return r
+}
+
+// WithLockBackoff sets the wait between attempts that found the migration
+// lock held, indexed by the step's lock waits so far. Lock waits don't
+// count against the policy's attempts: after each one the step starts a
+// fresh retry cycle, so only the context bounds how long a step waits for
+// the lock.
+func (r *Runner) WithLockBackoff(b recur.Backoff) *Runner {
+	r.lockBackoff = b
+	return r
+}
+
+// Run applies the steps in order, stopping at the first step that fails
+// after its retries. The report covers every step that ran, including the
+// failed one, and the error names the failed step.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	report := &Report{}
+	for _, step := range r.steps {
+		result := r.apply(ctx, step)
+		report.Steps = append(report.Steps, result)
+		if result.Err != nil {
+			return report, fmt.Errorf("migrate: step %q: %w", step.Name, result.Err)
+		}
+	}
+	return report, nil
+}
+
+func (r *Runner) apply(ctx context.Context, step Step) StepReport {
+	result := StepReport{Name: step.Name, Err: errNotAttempted}
+	start := time.Now()
+	for {
+		locked, ran := false, false
+		for attempt := range recur.Iter().WithContext(ctx).WithPolicy(recur.CombinePolicies(r.policies...)).Seq() {
+			ran = true
+			result.Attempts++
+			err := step.Up(attempt.Context())
+			result.Err = err
+			attempt.Result(err)
+			if locked = Locked(err); locked {
+				break
+			}
+		}
+		if !ran && result.LockWaits > 0 && ctx.Err() != nil {
+			// The context ended as a lock wait did, before another attempt
+			result.Err = fmt.Errorf("%w: %w", ctx.Err(), result.Err)
+			break
+		}
+		if !locked {
+			break
+		}
+		result.LockWaits++
+		if err := sleep(ctx, r.lockBackoff.Next(result.LockWaits)); err != nil {
+			result.Err = fmt.Errorf("%w: %w", err, result.Err)
+			break
+		}
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var errNotAttempted = errors.New("migrate: step not attempted")
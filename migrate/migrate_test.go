@@ -0,0 +1,146 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+var errTimeout = errors.New("i/o timeout")
+
+type pgError struct{ code string }
+
+func (e *pgError) Error() string    { return "pq: " + e.code }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestLocked(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errTimeout, false},
+		{ErrLocked, true},
+		{fmt.Errorf("step: %w", ErrLocked), true},
+		{&pgError{code: "55P03"}, true},
+		{&pgError{code: "40001"}, false},
+		{errors.New("Another migration is in progress"), true},
+		{errors.New("sqlite: database is locked"), true},
+	}
+	for _, tt := range tests {
+		if got := Locked(tt.err); got != tt.want {
+			t.Errorf("Locked(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+	if code := recur.ErrorCode(fmt.Errorf("step: %w", ErrLocked)); code != CodeLocked {
+		t.Errorf("Expected ErrLocked to carry code %q, got %q", CodeLocked, code)
+	}
+}
+
+func TestRunner_RetriesStepsInOrder(t *testing.T) {
+	var order []string
+	calls := map[string]int{}
+	step := func(name string, failures int) Step {
+		return Step{Name: name, Up: func(ctx context.Context) error {
+			calls[name]++
+			if calls[name] <= failures {
+				return errTimeout
+			}
+			order = append(order, name)
+			return nil
+		}}
+	}
+
+	report, err := NewRunner(step("001", 0), step("002", 2), step("003", 1)).
+		WithPolicy(recur.WithBackoff(recur.NoDelay())).
+		Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(order, []string{"001", "002", "003"}) {
+		t.Errorf("Expected steps applied in order, got %v", order)
+	}
+	if got := report.Applied(); !slices.Equal(got, order) {
+		t.Errorf("Expected report to list applied steps, got %v", got)
+	}
+	if attempts := report.Steps[1].Attempts; attempts != 3 {
+		t.Errorf("Expected 3 attempts for step 002, got %d", attempts)
+	}
+	if _, failed := report.Failed(); failed {
+		t.Error("Expected no failed step")
+	}
+}
+
+func TestRunner_WaitsOnLock(t *testing.T) {
+	var calls int
+	var retries int
+	report, err := NewRunner(Step{Name: "001", Up: func(ctx context.Context) error {
+		calls++
+		if calls <= 5 {
+			return ErrLocked
+		}
+		return nil
+	}}).
+		WithPolicy(recur.CombinePolicies(
+			recur.MaxAttempts(2),
+			recur.WithBackoff(recur.NoDelay()),
+			recur.OnRetry(func(_ context.Context, e recur.RetryEvent) {
+				if e.WillRetry {
+					retries++
+				}
+			}),
+		)).
+		WithLockBackoff(recur.Constant(time.Millisecond)).
+		Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if step := report.Steps[0]; step.Attempts != 6 || step.LockWaits != 5 || step.Duration < 5*time.Millisecond {
+		t.Errorf("Expected 6 attempts after 5 lock waits outside the attempt budget, got %+v", step)
+	}
+	if retries != 0 {
+		t.Errorf("Expected lock waits not to use retries, got %d", retries)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls = 0
+	report, err = NewRunner(Step{Name: "001", Up: func(ctx context.Context) error {
+		if calls++; calls == 3 {
+			cancel() // The deploy is aborted while the lock is still held
+		}
+		return ErrLocked
+	}}).WithLockBackoff(recur.NoDelay()).Run(ctx)
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, ErrLocked) || report.Steps[0].LockWaits != 3 {
+		t.Errorf("Expected the context to end lock waits, got %v after %+v", err, report.Steps[0])
+	}
+}
+
+func TestRunner_StopsAtFailedStep(t *testing.T) {
+	var ranLast bool
+	report, err := NewRunner(
+		Step{Name: "001", Up: func(ctx context.Context) error { return nil }},
+		Step{Name: "002", Up: func(ctx context.Context) error { return errTimeout }},
+		Step{Name: "003", Up: func(ctx context.Context) error { ranLast = true; return nil }},
+	).WithPolicy(recur.CombinePolicies(recur.MaxAttempts(2), recur.WithBackoff(recur.NoDelay()))).
+		Run(context.Background())
+
+	if !errors.Is(err, errTimeout) {
+		t.Fatalf("Expected step error, got %v", err)
+	}
+	if ranLast {
+		t.Error("Expected steps after the failed one not to run")
+	}
+	failed, ok := report.Failed()
+	if !ok || failed.Name != "002" || failed.Attempts != 2 {
+		t.Errorf("Expected step 002 reported as failed after 2 attempts, got %+v", failed)
+	}
+	if len(report.Steps) != 2 {
+		t.Errorf("Expected 2 steps in report, got %d", len(report.Steps))
+	}
+}
@@ -0,0 +1,143 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNegativeCached reports that a call failed fast because the same key
+// failed non-retryably within the negative cache's TTL. It wraps the cached
+// failure.
+var ErrNegativeCached error = &codedError{code: CodeNegativeCached, msg: "failure cached"}
+
+type cacheKeyKey struct{}
+
+// ContextWithCacheKey returns a context identifying the call for
+// WithNegativeCache. Cycles without a key bypass the cache.
+func ContextWithCacheKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, cacheKeyKey{}, key)
+}
+
+// cacheKey returns the negative cache key carried by ctx
+func cacheKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(cacheKeyKey{}).(string)
+	return key, ok
+}
+
+// negativeCache remembers non-retryable failures per key until they expire
+type negativeCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]negativeEntry
+	sweepAt int
+	now     func() time.Time
+}
+
+type negativeEntry struct {
+	err     error
+	expires time.Time
+}
+
+// negativeCacheSweep is the minimum number of entries before expired ones
+// are swept
+const negativeCacheSweep = 64
+
+func newNegativeCache(ttl time.Duration) *negativeCache {
+	return &negativeCache{
+		ttl:     ttl,
+		entries: make(map[string]negativeEntry),
+		sweepAt: negativeCacheSweep,
+		now:     time.Now,
+	}
+}
+
+// get returns the unexpired failure cached for key
+func (c *negativeCache) get(key string) (error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.err, true
+}
+
+// put caches err for key, sweeping expired entries as the cache grows
+func (c *negativeCache) put(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	c.entries[key] = negativeEntry{err: err, expires: now.Add(c.ttl)}
+	if len(c.entries) < c.sweepAt {
+		return
+	}
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.sweepAt = max(2*len(c.entries), negativeCacheSweep)
+}
+
+// WithNegativeCache remembers non-retryable failures for ttl, keyed by the
+// cycle's ContextWithCacheKey key. Identical calls within ttl fail fast
+// with ErrNegativeCached, wrapping the original failure, without running
+// an attempt. This complements a circuit breaker for per-key failures such
+// as a 404 for one resource. Exhausted attempts and cancellations are not
+// cached.
+//
+// Example:
+//
+//	get := recur.FuncR(...).WithNegativeCache(30 * time.Second).BuildContext()
+//	user, err := get(recur.ContextWithCacheKey(ctx, "user:"+id))
+func (b *IteratorBuilder) WithNegativeCache(ttl time.Duration) *IteratorBuilder {
+	b.negative = newNegativeCache(ttl)
+	return b
+}
+
+// CacheFailures creates a policy that caches non-retryable failures per
+// key, see IteratorBuilder.WithNegativeCache
+func CacheFailures(ttl time.Duration) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithNegativeCache(ttl)
+	}
+}
+
+// cachedFailure returns the error failing the cycle fast if its key has a
+// cached failure
+func (s *iteratorState) cachedFailure() error {
+	if s.builder.negative == nil {
+		return nil
+	}
+	key, ok := cacheKey(s.ctx)
+	if !ok {
+		return nil
+	}
+	err, ok := s.builder.negative.get(key)
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrNegativeCached, err)
+}
+
+// rememberFailure caches the cycle's failure if it was non-retryable
+func (s *iteratorState) rememberFailure(exhausted bool) {
+	if s.builder.negative == nil {
+		return
+	}
+	key, ok := cacheKey(s.ctx)
+	if !ok {
+		return
+	}
+	var nonRetryable *NonRetryableError
+	if err := s.finalError(exhausted); errors.As(err, &nonRetryable) {
+		s.builder.negative.put(key, err)
+	}
+}
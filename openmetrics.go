@@ -0,0 +1,100 @@
+package recur
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// OpenMetricsContentType is the Content-Type for WriteOpenMetrics output
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// metricFamilies maps each exported counter to its collector field
+var metricFamilies = []struct {
+	name, help string
+	value      func(m *MetricsCollector) int64
+}{
+	{"recur_cycles", "Completed retry cycles.", func(m *MetricsCollector) int64 { return m.TotalAttempts.Load() }},
+	{"recur_successes", "Retry cycles that succeeded.", func(m *MetricsCollector) int64 { return m.SuccessCount.Load() }},
+	{"recur_failures", "Retry cycles that failed.", func(m *MetricsCollector) int64 { return m.FailureCount.Load() }},
+	{"recur_attempts", "Attempts run, including first attempts.", func(m *MetricsCollector) int64 { return m.AttemptCount.Load() }},
+	{"recur_retries", "Attempts after the first in a cycle.", func(m *MetricsCollector) int64 { return m.TotalRetries.Load() }},
+	{"recur_abandoned", "Operations abandoned after ignoring cancellation.", func(m *MetricsCollector) int64 { return m.AbandonedCount.Load() }},
+}
+
+// gaugeFamilies maps each exported moving average to its value. Collectors
+// without a sample yet are left out rather than reported as zero, so alerts
+// on low success rates don't fire for idle retriers.
+var gaugeFamilies = []struct {
+	name, help string
+	value      func(m *MetricsCollector) *movingAverage
+}{
+	{"recur_success_rate", "Moving average of cycle outcomes, from 0 to 1.",
+		func(m *MetricsCollector) *movingAverage { return &m.averages.successRate }},
+	{"recur_attempts_per_success", "Moving average of attempts per successful cycle.",
+		func(m *MetricsCollector) *movingAverage { return &m.averages.attemptsPerSuccess }},
+}
+
+// WriteOpenMetrics writes the collector's counters in OpenMetrics text
+// format, labelled with its name. Variant collectors are written too,
+// labelled with their variant.
+func (m *MetricsCollector) WriteOpenMetrics(w io.Writer) error {
+	return WriteOpenMetrics(w, m)
+}
+
+// WriteOpenMetrics writes the counters and moving averages of all
+// collectors in OpenMetrics text format, which Prometheus scrapes natively,
+// without depending on a metrics client. Nil collectors are skipped, so
+// retriers without metrics can be passed as they are.
+//
+// Example:
+//
+//	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+//	    w.Header().Set("Content-Type", recur.OpenMetricsContentType)
+//	    recur.WriteOpenMetrics(w, fetchMetrics, storeMetrics)
+//	})
+func WriteOpenMetrics(w io.Writer, collectors ...*MetricsCollector) error {
+	var all []*MetricsCollector
+	for _, m := range collectors {
+		if m == nil {
+			continue
+		}
+		all = append(all, m)
+		all = append(all, m.Variants()...)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, family := range metricFamilies {
+		fmt.Fprintf(bw, "# TYPE %s counter\n", family.name)
+		fmt.Fprintf(bw, "# HELP %s %s\n", family.name, family.help)
+		for _, m := range all {
+			fmt.Fprintf(bw, "%s_total{%s} %d\n", family.name, m.labels(), family.value(m))
+		}
+	}
+	for _, family := range gaugeFamilies {
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", family.name)
+		fmt.Fprintf(bw, "# HELP %s %s\n", family.name, family.help)
+		for _, m := range all {
+			if v, ok := family.value(m).load(); ok {
+				fmt.Fprintf(bw, "%s{%s} %g\n", family.name, m.labels(), v)
+			}
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// labels formats the collector's OpenMetrics label set
+func (m *MetricsCollector) labels() string {
+	if m.variant == "" {
+		return fmt.Sprintf(`name="%s"`, escapeLabel(m.name))
+	}
+	return fmt.Sprintf(`name="%s",variant="%s"`, escapeLabel(m.name), escapeLabel(m.variant))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
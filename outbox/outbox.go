@@ -0,0 +1,287 @@
+// Package outbox implements the transactional outbox pattern on top of
+// recur: side effects are recorded in the same transaction as the state
+// change that causes them, and a Dispatcher delivers them afterwards with
+// retries, so a crash between commit and delivery never loses a message.
+//
+//	// Inside the business transaction
+//	err := store.Add(txCtx, outbox.Message{ID: id, Topic: "order.created", Payload: body})
+//
+//	// In a background goroutine
+//	d := outbox.NewDispatcher(store, publish).WithPolicy(recur.MaxAttempts(5))
+//	err := d.Run(ctx, time.Second)
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+// Message is a side effect waiting to be delivered
+type Message struct {
+	ID        string
+	Topic     string
+	Payload   []byte
+	CreatedAt time.Time
+	Failures  int // Delivery cycles that gave up so far
+
+	// NextAttemptAt is when delivery may be tried again after a failure,
+	// zero if the message is due immediately
+	NextAttemptAt time.Time
+}
+
+// Due reports whether the message may be delivered at now
+func (m Message) Due(now time.Time) bool {
+	return !now.Before(m.NextAttemptAt)
+}
+
+// Storage persists outbox messages. Implementations backed by a database
+// should make Add join the caller's transaction, typically carried in ctx.
+type Storage interface {
+	// Add records a message for delivery
+	Add(ctx context.Context, msg Message) error
+	// Pending returns up to limit undelivered messages, oldest first.
+	// Storage implementing RetryScheduler should return only due messages.
+	Pending(ctx context.Context, limit int) ([]Message, error)
+	// MarkDelivered removes or flags a delivered message
+	MarkDelivered(ctx context.Context, id string) error
+	// MarkFailed records that delivering a message gave up with err. The
+	// message stays pending unless the storage moves it aside.
+	MarkFailed(ctx context.Context, id string, err error) error
+}
+
+// RetryScheduler is implemented by storage that can persist when a failed
+// message is due again. Because the timestamp is stored with the message,
+// long redelivery backoffs survive process restarts: on startup, the first
+// pass delivers every message that fell due while the process was down.
+type RetryScheduler interface {
+	// ScheduleRetry records that the message id is due again at at
+	ScheduleRetry(ctx context.Context, id string, at time.Time) error
+}
+
+// DefaultBatchSize is the number of messages a Dispatcher loads per pass
+const DefaultBatchSize = 100
+
+// Dispatcher delivers pending messages with retries
+type Dispatcher struct {
+	storage  Storage
+	deliver  func(ctx context.Context, msg Message) error
+	policies []recur.Policy
+	batch    int
+	backoff  recur.Backoff
+	onError  func(error)
+	now      func() time.Time
+}
+
+// NewDispatcher creates a dispatcher that delivers messages from storage
+// with deliver. deliver may be called more than once for a message, so
+// consumers must tolerate duplicates.
+func NewDispatcher(storage Storage, deliver func(ctx context.Context, msg Message) error) *Dispatcher {
+	return &Dispatcher{
+		storage: storage,
+		deliver: deliver,
+		batch:   DefaultBatchSize,
+		now:     time.Now,
+	}
+}
+
+// WithPolicy applies a policy to each message's delivery
+func (d *Dispatcher) WithPolicy(policy recur.Policy) *Dispatcher {
+	d.policies = append(d.policies, policy)
+	return d
+}
+
+// WithBatchSize sets how many messages are loaded per pass. Sizes below 1
+// use DefaultBatchSize.
+func (d *Dispatcher) WithBatchSize(n int) *Dispatcher {
+	if n <= 0 {
+		n = DefaultBatchSize
+	}
+	d.batch = n
+	return d
+}
+
+// OnError sets fn to receive the storage errors that end a pass of Run,
+// which otherwise retries them silently on the next tick
+func (d *Dispatcher) OnError(fn func(error)) *Dispatcher {
+	d.onError = fn
+	return d
+}
+
+// WithRedeliveryBackoff spaces out delivery cycles of a message that keeps
+// failing: after its nth failed cycle, it is due again b.Next(n) later.
+// The timestamp is persisted if the storage implements RetryScheduler,
+// so backoffs of hours aren't lost on restart; other storage is retried
+// on the next pass as before.
+//
+// Example:
+//
+//	d.WithRedeliveryBackoff(recur.Exponential(time.Minute).(*recur.ExponentialBackoff).WithMaxDelay(6 * time.Hour))
+func (d *Dispatcher) WithRedeliveryBackoff(b recur.Backoff) *Dispatcher {
+	d.backoff = b
+	return d
+}
+
+// DispatchOnce delivers one batch of pending messages in order and returns
+// how many were delivered. Messages whose delivery gives up are marked
+// failed and left for a later pass; only storage and context errors are
+// returned.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	msgs, err := d.storage.Pending(ctx, d.batch)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	now := d.now()
+	for _, msg := range msgs {
+		if !msg.Due(now) {
+			continue
+		}
+		err := d.dispatch(ctx, msg)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return delivered, ctxErr
+		}
+		if err != nil {
+			if err := d.markFailed(ctx, msg, err); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := d.storage.MarkDelivered(ctx, msg.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// Run dispatches pending messages every interval until ctx is done. A pass
+// that delivers a full batch is followed immediately by the next one. A
+// pass failing with a storage error is reported to OnError and tried again
+// on the next tick.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		delivered, err := d.DispatchOnce(ctx)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && d.onError != nil {
+			d.onError(err)
+		}
+		if err == nil && delivered == d.batch {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// markFailed records a failed delivery cycle and schedules the next one
+func (d *Dispatcher) markFailed(ctx context.Context, msg Message, cause error) error {
+	if err := d.storage.MarkFailed(ctx, msg.ID, cause); err != nil {
+		return err
+	}
+	scheduler, ok := d.storage.(RetryScheduler)
+	if !ok || d.backoff == nil {
+		return nil
+	}
+	return scheduler.ScheduleRetry(ctx, msg.ID, d.now().Add(d.backoff.Next(msg.Failures+1)))
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, msg Message) error {
+	err := errNotAttempted
+	for attempt := range recur.Iter().WithContext(ctx).WithPolicy(recur.CombinePolicies(d.policies...)).Seq() {
+		err = d.deliver(attempt.Context(), msg)
+		attempt.Result(err)
+	}
+	return err
+}
+
+var errNotAttempted = errors.New("outbox: delivery not attempted")
+
+// MemoryStorage is an in-memory Storage for tests and single-process use.
+// It is safe for concurrent use.
+type MemoryStorage struct {
+	mu   sync.Mutex
+	msgs []Message
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{}
+}
+
+// Add implements Storage
+func (s *MemoryStorage) Add(ctx context.Context, msg Message) error {
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+// Pending implements Storage, returning only due messages
+func (s *MemoryStorage) Pending(ctx context.Context, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var pending []Message
+	for _, msg := range s.msgs {
+		if len(pending) == limit {
+			break
+		}
+		if msg.Due(now) {
+			pending = append(pending, msg)
+		}
+	}
+	return pending, nil
+}
+
+// MarkDelivered implements Storage
+func (s *MemoryStorage) MarkDelivered(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = slices.DeleteFunc(s.msgs, func(m Message) bool { return m.ID == id })
+	return nil
+}
+
+// MarkFailed implements Storage
+func (s *MemoryStorage) MarkFailed(ctx context.Context, id string, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.msgs {
+		if s.msgs[i].ID == id {
+			s.msgs[i].Failures++
+		}
+	}
+	return nil
+}
+
+// ScheduleRetry implements RetryScheduler
+func (s *MemoryStorage) ScheduleRetry(ctx context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.msgs {
+		if s.msgs[i].ID == id {
+			s.msgs[i].NextAttemptAt = at
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+var errUnavailable = errors.New("broker unavailable")
+
+func TestDispatcher_DeliversWithRetries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage()
+	_ = store.Add(ctx, Message{ID: "1", Topic: "order.created"})
+	_ = store.Add(ctx, Message{ID: "2", Topic: "order.paid"})
+
+	calls := map[string]int{}
+	d := NewDispatcher(store, func(ctx context.Context, msg Message) error {
+		calls[msg.ID]++
+		if msg.ID == "1" && calls[msg.ID] < 2 {
+			return errUnavailable
+		}
+		if msg.ID == "2" {
+			return errUnavailable
+		}
+		return nil
+	}).WithPolicy(recur.CombinePolicies(recur.MaxAttempts(3), recur.WithBackoff(recur.NoDelay())))
+
+	delivered, err := d.DispatchOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 1 || calls["1"] != 2 || calls["2"] != 3 {
+		t.Errorf("Expected 1 delivered after retries, got %d with calls %v", delivered, calls)
+	}
+
+	pending, _ := store.Pending(ctx, 10)
+	if len(pending) != 1 || pending[0].ID != "2" || pending[0].Failures != 1 {
+		t.Errorf("Expected undelivered message to stay pending with a failure, got %+v", pending)
+	}
+}
+
+func TestDispatcher_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := NewMemoryStorage()
+	_ = store.Add(ctx, Message{ID: "1"})
+
+	d := NewDispatcher(store, func(ctx context.Context, msg Message) error {
+		cancel()
+		return ctx.Err()
+	})
+
+	if _, err := d.DispatchOnce(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if pending, _ := store.Pending(context.Background(), 10); len(pending) != 1 || pending[0].Failures != 0 {
+		t.Errorf("Expected message untouched, got %+v", pending)
+	}
+}
+
+func TestDispatcher_DurableRedelivery(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStorage()
+	_ = store.Add(ctx, Message{ID: "1"})
+
+	failing := NewDispatcher(store, func(ctx context.Context, msg Message) error {
+		return errUnavailable
+	}).WithPolicy(recur.MaxAttempts(1)).
+		WithRedeliveryBackoff(recur.Constant(time.Hour))
+	if _, err := failing.DispatchOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := store.Pending(ctx, 10); len(pending) != 0 {
+		t.Fatalf("Expected failed message to wait out its backoff, got %+v", pending)
+	}
+
+	// Simulate a restart after the backoff elapsed
+	store.msgs[0].NextAttemptAt = time.Now().Add(-time.Minute)
+	var delivered []Message
+	restarted := NewDispatcher(store, func(ctx context.Context, msg Message) error {
+		delivered = append(delivered, msg)
+		return nil
+	})
+	if n, err := restarted.DispatchOnce(ctx); err != nil || n != 1 {
+		t.Fatalf("Expected overdue message to be delivered on startup, got %d, %v", n, err)
+	}
+	if delivered[0].Failures != 1 {
+		t.Errorf("Expected the failure count to survive the restart, got %+v", delivered[0])
+	}
+}
+
+// brokenStorage fails every Pending call
+type brokenStorage struct {
+	*MemoryStorage
+}
+
+var errStorage = errors.New("storage down")
+
+func (brokenStorage) Pending(context.Context, int) ([]Message, error) {
+	return nil, errStorage
+}
+
+func TestDispatcher_RunReportsStorageErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reported []error
+	d := NewDispatcher(brokenStorage{NewMemoryStorage()}, func(ctx context.Context, msg Message) error {
+		return nil
+	}).WithBatchSize(0).OnError(func(err error) {
+		reported = append(reported, err)
+		if len(reported) == 2 {
+			cancel()
+		}
+	})
+	if d.batch != DefaultBatchSize {
+		t.Errorf("Expected batch size 0 to use the default, got %d", d.batch)
+	}
+
+	if err := d.Run(ctx, time.Millisecond); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(reported) != 2 || !errors.Is(reported[0], errStorage) {
+		t.Errorf("Expected storage errors to be reported, got %v", reported)
+	}
+}
@@ -0,0 +1,81 @@
+package recur
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Outcome accumulates what retry cycles running under a context did, so
+// middleware higher in the chain can report whether a request involved
+// retries. It is safe for concurrent use by parallel downstream calls.
+type Outcome struct {
+	cycles   atomic.Int64
+	attempts atomic.Int64
+	retries  atomic.Int64
+	delay    atomic.Int64
+}
+
+// OutcomeSummary is a point-in-time copy of an Outcome
+type OutcomeSummary struct {
+	Cycles     int64         `json:"cycles"`   // Retry cycles started
+	Attempts   int64         `json:"attempts"` // Attempts run, including first attempts
+	Retries    int64         `json:"retries"`  // Attempts after the first in a cycle
+	TotalDelay time.Duration `json:"total_delay"`
+}
+
+type outcomeKey struct{}
+
+// ContextWithOutcome returns a context that records the retry cycles run
+// under it into the returned Outcome
+//
+// Example:
+//
+//	func accessLog(next http.Handler) http.Handler {
+//	    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	        ctx, outcome := recur.ContextWithOutcome(r.Context())
+//	        next.ServeHTTP(w, r.WithContext(ctx))
+//	        s := outcome.Summary()
+//	        log.Printf("%s %s retries=%d delay=%v", r.Method, r.URL.Path, s.Retries, s.TotalDelay)
+//	    })
+//	}
+func ContextWithOutcome(ctx context.Context) (context.Context, *Outcome) {
+	o := &Outcome{}
+	return context.WithValue(ctx, outcomeKey{}, o), o
+}
+
+// OutcomeFromContext returns the Outcome installed by ContextWithOutcome,
+// if any
+func OutcomeFromContext(ctx context.Context) (*Outcome, bool) {
+	o, ok := ctx.Value(outcomeKey{}).(*Outcome)
+	return o, ok
+}
+
+// Summary returns the outcome's current counts
+func (o *Outcome) Summary() OutcomeSummary {
+	return OutcomeSummary{
+		Cycles:     o.cycles.Load(),
+		Attempts:   o.attempts.Load(),
+		Retries:    o.retries.Load(),
+		TotalDelay: time.Duration(o.delay.Load()),
+	}
+}
+
+// Retried reports whether any cycle made more than one attempt
+func (o *Outcome) Retried() bool {
+	return o.retries.Load() > 0
+}
+
+// recordAttempt counts an attempt that is about to run
+func (o *Outcome) recordAttempt(att *Attempt) {
+	if o == nil {
+		return
+	}
+	o.attempts.Add(1)
+	if att.Number == 1 {
+		o.cycles.Add(1)
+		return
+	}
+	o.retries.Add(1)
+	o.delay.Add(int64(att.Delay))
+}
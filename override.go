@@ -0,0 +1,36 @@
+package recur
+
+import "context"
+
+// policyOverrideKey is the context key for per-request policy overrides
+type policyOverrideKey struct{}
+
+// ContextWithPolicyOverride returns a copy of ctx under which iterators and
+// retriers apply policy on top of their own configuration, so a single
+// request, such as one on a latency-sensitive path, can cut attempts or
+// delays without a separate retrier. Overrides apply after policy
+// selectors and accumulate when nested, the innermost applying last.
+//
+// The override reaches every retry cycle run under ctx, including ones in
+// functions the operation calls with its attempt context.
+//
+// Example:
+//
+//	ctx = recur.ContextWithPolicyOverride(ctx, recur.CombinePolicies(
+//	    recur.MaxAttempts(1),
+//	    recur.Timeout(200*time.Millisecond),
+//	))
+//	user, err := getUser(ctx, id)
+func ContextWithPolicyOverride(ctx context.Context, policy Policy) context.Context {
+	if parent, ok := PolicyOverrideFromContext(ctx); ok {
+		policy = CombinePolicies(parent, policy)
+	}
+	return context.WithValue(ctx, policyOverrideKey{}, policy)
+}
+
+// PolicyOverrideFromContext returns the policy override carried by ctx, if
+// any
+func PolicyOverrideFromContext(ctx context.Context) (Policy, bool) {
+	policy, ok := ctx.Value(policyOverrideKey{}).(Policy)
+	return policy, ok
+}
@@ -0,0 +1,106 @@
+package recur
+
+import (
+	"context"
+	"iter"
+)
+
+// PageFunc fetches the page identified by pageToken, which is empty for the
+// first page, returning its items and the next page's token, or "" after
+// the last page
+type PageFunc[T any] func(ctx context.Context, pageToken string) (items []T, next string, err error)
+
+// Pager streams the items of a paginated API, retrying each page fetch
+// independently with the configured policy
+type Pager[T any] struct {
+	config *IteratorBuilder
+	fetch  PageFunc[T]
+	token  string
+	done   bool
+	err    error
+}
+
+// Pages creates a pager over fetch with the default iterator configuration
+// followed by any policies
+//
+// Example:
+//
+//	pager := recur.Pages(func(ctx context.Context, token string) ([]*User, string, error) {
+//	    resp, err := client.ListUsers(ctx, &ListUsersRequest{PageToken: token})
+//	    if err != nil {
+//	        return nil, "", err
+//	    }
+//	    return resp.Users, resp.NextPageToken, nil
+//	}, recur.MaxAttempts(5))
+//
+//	for user := range pager.All(ctx) {
+//	    sync(user)
+//	}
+//	if err := pager.Err(); err != nil {
+//	    saveCheckpoint(pager.Token())
+//	}
+func Pages[T any](fetch PageFunc[T], policies ...Policy) *Pager[T] {
+	return &Pager[T]{
+		config: Iter().WithPolicy(CombinePolicies(policies...)),
+		fetch:  fetch,
+	}
+}
+
+// StartAt resumes paging at token, typically a Token saved from an earlier
+// run that failed or stopped early
+func (p *Pager[T]) StartAt(token string) *Pager[T] {
+	p.token = token
+	p.done = false
+	p.err = nil
+	return p
+}
+
+// All returns the items of every page in order, fetching each page when
+// the previous one has been consumed. Iteration stops early if a page
+// can't be fetched per the policy; check Err afterwards. Calling All again
+// continues where the previous iteration stopped.
+func (p *Pager[T]) All(ctx context.Context) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		p.err = nil
+		for !p.done {
+			items, next, err := p.fetchPage(ctx)
+			if err != nil {
+				p.err = err
+				return
+			}
+			for _, item := range items {
+				if !yield(item) {
+					return
+				}
+			}
+			p.token = next
+			p.done = next == ""
+		}
+	}
+}
+
+// Err returns the error that stopped the last iteration, or nil; each
+// call to All clears it
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// Token returns the token of the page to resume from: the page that failed
+// or was being consumed when iteration stopped, or "" once all pages were
+// consumed. Resuming from a partly consumed page yields its items again.
+func (p *Pager[T]) Token() string {
+	return p.token
+}
+
+// fetchPage fetches the current page with retries
+func (p *Pager[T]) fetchPage(ctx context.Context) ([]T, string, error) {
+	var items []T
+	var next string
+	var final error
+	for attempt := range p.config.seq(ctx, &final) {
+		var err error
+		items, next, err = p.fetch(attempt.Context(), p.token)
+		attempt.Result(err)
+	}
+	return items, next, final
+}
@@ -0,0 +1,94 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// pagedAPI serves items 0-9 in pages of 3, failing once per page
+type pagedAPI struct {
+	failed map[string]bool
+	calls  int
+	down   bool
+}
+
+func (a *pagedAPI) fetch(_ context.Context, token string) ([]int, string, error) {
+	a.calls++
+	if a.down {
+		return nil, "", ErrTemporary
+	}
+	if !a.failed[token] {
+		a.failed[token] = true
+		return nil, "", ErrTemporary
+	}
+	start := 0
+	if token != "" {
+		start, _ = strconv.Atoi(token)
+	}
+	end := min(start+3, 10)
+	var items []int
+	for i := start; i < end; i++ {
+		items = append(items, i)
+	}
+	next := ""
+	if end < 10 {
+		next = strconv.Itoa(end)
+	}
+	return items, next, nil
+}
+
+func TestPages(t *testing.T) {
+	api := &pagedAPI{failed: map[string]bool{}}
+	pager := Pages(api.fetch, WithBackoff(NoDelay()))
+
+	got := slices.Collect(pager.All(context.Background()))
+	if want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if err := pager.Err(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if api.calls != 8 {
+		t.Errorf("Expected each of 4 pages to be retried once, got %d calls", api.calls)
+	}
+}
+
+func TestPages_Resume(t *testing.T) {
+	api := &pagedAPI{failed: map[string]bool{}}
+	pager := Pages(api.fetch, WithBackoff(NoDelay()))
+
+	var got []int
+	for item := range pager.All(context.Background()) {
+		got = append(got, item)
+		if item == 4 {
+			api.down = true
+			break
+		}
+	}
+	if pager.Token() != "3" {
+		t.Errorf("Expected to resume from the partly consumed page, got token %q", pager.Token())
+	}
+
+	for item := range pager.All(context.Background()) {
+		got = append(got, item)
+	}
+	var maxErr *MaxAttemptsExceededError
+	if !errors.As(pager.Err(), &maxErr) || pager.Token() != "3" {
+		t.Fatalf("Expected exhausted page at token 3, got %v at %q", pager.Err(), pager.Token())
+	}
+
+	api.down = false
+	resumed := Pages(api.fetch, WithBackoff(NoDelay())).StartAt(pager.Token())
+	got = append(got, slices.Collect(resumed.All(context.Background()))...)
+	if want := []int{0, 1, 2, 3, 4, 3, 4, 5, 6, 7, 8, 9}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	for range pager.All(context.Background()) {
+	}
+	if pager.Err() != nil || pager.Token() != "" {
+		t.Errorf("Expected a retried iteration to clear the error, got %v at %q", pager.Err(), pager.Token())
+	}
+}
@@ -0,0 +1,118 @@
+package recur
+
+import (
+	"context"
+	"sync"
+)
+
+// Gate pauses and resumes the iterators it is attached to, so operators can
+// halt calls to a dependency under maintenance without tearing down the
+// component. The zero value is an open gate, safe for concurrent use.
+type Gate struct {
+	mu     sync.Mutex
+	paused bool
+	resume chan struct{} // Closed by Resume
+}
+
+// Pause makes attempts wait at the gate until Resume. Attempts already
+// running are not interrupted.
+func (g *Gate) Pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.paused {
+		g.paused = true
+		g.resume = make(chan struct{})
+	}
+}
+
+// Resume releases waiting attempts
+func (g *Gate) Resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused {
+		g.paused = false
+		close(g.resume)
+	}
+}
+
+// Paused reports whether the gate is paused
+func (g *Gate) Paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// Wait blocks while the gate is paused, returning ctx's error if ctx is
+// done first
+func (g *Gate) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	paused, resume := g.paused, g.resume
+	g.mu.Unlock()
+
+	if !paused {
+		return nil
+	}
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithGate makes every attempt, including the first, wait while gate is
+// paused. Time spent paused doesn't count as backoff, but does count
+// against WithTimeout.
+//
+// Example:
+//
+//	var maintenance recur.Gate
+//	worker := recur.Iter().WithGate(&maintenance)
+//
+//	// From an admin endpoint
+//	maintenance.Pause()
+func (b *IteratorBuilder) WithGate(gate *Gate) *IteratorBuilder {
+	b.gate = gate
+	return b
+}
+
+// PausedBy creates a policy that attaches gate to the iterator
+func PausedBy(gate *Gate) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithGate(gate)
+	}
+}
+
+// waitForGate blocks while the configured gate is paused
+func (s *iteratorState) waitForGate() bool {
+	if s.builder.gate == nil {
+		return true
+	}
+	if err := s.builder.gate.Wait(s.ctx); err != nil {
+		s.recordFailureMetrics()
+		return false
+	}
+	return true
+}
+
+// Pause halts reconnect attempts until Resume. Calls needing a new
+// connection wait, or fail when their context is done; a healthy current
+// connection keeps being handed out.
+func (r *ReconnectingConn[C]) Pause() {
+	r.gate.Pause()
+}
+
+// Resume lets paused reconnect attempts proceed
+func (r *ReconnectingConn[C]) Resume() {
+	r.gate.Resume()
+}
+
+// Pause halts stream re-establishment until Resume
+func (s *StreamRetrier[M]) Pause() {
+	s.gate.Pause()
+}
+
+// Resume lets stream re-establishment proceed
+func (s *StreamRetrier[M]) Resume() {
+	s.gate.Resume()
+}
@@ -0,0 +1,103 @@
+package recur
+
+import "time"
+
+// Policy is a reusable piece of retry configuration. Policies can be applied
+// to iterators and to function retriers alike, and combined with
+// CombinePolicies. Any IteratorBuilder option can be used to write a custom
+// policy:
+//
+//	redacted := recur.Policy(func(b *recur.IteratorBuilder) {
+//	    b.WithErrorRedactor(scrub)
+//	})
+type Policy func(b *IteratorBuilder)
+
+// WithPolicy applies a policy to the iterator
+func (b *IteratorBuilder) WithPolicy(policy Policy) *IteratorBuilder {
+	policy(b)
+	return b
+}
+
+// CombinePolicies creates a policy applying each of policies in order
+func CombinePolicies(policies ...Policy) Policy {
+	return func(b *IteratorBuilder) {
+		for _, policy := range policies {
+			policy(b)
+		}
+	}
+}
+
+// MaxAttempts creates a policy that sets the maximum number of attempts.
+// The operation will be attempted at most n times (including the initial attempt).
+func MaxAttempts(n int) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithMaxAttempts(n)
+	}
+}
+
+// MaxRetries creates a policy that sets the maximum number of retries after
+// the first attempt, i.e. n+1 attempts in total
+func MaxRetries(n int) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithMaxRetries(n)
+	}
+}
+
+// WithBackoff creates a policy that sets the backoff strategy
+func WithBackoff(backoff Backoff) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithBackoff(backoff)
+	}
+}
+
+// Named creates a policy that names the retried operation
+func Named(name string) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithName(name)
+	}
+}
+
+// MinDelay creates a policy that clamps every delay between attempts to at
+// least d, regardless of the backoff strategy
+func MinDelay(d time.Duration) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithMinDelay(d)
+	}
+}
+
+// MaxDelay creates a policy that caps every backoff delay at d, regardless
+// of the backoff strategy
+func MaxDelay(d time.Duration) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithMaxDelay(d)
+	}
+}
+
+// DelayIncludesAttempt creates a policy that measures backoff delays
+// between attempt starts, see IteratorBuilder.WithDelayIncludesAttempt
+func DelayIncludesAttempt(enabled bool) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithDelayIncludesAttempt(enabled)
+	}
+}
+
+// Timeout creates a policy that sets an overall timeout
+func Timeout(d time.Duration) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithTimeout(d)
+	}
+}
+
+// RetryIf creates a policy that sets the error matcher
+func RetryIf(matcher ErrorMatcher) Policy {
+	return func(b *IteratorBuilder) {
+		b.RetryIf(matcher)
+	}
+}
+
+// OnRetry creates a policy that registers a retry hook
+func OnRetry(hook Hook) Policy {
+	return func(b *IteratorBuilder) {
+		b.OnRetry(hook)
+	}
+}
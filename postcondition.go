@@ -0,0 +1,102 @@
+package recur
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ErrZeroValue is the attempt error for a zero value returned with a nil
+// error when WithRetryOnZeroValue is enabled
+var ErrZeroValue error = &codedError{code: CodeZeroValue, msg: "recur: zero value result"}
+
+// PostConditionError reports a value that failed a post-condition
+type PostConditionError struct {
+	Value any   // Value the attempt returned
+	Err   error // Error returned by the check
+}
+
+func (e *PostConditionError) Error() string {
+	return fmt.Sprintf("post-condition failed: %v", e.Err)
+}
+
+func (e *PostConditionError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PostConditionError) Code() string {
+	return CodePostCondition
+}
+
+// PostCondition validates the values returned by a FuncR, Func1R or Func2R
+// retrier, passed to the constructor so its type must match the wrapped
+// function's. A value for which the check returns an error fails the
+// attempt with a PostConditionError, so schema or invariant checks drive
+// retries without living in the operation.
+//
+// Violations are retried regardless of RetryIf; return Fatal(err) from
+// the check to stop instead, or Transient and RateLimited errors to
+// control retries as usual.
+//
+// Example:
+//
+//	fetch := recur.FuncR(loadConfig, func(c *Config) error {
+//	    if c.Version == 0 {
+//	        return errors.New("config not yet published")
+//	    }
+//	    return nil
+//	}).Build()
+type PostCondition[T any] func(T) error
+
+// WithRetryOnZeroValue treats a zero value returned with a nil error by
+// FuncR, Func1R and Func2R retriers as a failed attempt with ErrZeroValue,
+// for APIs that signal "not found yet" with empty results during eventual
+// consistency windows. Empty slices, maps and strings count as zero.
+// Like post-condition violations, such attempts are retried regardless of
+// RetryIf, and the built function returns the zero value and the final
+// error once attempts run out.
+func (b *IteratorBuilder) WithRetryOnZeroValue(enabled bool) *IteratorBuilder {
+	b.retryOnZero = enabled
+	return b
+}
+
+// RetryOnZeroValue returns a policy treating zero values as failures, see
+// IteratorBuilder.WithRetryOnZeroValue
+func RetryOnZeroValue(enabled bool) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithRetryOnZeroValue(enabled)
+	}
+}
+
+// isEmpty reports whether v is nil, a zero value, or an empty slice, map or
+// string
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// checkValue checks v against WithRetryOnZeroValue and conditions in
+// order, and returns the first violation
+func checkValue[T any](config *IteratorBuilder, conditions []PostCondition[T], v T) error {
+	if config.retryOnZero && isEmpty(v) {
+		return Transient(ErrZeroValue)
+	}
+	for _, check := range conditions {
+		err := check(v)
+		if err == nil {
+			continue
+		}
+		violation := &PostConditionError{Value: v, Err: err}
+		if _, ok := Classify(err); ok {
+			return violation
+		}
+		return Transient(violation)
+	}
+	return nil
+}
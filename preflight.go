@@ -0,0 +1,69 @@
+package recur
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultPreflightBackoff spaces out preflight checks that keep failing
+var DefaultPreflightBackoff Backoff = Exponential(100 * time.Millisecond).(*ExponentialBackoff).WithMaxDelay(5 * time.Second)
+
+// WithPreflight runs check before every attempt, after its backoff delay.
+// While check fails, for example because the network is down or
+// credentials are missing, the attempt waits and checks again on the
+// preflight backoff instead of running, so known-bad conditions don't use
+// up attempts. Waiting ends only when check passes or the cycle's context
+// is done, so bound the cycle with WithTimeout or a context deadline.
+//
+// Example:
+//
+//	recur.Iter().WithPreflight(func(ctx context.Context) error {
+//	    if !netmon.Online() {
+//	        return errors.New("offline")
+//	    }
+//	    return nil
+//	})
+func (b *IteratorBuilder) WithPreflight(check func(ctx context.Context) error) *IteratorBuilder {
+	b.preflight = check
+	return b
+}
+
+// WithPreflightBackoff sets the wait between failed preflight checks,
+// DefaultPreflightBackoff by default
+func (b *IteratorBuilder) WithPreflightBackoff(backoff Backoff) *IteratorBuilder {
+	b.preflightBackoff = backoff
+	return b
+}
+
+// Preflight returns a policy checking conditions before every attempt, see
+// IteratorBuilder.WithPreflight
+func Preflight(check func(ctx context.Context) error) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithPreflight(check)
+	}
+}
+
+// passPreflight waits until the preflight check passes, returning false if
+// the cycle's context is done first
+func (s *iteratorState) passPreflight() bool {
+	check := s.builder.preflight
+	if check == nil {
+		return true
+	}
+	backoff := s.builder.preflightBackoff
+	if backoff == nil {
+		backoff = DefaultPreflightBackoff
+	}
+	for failures := 1; ; failures++ {
+		if s.isContextDone() {
+			s.recordFailureMetrics()
+			return false
+		}
+		if check(s.ctx) == nil {
+			return true
+		}
+		if !s.sleep(backoff.Next(failures)) {
+			return false
+		}
+	}
+}
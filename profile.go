@@ -0,0 +1,41 @@
+package recur
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+)
+
+// pprof label keys set by WithProfilerLabels
+const (
+	LabelOperation = "recur_operation"
+	LabelAttempt   = "recur_attempt"
+)
+
+// WithProfilerLabels runs each attempt's loop body under pprof labels
+// naming the operation (see WithName) and the attempt number, so CPU and
+// goroutine profiles attribute time to specific retried operations.
+// Goroutines started during the attempt inherit the labels, and the
+// attempt's context carries them for pprof.Do calls of its own.
+func (b *IteratorBuilder) WithProfilerLabels(enabled bool) *IteratorBuilder {
+	b.profile = enabled
+	return b
+}
+
+// runLabeled calls body with att, under pprof labels if they are enabled
+func (s *iteratorState) runLabeled(att *Attempt, body func(*Attempt) bool) bool {
+	if !s.builder.profile {
+		return body(att)
+	}
+	labels := []string{LabelAttempt, strconv.Itoa(att.Number)}
+	if name := s.builder.operationName(); name != "" {
+		labels = append(labels, LabelOperation, name)
+	}
+
+	var more bool
+	pprof.Do(att.ctx, pprof.Labels(labels...), func(ctx context.Context) {
+		att.ctx = ctx
+		more = body(att)
+	})
+	return more
+}
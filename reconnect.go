@@ -0,0 +1,278 @@
+package recur
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ErrConnClosed is returned by a ReconnectingConn after Close
+var ErrConnClosed error = &codedError{code: CodeConnClosed, msg: "recur: connection closed"}
+
+// Conn is a long-lived connection managed by ReconnectingConn, such as a
+// WebSocket or a message broker channel
+type Conn interface {
+	Close() error
+}
+
+// ConnState is the lifecycle state of a ReconnectingConn
+type ConnState int
+
+const (
+	StateDisconnected ConnState = iota
+	StateConnecting
+	StateConnected
+	StateClosed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// ReconnectingConn keeps a connection of type C available, re-dialing it
+// with backoff and jitter once it breaks and replaying a handshake, such as
+// authentication or subscriptions, on every new connection. It is safe for
+// concurrent use.
+type ReconnectingConn[C Conn] struct {
+	config    *IteratorBuilder
+	dial      func(ctx context.Context) (C, error)
+	handshake func(ctx context.Context, conn C) error
+	listeners []func(state ConnState, err error)
+	gate      Gate
+
+	mu         sync.Mutex
+	conn       C
+	gen        uint64 // Incremented per connection, so stale failures are ignored
+	state      ConnState
+	connecting chan struct{}           // Closed when the reconnect in progress ends
+	cancel     context.CancelCauseFunc // Cancels the reconnect in progress
+}
+
+// NewReconnectingConn creates a manager that connects lazily with dial.
+// Reconnects use jittered exponential backoff from 100ms up to 30s.
+//
+// Example:
+//
+//	rc := recur.NewReconnectingConn(func(ctx context.Context) (*websocket.Conn, error) {
+//	    conn, _, err := websocket.Dial(ctx, url, nil)
+//	    return conn, err
+//	}).WithHandshake(func(ctx context.Context, c *websocket.Conn) error {
+//	    return c.Write(ctx, websocket.MessageText, subscribeMsg)
+//	}).WithPolicy(recur.MaxAttempts(10))
+//
+//	err := rc.Do(ctx, func(c *websocket.Conn) error {
+//	    return c.Write(ctx, websocket.MessageText, msg)
+//	})
+func NewReconnectingConn[C Conn](dial func(ctx context.Context) (C, error)) *ReconnectingConn[C] {
+	r := &ReconnectingConn[C]{
+		config: Iter().WithBackoff(Jitter(Exponential(100*time.Millisecond).(*ExponentialBackoff).WithMaxDelay(30*time.Second), 0.5)),
+		dial:   dial,
+	}
+	r.config.WithGate(&r.gate)
+	return r
+}
+
+// WithPolicy applies a policy to reconnection attempts
+func (r *ReconnectingConn[C]) WithPolicy(policy Policy) *ReconnectingConn[C] {
+	r.config.WithPolicy(policy)
+	return r
+}
+
+// WithHandshake runs fn on every new connection before it is handed out.
+// A failed handshake closes the connection and counts as a failed attempt.
+func (r *ReconnectingConn[C]) WithHandshake(fn func(ctx context.Context, conn C) error) *ReconnectingConn[C] {
+	r.handshake = fn
+	return r
+}
+
+// OnStateChange registers fn to be called on every state transition.
+// err is the failure that caused a transition to StateDisconnected.
+// fn runs synchronously and must not call back into the connection.
+func (r *ReconnectingConn[C]) OnStateChange(fn func(state ConnState, err error)) *ReconnectingConn[C] {
+	r.listeners = append(r.listeners, fn)
+	return r
+}
+
+// State returns the current connection state
+func (r *ReconnectingConn[C]) State() ConnState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state
+}
+
+// Get returns the current connection, connecting first if there is none.
+// Concurrent callers wait for a single reconnect.
+func (r *ReconnectingConn[C]) Get(ctx context.Context) (C, error) {
+	conn, _, err := r.get(ctx)
+	return conn, err
+}
+
+// Do calls fn with the current connection. If fn fails, the connection is
+// treated as broken and re-established on next use; fn is not retried.
+func (r *ReconnectingConn[C]) Do(ctx context.Context, fn func(conn C) error) error {
+	conn, gen, err := r.get(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(conn); err != nil {
+		r.reset(gen, err)
+		return err
+	}
+	return nil
+}
+
+// Reset closes the current connection after an externally detected
+// failure, such as a read loop ending, so the next use reconnects
+func (r *ReconnectingConn[C]) Reset(err error) {
+	r.mu.Lock()
+	gen := r.gen
+	r.mu.Unlock()
+	r.reset(gen, err)
+}
+
+// Close closes the current connection and stops further reconnects,
+// canceling one in progress
+func (r *ReconnectingConn[C]) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state == StateClosed {
+		return nil
+	}
+	var err error
+	if r.state == StateConnected {
+		err = r.conn.Close()
+	}
+	if r.cancel != nil {
+		r.cancel(ErrConnClosed)
+	}
+	r.setState(StateClosed, nil)
+	return err
+}
+
+func (r *ReconnectingConn[C]) get(ctx context.Context) (C, uint64, error) {
+	var zero C
+	for {
+		r.mu.Lock()
+		switch {
+		case r.state == StateConnected:
+			conn, gen := r.conn, r.gen
+			r.mu.Unlock()
+			return conn, gen, nil
+		case r.state == StateClosed:
+			r.mu.Unlock()
+			return zero, 0, ErrConnClosed
+		case r.connecting != nil:
+			// Wait for the reconnect in progress, then look again
+			done := r.connecting
+			r.mu.Unlock()
+			select {
+			case <-done:
+			case <-ctx.Done():
+				return zero, 0, ctx.Err()
+			}
+			continue
+		case r.gate.Paused():
+			r.mu.Unlock()
+			if err := r.gate.Wait(ctx); err != nil {
+				return zero, 0, err
+			}
+			continue
+		}
+		return r.reconnect(ctx)
+	}
+}
+
+// reconnect dials a new connection, called with r.mu held. The lock is
+// released while dialing, so State, Close and waiting callers don't block.
+// Under WithSuccessThreshold only the last connection is kept.
+func (r *ReconnectingConn[C]) reconnect(ctx context.Context) (C, uint64, error) {
+	var zero C
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancelCause(ctx)
+	r.connecting, r.cancel = done, cancel
+	r.setState(StateConnecting, nil)
+	r.mu.Unlock()
+
+	var conn C
+	var connected bool
+	var final error
+	for attempt := range r.config.seq(ctx, &final) {
+		c, err := r.connect(attempt.Context())
+		if err == nil {
+			if connected {
+				_ = conn.Close() // Superseded under WithSuccessThreshold
+			}
+			conn, connected = c, true
+		}
+		attempt.Result(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	defer close(done)
+	r.connecting, r.cancel = nil, nil
+	cancel(nil)
+
+	if connected && (r.state == StateClosed || final != nil) {
+		_ = conn.Close()
+	}
+	if r.state == StateClosed {
+		return zero, 0, ErrConnClosed
+	}
+	if final != nil {
+		r.setState(StateDisconnected, final)
+		return zero, 0, final
+	}
+	r.conn = conn
+	r.gen++
+	r.setState(StateConnected, nil)
+	return conn, r.gen, nil
+}
+
+func (r *ReconnectingConn[C]) connect(ctx context.Context) (C, error) {
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return conn, err
+	}
+	if r.handshake != nil {
+		if err := r.handshake(ctx, conn); err != nil {
+			_ = conn.Close()
+			var zero C
+			return zero, err
+		}
+	}
+	return conn, nil
+}
+
+// reset drops connection gen if it is still current
+func (r *ReconnectingConn[C]) reset(gen uint64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.state != StateConnected || r.gen != gen {
+		return
+	}
+	_ = r.conn.Close()
+	var zero C
+	r.conn = zero
+	r.setState(StateDisconnected, err)
+}
+
+func (r *ReconnectingConn[C]) setState(state ConnState, err error) {
+	r.state = state
+	for _, fn := range r.listeners {
+		fn(state, err)
+	}
+}
@@ -0,0 +1,245 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeConn struct {
+	id     int
+	closed bool
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestReconnectingConn_ReconnectsAndReplaysHandshake(t *testing.T) {
+	dials, handshakes := 0, 0
+	var states []ConnState
+
+	rc := NewReconnectingConn(func(ctx context.Context) (*fakeConn, error) {
+		dials++
+		if dials == 2 {
+			return nil, ErrTemporary
+		}
+		return &fakeConn{id: dials}, nil
+	}).WithHandshake(func(ctx context.Context, c *fakeConn) error {
+		handshakes++
+		return nil
+	}).OnStateChange(func(state ConnState, err error) {
+		states = append(states, state)
+	}).WithPolicy(WithBackoff(NoDelay()))
+
+	ctx := context.Background()
+	var first *fakeConn
+	if err := rc.Do(ctx, func(c *fakeConn) error {
+		first = c
+		return ErrTemporary
+	}); !errors.Is(err, ErrTemporary) {
+		t.Fatalf("Expected fn error, got %v", err)
+	}
+	if !first.closed {
+		t.Error("Expected broken connection to be closed")
+	}
+
+	conn, err := rc.Get(ctx)
+	if err != nil {
+		t.Fatalf("Expected reconnect, got %v", err)
+	}
+	if conn.id != 3 || handshakes != 2 {
+		t.Errorf("Expected third dial with 2 handshakes, got dial %d and %d handshakes", conn.id, handshakes)
+	}
+
+	want := []ConnState{StateConnecting, StateConnected, StateDisconnected, StateConnecting, StateConnected}
+	if !slices.Equal(states, want) {
+		t.Errorf("Expected states %v, got %v", want, states)
+	}
+}
+
+func TestReconnectingConn_Close(t *testing.T) {
+	rc := NewReconnectingConn(func(ctx context.Context) (*fakeConn, error) {
+		return &fakeConn{}, nil
+	})
+
+	conn, _ := rc.Get(context.Background())
+	if err := rc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !conn.closed || rc.State() != StateClosed {
+		t.Error("Expected connection to be closed")
+	}
+	if _, err := rc.Get(context.Background()); !errors.Is(err, ErrConnClosed) {
+		t.Errorf("Expected ErrConnClosed, got %v", err)
+	}
+}
+
+func TestReconnectingConn_CloseCancelsReconnect(t *testing.T) {
+	dialing := make(chan struct{})
+	rc := NewReconnectingConn(func(ctx context.Context) (*fakeConn, error) {
+		close(dialing)
+		<-ctx.Done()
+		return nil, context.Cause(ctx)
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := rc.Get(context.Background())
+		errs <- err
+	}()
+	<-dialing
+	if state := rc.State(); state != StateConnecting {
+		t.Errorf("Expected StateConnecting while dialing, got %v", state)
+	}
+	if err := rc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrConnClosed) {
+			t.Errorf("Expected ErrConnClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Close to cancel the reconnect")
+	}
+	if state := rc.State(); state != StateClosed {
+		t.Errorf("Expected StateClosed, got %v", state)
+	}
+}
+
+func TestReconnectingConn_SingleReconnect(t *testing.T) {
+	var dials atomic.Int32
+	release := make(chan struct{})
+	rc := NewReconnectingConn(func(ctx context.Context) (*fakeConn, error) {
+		dials.Add(1)
+		<-release
+		return &fakeConn{}, nil
+	})
+
+	var wg sync.WaitGroup
+	conns := make([]*fakeConn, 5)
+	for i := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conns[i], _ = rc.Get(context.Background())
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if dials.Load() != 1 {
+		t.Errorf("Expected a single dial, got %d", dials.Load())
+	}
+	for _, c := range conns {
+		if c == nil || c != conns[0] {
+			t.Fatal("Expected every caller to get the same connection")
+		}
+	}
+}
+
+func TestReconnectingConn_HandshakeFailure(t *testing.T) {
+	var conns []*fakeConn
+	rc := NewReconnectingConn(func(ctx context.Context) (*fakeConn, error) {
+		c := &fakeConn{}
+		conns = append(conns, c)
+		return c, nil
+	}).WithHandshake(func(ctx context.Context, c *fakeConn) error {
+		return ErrFatal
+	}).WithPolicy(CombinePolicies(MaxAttempts(2), WithBackoff(NoDelay())))
+
+	_, err := rc.Get(context.Background())
+	if !IsMaxAttemptsExceeded(err) || rc.State() != StateDisconnected {
+		t.Errorf("Expected exhausted reconnect, got %v in state %v", err, rc.State())
+	}
+	for _, c := range conns {
+		if !c.closed {
+			t.Error("Expected connection with failed handshake to be closed")
+		}
+	}
+}
+
+func TestReconnectingConn_SuccessThreshold(t *testing.T) {
+	var dialed []*fakeConn
+	rc := NewReconnectingConn(func(ctx context.Context) (*fakeConn, error) {
+		c := &fakeConn{id: len(dialed) + 1}
+		dialed = append(dialed, c)
+		return c, nil
+	}).WithPolicy(CombinePolicies(WithBackoff(NoDelay()), SuccessThreshold(3)))
+
+	conn, err := rc.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Expected connection, got %v", err)
+	}
+	if conn.id != 3 || conn.closed {
+		t.Errorf("Expected the last connection kept open, got %+v", conn)
+	}
+	for _, c := range dialed[:len(dialed)-1] {
+		if !c.closed {
+			t.Errorf("Expected superseded connection %d to be closed", c.id)
+		}
+	}
+}
+
+func TestReconnectingConn_PauseResume(t *testing.T) {
+	dials := 0
+	rc := NewReconnectingConn(func(ctx context.Context) (*fakeConn, error) {
+		dials++
+		return &fakeConn{id: dials}, nil
+	})
+
+	rc.Pause()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := rc.Get(ctx); !errors.Is(err, context.DeadlineExceeded) || dials != 0 {
+		t.Fatalf("Expected paused connect to wait, got %v after %d dials", err, dials)
+	}
+	if rc.State() != StateDisconnected {
+		t.Errorf("Expected State to answer while paused, got %v", rc.State())
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := rc.Get(context.Background())
+		done <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	rc.Resume()
+
+	if err := <-done; err != nil || dials != 1 {
+		t.Errorf("Expected connect after resume, got %v after %d dials", err, dials)
+	}
+}
+
+func TestGate(t *testing.T) {
+	var g Gate
+	if g.Paused() || g.Wait(context.Background()) != nil {
+		t.Fatal("Expected zero gate to be open")
+	}
+
+	g.Pause()
+	counter := 0
+	builder := Iter().WithGate(&g).WithTimeout(10 * time.Millisecond)
+	for range builder.Seq() {
+		counter++
+	}
+	if counter != 0 {
+		t.Errorf("Expected no attempts while paused, got %d", counter)
+	}
+
+	g.Resume()
+	for attempt := range Iter().WithGate(&g).Seq() {
+		counter++
+		attempt.Result(nil)
+	}
+	if counter != 1 {
+		t.Errorf("Expected attempt after resume, got %d", counter)
+	}
+}
@@ -0,0 +1,103 @@
+// Package recurtest provides helpers for testing code that uses recur
+package recurtest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// DefaultIterations is the number of calls a BenchmarkRunner times per function
+const DefaultIterations = 100_000
+
+// BenchmarkResult is the measured cost of one call
+type BenchmarkResult struct {
+	NsPerOp     float64
+	AllocsPerOp float64
+}
+
+// Overhead is the cost a retry wrapper adds on top of the direct call
+type Overhead struct {
+	Baseline    BenchmarkResult
+	Wrapped     BenchmarkResult
+	NsPerOp     float64 // Wrapped minus baseline, may be slightly negative from noise
+	AllocsPerOp float64
+}
+
+// Check returns an error if the overhead exceeds maxNs nanoseconds or
+// maxAllocs allocations per call
+func (o Overhead) Check(maxNs, maxAllocs float64) error {
+	if o.NsPerOp > maxNs {
+		return fmt.Errorf("recurtest: wrapper adds %.1fns per call, limit %.1fns", o.NsPerOp, maxNs)
+	}
+	if o.AllocsPerOp > maxAllocs {
+		return fmt.Errorf("recurtest: wrapper adds %.1f allocs per call, limit %.1f", o.AllocsPerOp, maxAllocs)
+	}
+	return nil
+}
+
+func (o Overhead) String() string {
+	return fmt.Sprintf("+%.1fns/op +%.1f allocs/op (baseline %.1fns/op, wrapped %.1fns/op)",
+		o.NsPerOp, o.AllocsPerOp, o.Baseline.NsPerOp, o.Wrapped.NsPerOp)
+}
+
+// BenchmarkRunner compares a function called directly with the same call
+// made through a retry wrapper, to guard the success path against
+// performance regressions from plain tests.
+//
+// Example:
+//
+//	get := recur.Func0(cache.Get).Build()
+//	r := recurtest.BenchmarkRunner{
+//	    Baseline: func() { _ = cache.Get() },
+//	    Wrapped:  func() { _ = get() },
+//	}
+//	recurtest.AssertOverhead(t, r.Run(), 2000, 8)
+type BenchmarkRunner struct {
+	Baseline   func()
+	Wrapped    func()
+	Iterations int // Calls timed per function, DefaultIterations if zero
+}
+
+// Run measures both functions and returns the wrapper's overhead
+func (r BenchmarkRunner) Run() Overhead {
+	n := r.Iterations
+	if n <= 0 {
+		n = DefaultIterations
+	}
+	baseline := measure(r.Baseline, n)
+	wrapped := measure(r.Wrapped, n)
+	return Overhead{
+		Baseline:    baseline,
+		Wrapped:     wrapped,
+		NsPerOp:     wrapped.NsPerOp - baseline.NsPerOp,
+		AllocsPerOp: wrapped.AllocsPerOp - baseline.AllocsPerOp,
+	}
+}
+
+// AssertOverhead fails t if o exceeds maxNs nanoseconds or maxAllocs
+// allocations per call
+func AssertOverhead(t testing.TB, o Overhead, maxNs, maxAllocs float64) {
+	t.Helper()
+	if err := o.Check(maxNs, maxAllocs); err != nil {
+		t.Errorf("%v (%v)", err, o)
+	}
+}
+
+func measure(fn func(), n int) BenchmarkResult {
+	// Warm up caches and lazily initialized state
+	for range min(n, 1000) {
+		fn()
+	}
+
+	start := time.Now()
+	for range n {
+		fn()
+	}
+	elapsed := time.Since(start)
+
+	return BenchmarkResult{
+		NsPerOp:     float64(elapsed.Nanoseconds()) / float64(n),
+		AllocsPerOp: testing.AllocsPerRun(min(n, 1000), fn),
+	}
+}
@@ -0,0 +1,28 @@
+package recurtest
+
+import (
+	"testing"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+func TestBenchmarkRunner(t *testing.T) {
+	op := func() error { return nil }
+	wrapped := recur.Func0(op).Build()
+
+	o := BenchmarkRunner{
+		Baseline:   func() { _ = op() },
+		Wrapped:    func() { _ = wrapped() },
+		Iterations: 10_000,
+	}.Run()
+
+	if o.Wrapped.NsPerOp <= 0 || o.AllocsPerOp <= 0 {
+		t.Errorf("Expected the wrapper to cost time and allocations, got %v", o)
+	}
+	if err := o.Check(o.NsPerOp+1, o.AllocsPerOp); err != nil {
+		t.Errorf("Expected overhead within limits, got %v", err)
+	}
+	if err := o.Check(o.NsPerOp+1, 0); err == nil {
+		t.Error("Expected allocation limit to be enforced")
+	}
+}
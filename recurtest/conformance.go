@@ -0,0 +1,144 @@
+package recurtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+// DefaultConformanceRetries is the number of consecutive retries a
+// BackoffContract checks when Retries is zero
+const DefaultConformanceRetries = 32
+
+// farRetries are retry numbers far beyond realistic limits, where naive
+// exponential arithmetic overflows
+var farRetries = []int{64, 1 << 10, 1 << 20, math.MaxInt32}
+
+// BackoffContract lists the guarantees a Backoff is checked against. The
+// zero value checks only what the retry engine relies on: delays are never
+// negative, even for very large retry numbers.
+type BackoffContract struct {
+	Retries   int           // Consecutive retries to check, DefaultConformanceRetries if zero
+	Monotonic bool          // Delays never decrease from one retry to the next
+	MinDelay  time.Duration // Lower bound for every delay, unchecked if zero
+	MaxDelay  time.Duration // Upper bound for every delay, unchecked if zero
+}
+
+// TestBackoff checks b against the zero BackoffContract
+//
+// Example:
+//
+//	func TestDecorrelatedBackoff(t *testing.T) {
+//	    recurtest.TestBackoff(t, NewDecorrelated(100*time.Millisecond))
+//	}
+func TestBackoff(t testing.TB, b recur.Backoff) {
+	t.Helper()
+	BackoffContract{}.Test(t, b)
+}
+
+// Test checks b against the contract, reporting every violation. Backoffs
+// implementing recur.ElapsedBackoffer are also checked through NextElapsed.
+//
+// Example:
+//
+//	recurtest.BackoffContract{Monotonic: true, MaxDelay: time.Second}.Test(t, b)
+func (c BackoffContract) Test(t testing.TB, b recur.Backoff) {
+	t.Helper()
+	retries := c.Retries
+	if retries <= 0 {
+		retries = DefaultConformanceRetries
+	}
+
+	var prev time.Duration
+	for retry := 1; retry <= retries; retry++ {
+		d := b.Next(retry)
+		c.checkBounds(t, fmt.Sprintf("Next(%d)", retry), d)
+		if c.Monotonic && retry > 1 && d < prev {
+			t.Errorf("Next(%d) = %v, decreased from %v", retry, d, prev)
+		}
+		prev = d
+	}
+	for _, retry := range farRetries {
+		c.checkBounds(t, fmt.Sprintf("Next(%d)", retry), b.Next(retry))
+	}
+
+	eb, ok := b.(recur.ElapsedBackoffer)
+	if !ok {
+		return
+	}
+	for _, elapsed := range []time.Duration{0, time.Second, time.Hour, math.MaxInt64} {
+		for _, retry := range []int{1, retries} {
+			d := eb.NextElapsed(retry, elapsed)
+			c.checkBounds(t, fmt.Sprintf("NextElapsed(%d, %v)", retry, elapsed), d)
+		}
+	}
+}
+
+func (c BackoffContract) checkBounds(t testing.TB, call string, d time.Duration) {
+	t.Helper()
+	if d < 0 {
+		t.Errorf("%s = %v, want a non-negative delay", call, d)
+	}
+	if c.MinDelay > 0 && d < c.MinDelay {
+		t.Errorf("%s = %v, below the minimum %v", call, d, c.MinDelay)
+	}
+	if c.MaxDelay > 0 && d > c.MaxDelay {
+		t.Errorf("%s = %v, above the maximum %v", call, d, c.MaxDelay)
+	}
+}
+
+// MatcherCase is an error and whether a matcher should retry it
+type MatcherCase struct {
+	Err   error
+	Retry bool
+}
+
+// TestMatcher checks m against the ErrorMatcher contract and the expected
+// cases: a nil error is never retried, every case matches as expected, and
+// wrapping a case's error with fmt.Errorf("...: %w") doesn't change the
+// decision, since operations commonly wrap the errors they return.
+//
+// Example:
+//
+//	recurtest.TestMatcher(t, isThrottled, []recurtest.MatcherCase{
+//	    {Err: &APIError{Status: 429}, Retry: true},
+//	    {Err: &APIError{Status: 400}, Retry: false},
+//	})
+func TestMatcher(t testing.TB, m recur.ErrorMatcher, cases []MatcherCase) {
+	t.Helper()
+	if matchNil(t, m) {
+		t.Error("matcher retries a nil error")
+	}
+	for _, tc := range cases {
+		if got := m(tc.Err); got != tc.Retry {
+			t.Errorf("matcher(%v) = %v, want %v", tc.Err, got, tc.Retry)
+			continue
+		}
+		if tc.Err == nil {
+			continue
+		}
+		wrapped := fmt.Errorf("wrapped: %w", tc.Err)
+		if got := m(wrapped); got != tc.Retry {
+			t.Errorf("matcher(%v) = %v, want %v as for the unwrapped error", wrapped, got, tc.Retry)
+		}
+		joined := errors.Join(errors.New("context"), tc.Err)
+		if got := m(joined); got != tc.Retry {
+			t.Errorf("matcher(%v) = %v, want %v as for the unwrapped error", joined, got, tc.Retry)
+		}
+	}
+}
+
+// matchNil calls m with a nil error, reporting a panic as a failure
+func matchNil(t testing.TB, m recur.ErrorMatcher) (retry bool) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("matcher panics on a nil error: %v", r)
+		}
+	}()
+	return m(nil)
+}
@@ -0,0 +1,80 @@
+package recurtest
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+func TestBackoff_BuiltIn(t *testing.T) {
+	backoffs := map[string]recur.Backoff{
+		"constant":    recur.Constant(100 * time.Millisecond),
+		"exponential": recur.Exponential(100 * time.Millisecond),
+		"fibonacci":   recur.Fibonacci(100 * time.Millisecond),
+		"linear":      recur.Linear(100*time.Millisecond, 50*time.Millisecond),
+		"elapsed":     recur.Elapsed(100*time.Millisecond, 5*time.Second, time.Minute),
+		"jitter":      recur.Jitter(recur.Exponential(100*time.Millisecond), 0.5),
+		"capped":      recur.CapBackoff(recur.Fibonacci(100*time.Millisecond), time.Second),
+		"none":        recur.NoDelay(),
+	}
+	for name, b := range backoffs {
+		t.Run(name, func(t *testing.T) {
+			TestBackoff(t, b)
+		})
+	}
+
+	capped := recur.Exponential(10 * time.Millisecond).(*recur.ExponentialBackoff).WithMaxDelay(time.Second)
+	BackoffContract{Monotonic: true, MaxDelay: time.Second}.Test(t, capped)
+}
+
+func TestBackoffContract_ReportsViolations(t *testing.T) {
+	rec := &recorder{TB: t}
+	BackoffContract{Monotonic: true, MaxDelay: time.Second}.Test(rec, countdown{})
+	if rec.errors == 0 {
+		t.Error("Expected a decreasing, unbounded backoff to violate the contract")
+	}
+}
+
+func TestMatcher_BuiltIn(t *testing.T) {
+	TestMatcher(t, recur.MatchAny, []MatcherCase{{Err: io.EOF, Retry: true}})
+	TestMatcher(t, recur.MatchErrors(io.ErrUnexpectedEOF), []MatcherCase{
+		{Err: io.ErrUnexpectedEOF, Retry: true},
+		{Err: io.EOF, Retry: false},
+	})
+	TestMatcher(t, recur.MatchAs[net.Error](), []MatcherCase{
+		{Err: &net.DNSError{IsTimeout: true}, Retry: true},
+		{Err: errors.New("plain"), Retry: false},
+	})
+}
+
+func TestMatcher_ReportsViolations(t *testing.T) {
+	errTarget := errors.New("target")
+	rec := &recorder{TB: t}
+	TestMatcher(rec, func(err error) bool { return err == errTarget || err == nil }, []MatcherCase{
+		{Err: errTarget, Retry: true},
+	})
+	if rec.errors != 3 {
+		t.Errorf("Expected nil, wrapped and joined violations, got %d errors", rec.errors)
+	}
+}
+
+// countdown is a backoff whose delays decrease without bound
+type countdown struct{}
+
+func (countdown) Next(retry int) time.Duration {
+	return time.Hour - time.Duration(retry)*time.Minute
+}
+
+// recorder counts reported errors instead of failing the test
+type recorder struct {
+	testing.TB
+	errors int
+}
+
+func (r *recorder) Errorf(string, ...any) { r.errors++ }
+func (r *recorder) Error(...any)          { r.errors++ }
+func (r *recorder) Helper()               {}
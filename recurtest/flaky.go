@@ -0,0 +1,77 @@
+package recurtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+// FlakyOptions scripts how a FlakyServer misbehaves before it recovers
+type FlakyOptions struct {
+	Failures   int           // Requests failed before the server recovers
+	Status     int           // Status of failed requests, 503 if zero
+	RetryAfter time.Duration // Retry hint sent with failed requests, see recur.SetRetryAfter
+	Stall      time.Duration // Delay before answering failed requests, cut short if the client gives up
+	Handler    http.Handler  // Serves requests after recovery, a 200 "ok" if nil
+}
+
+// FlakyServer is an httptest.Server that fails a scripted number of
+// requests before serving normally, for integration tests of HTTP retry
+// logic, matchers and Retry-After handling against a real network stack
+type FlakyServer struct {
+	*httptest.Server
+	requests atomic.Int64
+}
+
+// NewFlakyServer starts a server failing as opts describe. Close it when
+// done, as with httptest.NewServer.
+//
+// Example:
+//
+//	srv := recurtest.NewFlakyServer(recurtest.FlakyOptions{Failures: 2, RetryAfter: 10 * time.Millisecond})
+//	defer srv.Close()
+//	body, err := fetch(ctx, srv.URL)
+//	if err != nil || srv.Requests() != 3 {
+//	    t.Fatalf("expected success on the third request, got %v after %d", err, srv.Requests())
+//	}
+func NewFlakyServer(opts FlakyOptions) *FlakyServer {
+	if opts.Status == 0 {
+		opts.Status = http.StatusServiceUnavailable
+	}
+	if opts.Handler == nil {
+		opts.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		})
+	}
+
+	s := &FlakyServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.requests.Add(1) > int64(opts.Failures) {
+			opts.Handler.ServeHTTP(w, r)
+			return
+		}
+		if opts.Stall > 0 {
+			timer := time.NewTimer(opts.Stall)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if opts.RetryAfter > 0 {
+			recur.SetRetryAfter(w, opts.RetryAfter)
+		}
+		w.WriteHeader(opts.Status)
+	}))
+	return s
+}
+
+// Requests returns how many requests the server has received
+func (s *FlakyServer) Requests() int {
+	return int(s.requests.Load())
+}
@@ -0,0 +1,83 @@
+package recurtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+// get fetches url, classifying failed responses with their retry hints
+func get(ctx context.Context, client *http.Client, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", recur.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("status %d", resp.StatusCode)
+		if hint, ok := recur.ParseRetryHint(resp.Header); ok {
+			return "", recur.RateLimited(err, hint.After)
+		}
+		return "", err
+	}
+	body, err := io.ReadAll(resp.Body)
+	return string(body), err
+}
+
+func TestFlakyServer(t *testing.T) {
+	srv := NewFlakyServer(FlakyOptions{Failures: 2, RetryAfter: 20 * time.Millisecond})
+	defer srv.Close()
+
+	var body string
+	var err error
+	var delays []time.Duration
+	for attempt := range recur.Iter().WithBackoff(recur.NoDelay()).Seq() {
+		if attempt.Number > 1 {
+			delays = append(delays, attempt.Delay)
+		}
+		body, err = get(attempt.Context(), srv.Client(), srv.URL)
+		attempt.Result(err)
+	}
+	if err != nil || body != "ok" {
+		t.Fatalf("Expected success after recovery, got %q, %v", body, err)
+	}
+	if srv.Requests() != 3 {
+		t.Errorf("Expected 3 requests, got %d", srv.Requests())
+	}
+	if len(delays) != 2 {
+		t.Errorf("Expected 2 retries, got %v", delays)
+	}
+	for _, d := range delays {
+		if d < 20*time.Millisecond {
+			t.Errorf("Expected the Retry-After hint to be honored, got %v", d)
+		}
+	}
+}
+
+func TestFlakyServer_Stall(t *testing.T) {
+	srv := NewFlakyServer(FlakyOptions{Failures: 1, Stall: time.Minute, Status: http.StatusBadGateway})
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := get(ctx, srv.Client(), srv.URL); err == nil {
+		t.Fatal("Expected the stalled request to time out")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the client's deadline to cut the stall short, took %v", elapsed)
+	}
+
+	if body, err := get(context.Background(), srv.Client(), srv.URL); err != nil || body != "ok" {
+		t.Errorf("Expected the server to recover, got %q, %v", body, err)
+	}
+}
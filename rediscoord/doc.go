@@ -0,0 +1,16 @@
+// Package rediscoord implements recur.Coordinator with Redis locks, so only
+// one instance in a fleet runs and retries a shared operation. It speaks
+// the Redis protocol directly and adds no dependencies, but is built only
+// with the redis build tag:
+//
+//	go build -tags redis ./...
+//
+// Locks are taken with SET NX and a lease, and released only by the
+// instance holding them:
+//
+//	coord := rediscoord.New("redis:6379", time.Minute)
+//	defer coord.Close()
+//	rebuild := recur.Func0(rebuildCache).
+//	    WithCoordinator(coord, "cache-rebuild").
+//	    WithPolicy(recur.Timeout(50 * time.Second))
+package rediscoord
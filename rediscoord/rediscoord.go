@@ -0,0 +1,200 @@
+//go:build redis
+
+package rediscoord
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+// releaseScript deletes a lock only if it still holds this instance's token,
+// so an instance whose lease expired cannot release another's lock
+const releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+// Coordinator holds recur coordination keys as Redis locks
+type Coordinator struct {
+	addr     string
+	ttl      time.Duration
+	token    string
+	prefix   string
+	failOpen bool
+	onError  func(error)
+	dial     func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+var _ recur.Coordinator = (*Coordinator)(nil)
+
+// New creates a coordinator using the Redis server at addr with locks
+// leased for ttl. The lease must outlast the longest cycle holding it.
+func New(addr string, ttl time.Duration) *Coordinator {
+	var token [16]byte
+	_, _ = rand.Read(token[:])
+	var d net.Dialer
+	return &Coordinator{
+		addr:   addr,
+		ttl:    ttl,
+		token:  hex.EncodeToString(token[:]),
+		prefix: "recur:lock:",
+		dial:   d.DialContext,
+	}
+}
+
+// WithDialer sets how connections are made, for example with TLS
+func (c *Coordinator) WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) *Coordinator {
+	c.dial = dial
+	return c
+}
+
+// WithPrefix sets the prefix of Redis keys, "recur:lock:" by default
+func (c *Coordinator) WithPrefix(prefix string) *Coordinator {
+	c.prefix = prefix
+	return c
+}
+
+// WithFailOpen controls whether TryAcquire grants a key when Redis cannot
+// be reached. By default it returns the error, so no instance runs the
+// operation while Redis is down; fail open when duplicate work is cheaper
+// than none. Errors are reported to OnError either way.
+func (c *Coordinator) WithFailOpen(enabled bool) *Coordinator {
+	c.failOpen = enabled
+	return c
+}
+
+// OnError sets fn to receive errors talking to Redis
+func (c *Coordinator) OnError(fn func(error)) *Coordinator {
+	c.onError = fn
+	return c
+}
+
+// TryAcquire takes the lock for key if no instance holds it
+func (c *Coordinator) TryAcquire(ctx context.Context, key string) (bool, error) {
+	reply, err := c.do(ctx, "SET", c.prefix+key, c.token, "NX", "PX", strconv.FormatInt(c.ttl.Milliseconds(), 10))
+	if err != nil {
+		c.report(err)
+		if c.failOpen {
+			return true, nil
+		}
+		return false, err
+	}
+	return reply == "OK", nil
+}
+
+// Release gives up the lock for key if this instance still holds it
+func (c *Coordinator) Release(ctx context.Context, key string) {
+	if _, err := c.do(ctx, "EVAL", releaseScript, "1", c.prefix+key, c.token); err != nil {
+		c.report(err)
+	}
+}
+
+// Close closes the connection to Redis
+func (c *Coordinator) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+func (c *Coordinator) report(err error) {
+	if c.onError != nil {
+		c.onError(err)
+	}
+}
+
+// do sends a command and reads its reply, dropping the connection on
+// errors so the next command reconnects
+func (c *Coordinator) do(ctx context.Context, args ...string) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		conn, err := c.dial(ctx, "tcp", c.addr)
+		if err != nil {
+			return nil, fmt.Errorf("rediscoord: %w", err)
+		}
+		c.conn, c.r = conn, bufio.NewReader(conn)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.conn.SetDeadline(deadline)
+	} else {
+		_ = c.conn.SetDeadline(time.Time{})
+	}
+
+	reply, err := c.roundTrip(args)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rediscoord: %w", err)
+	}
+	return reply, nil
+}
+
+func (c *Coordinator) roundTrip(args []string) (any, error) {
+	cmd := "*" + strconv.Itoa(len(args)) + "\r\n"
+	for _, arg := range args {
+		cmd += "$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n"
+	}
+	if _, err := c.conn.Write([]byte(cmd)); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+// redisError is an error reply from the server
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+// readReply reads one RESP reply: a string for simple and bulk strings, an
+// int64 for integers, nil for null bulk strings and a redisError for errors
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	body := line[1 : len(line)-2]
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	default:
+		return nil, fmt.Errorf("unsupported reply %q", line)
+	}
+}
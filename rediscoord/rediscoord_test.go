@@ -0,0 +1,144 @@
+//go:build redis
+
+package rediscoord
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	recur "github.com/amr8t/go-recur"
+)
+
+// fakeRedis serves the SET NX and release script commands the coordinator
+// sends, from an in-memory map
+type fakeRedis struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func (f *fakeRedis) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				args, err := readCommand(r)
+				if err != nil {
+					return
+				}
+				fmt.Fprint(conn, f.exec(args))
+			}
+		}()
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch args[0] {
+	case "SET":
+		if _, ok := f.keys[args[1]]; ok {
+			return "$-1\r\n"
+		}
+		f.keys[args[1]] = args[2]
+		return "+OK\r\n"
+	case "EVAL":
+		if f.keys[args[3]] != args[4] {
+			return ":0\r\n"
+		}
+		delete(f.keys, args[3])
+		return ":1\r\n"
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestCoordinator(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	redis := &fakeRedis{keys: map[string]string{}}
+	go redis.serve(ln)
+
+	a := New(ln.Addr().String(), time.Minute)
+	b := New(ln.Addr().String(), time.Minute)
+	defer a.Close()
+	defer b.Close()
+
+	ctx := context.Background()
+	if ok, err := a.TryAcquire(ctx, "rebuild"); !ok || err != nil {
+		t.Fatalf("Expected the first instance to acquire the lock, got %v", err)
+	}
+	if ok, err := b.TryAcquire(ctx, "rebuild"); ok || err != nil {
+		t.Errorf("Expected the second instance to be refused, got %v", err)
+	}
+	b.Release(ctx, "rebuild") // Not the holder, must not release
+	if redis.keys["recur:lock:rebuild"] != a.token {
+		t.Error("Expected a non-holder's release to leave the lock")
+	}
+	a.Release(ctx, "rebuild")
+	if ok, _ := b.TryAcquire(ctx, "rebuild"); !ok {
+		t.Error("Expected the lock to be free after release")
+	}
+
+	err = recur.Func0(func() error { return nil }).WithCoordinator(a, "rebuild").Build()()
+	if !errors.Is(err, recur.ErrCoordinated) {
+		t.Errorf("Expected ErrCoordinated while another instance holds the key, got %v", err)
+	}
+}
+
+func TestCoordinator_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	var reported error
+	c := New(addr, time.Minute).OnError(func(err error) { reported = err })
+	if ok, err := c.TryAcquire(context.Background(), "rebuild"); ok || err == nil || reported == nil {
+		t.Errorf("Expected an unreachable server to fail and report, got %v and %v", err, reported)
+	}
+	if ok, err := c.WithFailOpen(true).TryAcquire(context.Background(), "rebuild"); !ok || err != nil {
+		t.Errorf("Expected fail-open to grant the key, got %v", err)
+	}
+
+	err = recur.Func0(func() error { return nil }).WithCoordinator(c.WithFailOpen(false), "rebuild").Build()()
+	if !errors.Is(err, recur.ErrCoordinatorUnavailable) || errors.Is(err, recur.ErrCoordinated) {
+		t.Errorf("Expected the Redis error surfaced, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+var (
+	matchersMu sync.RWMutex
+	matchers   = map[string]ErrorMatcher{}
+	// matcherNames maps a registered matcher's function name back to its
+	// registered name, so descriptions show "timeout" instead of a symbol.
+	// Closures share their symbol and are left out.
+	matcherNames = map[string]string{}
+)
+
+// Built-in matcher names available to ResolveMatcher
+const (
+	MatcherAny             = "any"
+	MatcherNone            = "none"
+	MatcherTimeout         = "timeout"
+	MatcherTemporary       = "temporary"
+	MatcherConnectionReset = "connection_reset"
+	MatcherConnRefused     = "connection_refused"
+	MatcherEOF             = "eof"
+	MatcherNetwork         = "network"
+	MatcherHTTPRetryable   = "http_retryable"
+	MatcherHTTPTransport   = "http_transport"
+	MatcherGRPCRetryable   = "grpc_retryable"
+	MatcherGRPCUnavailable = "grpc_unavailable"
+)
+
+func init() {
+	RegisterMatcher(MatcherAny, MatchAny)
+	RegisterMatcher(MatcherNone, MatchNone)
+	RegisterMatcher(MatcherTimeout, matchTimeout)
+	RegisterMatcher(MatcherTemporary, matchTemporary)
+	RegisterMatcher(MatcherConnectionReset, matchConnectionReset)
+	RegisterMatcher(MatcherConnRefused, matchConnectionRefused)
+	RegisterMatcher(MatcherEOF, matchEOF)
+	RegisterMatcher(MatcherNetwork, matchNetwork)
+	RegisterMatcher(MatcherHTTPRetryable, matchHTTPRetryable)
+	RegisterMatcher(MatcherHTTPTransport, matchHTTPTransport)
+	RegisterMatcher(MatcherGRPCRetryable, matchGRPCRetryable)
+	RegisterMatcher(MatcherGRPCUnavailable, matchGRPCUnavailable)
+}
+
+// RegisterMatcher makes matcher available under name to ResolveMatcher, so
+// declarative configuration (YAML, env vars) can refer to matchers by name.
+// Registering an existing name replaces the previous matcher.
+func RegisterMatcher(name string, matcher ErrorMatcher) {
+	matchersMu.Lock()
+	defer matchersMu.Unlock()
+
+	matchers[name] = matcher
+	maps.DeleteFunc(matcherNames, func(_, registered string) bool {
+		return registered == name // Drop the matcher name replaces
+	})
+	if fn := funcName(matcher); !strings.Contains(fn, ".func") {
+		matcherNames[fn] = name
+	}
+}
+
+// ResolveMatcher returns the matchers registered under names combined with
+// Or. It returns an error naming the first unknown matcher.
+func ResolveMatcher(names ...string) (ErrorMatcher, error) {
+	matchersMu.RLock()
+	defer matchersMu.RUnlock()
+
+	resolved := make([]ErrorMatcher, 0, len(names))
+	for _, name := range names {
+		matcher, ok := matchers[name]
+		if !ok {
+			return nil, fmt.Errorf("recur: unknown matcher %q", name)
+		}
+		resolved = append(resolved, matcher)
+	}
+	if len(resolved) == 1 {
+		return resolved[0], nil
+	}
+	return Or(resolved...), nil
+}
+
+// RegisteredMatchers returns the sorted names of all registered matchers
+func RegisteredMatchers() []string {
+	matchersMu.RLock()
+	defer matchersMu.RUnlock()
+
+	names := make([]string, 0, len(matchers))
+	for name := range matchers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// registeredName returns the registered name of matcher, if any
+func registeredName(matcher ErrorMatcher) (string, bool) {
+	matchersMu.RLock()
+	defer matchersMu.RUnlock()
+
+	name, ok := matcherNames[funcName(matcher)]
+	return name, ok
+}
+
+// matchTimeout matches context deadlines and errors reporting Timeout() true
+func matchTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var t interface{ Timeout() bool }
+	return errors.As(err, &t) && t.Timeout()
+}
+
+// matchTemporary matches errors reporting Temporary() true
+func matchTemporary(err error) bool {
+	var t interface{ Temporary() bool }
+	return errors.As(err, &t) && t.Temporary()
+}
+
+// matchConnectionReset matches connections reset or closed by the peer
+func matchConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+// matchConnectionRefused matches refused connection attempts
+func matchConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// matchEOF matches connections closed mid-response
+func matchEOF(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// matchNetwork matches any net.Error
+func matchNetwork(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// matchHTTPRetryable matches errors carrying an HTTP status that is worth
+// retrying: 408, 429, 500, 502, 503 or 504. The status comes from a
+// StatusError or any error with a StatusCode() int method.
+func matchHTTPRetryable(err error) bool {
+	var status int
+	if e, ok := asError[*StatusError](err); ok {
+		status = e.Status
+	} else if e, ok := asError[interface {
+		error
+		StatusCode() int
+	}](err); ok {
+		status = e.StatusCode()
+	}
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// matchHTTPTransport matches http.Client errors where no response arrived
+// because the connection failed, timed out or was closed
+func matchHTTPTransport(err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	return urlErr.Timeout() || matchNetwork(urlErr.Err) || matchEOF(urlErr.Err) || matchConnectionReset(urlErr.Err)
+}
+
+// gRPC status codes, see google.golang.org/grpc/codes
+const (
+	grpcResourceExhausted = 8
+	grpcAborted           = 10
+	grpcUnavailable       = 14
+)
+
+// matchGRPCRetryable matches gRPC status errors with code Unavailable,
+// ResourceExhausted or Aborted
+func matchGRPCRetryable(err error) bool {
+	code, ok := grpcCode(err)
+	return ok && (code == grpcUnavailable || code == grpcResourceExhausted || code == grpcAborted)
+}
+
+// matchGRPCUnavailable matches gRPC status errors with code Unavailable
+func matchGRPCUnavailable(err error) bool {
+	code, ok := grpcCode(err)
+	return ok && code == grpcUnavailable
+}
+
+// grpcCode returns the code of the first gRPC status error in err's chain.
+// Status errors are found by their GRPCStatus method, called through
+// reflection so the module doesn't depend on grpc.
+func grpcCode(err error) (uint32, bool) {
+	for err != nil {
+		if method := reflect.ValueOf(err).MethodByName("GRPCStatus"); method.IsValid() && method.Type().NumIn() == 0 && method.Type().NumOut() == 1 {
+			status := method.Call(nil)[0]
+			if status.Kind() == reflect.Pointer && !status.IsNil() {
+				if code := status.MethodByName("Code"); code.IsValid() && code.Type().NumIn() == 0 && code.Type().NumOut() == 1 {
+					if c := code.Call(nil)[0]; c.CanUint() {
+						return uint32(c.Uint()), true
+					}
+				}
+			}
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				if code, ok := grpcCode(err); ok {
+					return code, true
+				}
+			}
+			return 0, false
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
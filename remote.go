@@ -0,0 +1,196 @@
+package recur
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RemotePolicyProvider fetches a retry policy from an HTTP endpoint, so
+// retry behavior across a fleet can be managed centrally. The endpoint
+// serves a PolicyDescription as JSON, the same shape Describe produces;
+// max_attempts, backoff (see ParseBackoff), timeout, min_delay, max_delay,
+// matcher (a name registered with RegisterMatcher) and variant are applied,
+// and fields left out or zero keep the local configuration.
+//
+// Fetches send If-None-Match with the last ETag, so unchanged policies cost
+// a 304 and no parsing. They are retried on their own conservative policy,
+// independent of any fetched one: 3 attempts with jittered exponential
+// backoff on network errors, 5xx and 429 responses, each bounded to 10s.
+//
+// Example:
+//
+//	remote := recur.NewRemotePolicyProvider("https://config.internal/retry/payments.json")
+//	if err := remote.Refresh(ctx); err != nil {
+//	    log.Printf("using local retry policy: %v", err)
+//	}
+//	recur.Every(time.Minute, remote.Refresh, recur.MaxAttempts(1)).Start(ctx)
+//	charge := recur.Func1(chargeCard).WithPolicy(remote.Policy())
+type RemotePolicyProvider struct {
+	url    string
+	client *http.Client
+	config *IteratorBuilder
+
+	mu     sync.RWMutex
+	etag   string
+	desc   PolicyDescription
+	policy Policy
+}
+
+// NewRemotePolicyProvider creates a provider fetching from url with
+// http.DefaultClient. Nothing is fetched until Refresh is called.
+func NewRemotePolicyProvider(url string) *RemotePolicyProvider {
+	return &RemotePolicyProvider{
+		url:    url,
+		client: http.DefaultClient,
+		config: Iter().
+			WithMaxAttempts(3).
+			WithBackoff(Jitter(Exponential(500*time.Millisecond).(*ExponentialBackoff).WithMaxDelay(5*time.Second), 0.5)).
+			WithAttemptTimeout(10 * time.Second).
+			RetryIf(matchNetwork),
+	}
+}
+
+// WithClient sets the HTTP client used for fetches
+func (p *RemotePolicyProvider) WithClient(client *http.Client) *RemotePolicyProvider {
+	p.client = client
+	return p
+}
+
+// Refresh fetches the policy, replacing the current one if the endpoint
+// serves a new version. On error the last fetched policy stays in effect.
+func (p *RemotePolicyProvider) Refresh(ctx context.Context) error {
+	p.mu.RLock()
+	etag := p.etag
+	p.mu.RUnlock()
+
+	var final error
+	for attempt := range p.config.seq(ctx, &final) {
+		desc, tag, changed, err := p.fetch(attempt.Context(), etag)
+		if err == nil && changed {
+			err = p.set(desc, tag)
+		}
+		attempt.Result(err)
+	}
+	return final
+}
+
+// fetch requests the policy, reporting changed false for a 304
+func (p *RemotePolicyProvider) fetch(ctx context.Context, etag string) (PolicyDescription, string, bool, error) {
+	var desc PolicyDescription
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return desc, "", false, Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return desc, "", false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return desc, "", false, nil
+	case resp.StatusCode == http.StatusOK:
+	default:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		err := fmt.Errorf("recur: policy endpoint responded %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		if resp.StatusCode == http.StatusTooManyRequests {
+			hint, _ := ParseRetryHint(resp.Header)
+			return desc, "", false, RateLimited(err, hint.After)
+		}
+		if resp.StatusCode >= 500 {
+			return desc, "", false, Transient(err)
+		}
+		return desc, "", false, Fatal(err)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, DefaultMaxBodyBytes)).Decode(&desc); err != nil {
+		return desc, "", false, Fatal(fmt.Errorf("recur: decoding remote policy: %w", err))
+	}
+	return desc, resp.Header.Get("ETag"), true, nil
+}
+
+// set validates desc and makes it the current policy
+func (p *RemotePolicyProvider) set(desc PolicyDescription, etag string) error {
+	policy, err := descriptionPolicy(desc)
+	if err != nil {
+		return Fatal(fmt.Errorf("recur: invalid remote policy: %w", err))
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.desc, p.etag, p.policy = desc, etag, policy
+	return nil
+}
+
+// Policy returns a policy applying the latest fetched policy at the start
+// of every retry cycle, on top of the local configuration. Until a fetch
+// succeeds the local configuration is used unchanged. It installs a policy
+// selector (see WithPolicySelector) and replaces any other.
+func (p *RemotePolicyProvider) Policy() Policy {
+	return PolicySelector(func(context.Context) Policy {
+		p.mu.RLock()
+		defer p.mu.RUnlock()
+		return p.policy
+	})
+}
+
+// Description returns the latest fetched policy, or false if no fetch has
+// succeeded yet
+func (p *RemotePolicyProvider) Description() (PolicyDescription, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.desc, p.policy != nil
+}
+
+// descriptionPolicy builds a policy from the fields of desc that
+// RemotePolicyProvider applies
+func descriptionPolicy(desc PolicyDescription) (Policy, error) {
+	var policies []Policy
+	if desc.MaxAttempts > 0 {
+		policies = append(policies, MaxAttempts(desc.MaxAttempts))
+	}
+	if desc.Backoff.Type != "" {
+		backoff, err := ParseBackoff(desc.Backoff)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, WithBackoff(backoff))
+	}
+	for _, d := range []struct {
+		value  string
+		policy func(time.Duration) Policy
+	}{
+		{desc.Timeout, Timeout},
+		{desc.MinDelay, MinDelay},
+		{desc.MaxDelay, MaxDelay},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, d.policy(v))
+	}
+	if desc.Matcher != "" {
+		matcher, err := ResolveMatcher(desc.Matcher)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, RetryIf(matcher))
+	}
+	if desc.Variant != "" {
+		return Variant(desc.Variant, policies...), nil
+	}
+	return CombinePolicies(policies...), nil
+}
@@ -0,0 +1,94 @@
+package recur
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRemotePolicyProvider(t *testing.T) {
+	var requests, unavailable atomic.Int32
+	body := atomic.Value{}
+	body.Store(`{"max_attempts": 2, "backoff": {"type": "constant", "params": {"delay": "1ms"}}, "variant": "central"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if unavailable.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		etag := `"` + body.Load().(string)[:20] + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(body.Load().(string)))
+	}))
+	defer server.Close()
+
+	remote := NewRemotePolicyProvider(server.URL)
+	remote.config.WithBackoff(NoDelay())
+
+	var attempts int
+	retrier := Func0(func() error {
+		attempts++
+		return ErrTemporary
+	}).WithMaxAttempts(5).WithBackoff(NoDelay()).WithPolicy(remote.Policy())
+
+	_ = retrier.Build()()
+	if attempts != 5 {
+		t.Errorf("Expected the local policy before the first fetch, got %d attempts", attempts)
+	}
+	if _, ok := remote.Description(); ok {
+		t.Error("Expected no description before the first fetch")
+	}
+
+	unavailable.Store(1)
+	if err := remote.Refresh(context.Background()); err != nil {
+		t.Fatalf("Expected a retried fetch to succeed, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected the 503 to be retried, got %d requests", requests.Load())
+	}
+	attempts = 0
+	_ = retrier.Build()()
+	if attempts != 2 {
+		t.Errorf("Expected the remote max attempts, got %d attempts", attempts)
+	}
+	if desc, ok := remote.Description(); !ok || desc.Variant != "central" {
+		t.Errorf("Unexpected description %+v", desc)
+	}
+
+	if err := remote.Refresh(context.Background()); err != nil || requests.Load() != 3 {
+		t.Errorf("Expected a not-modified fetch, got %v after %d requests", err, requests.Load())
+	}
+
+	body.Store(`{"max_attempts": 3, "matcher": "no-such-matcher"}`)
+	if err := remote.Refresh(context.Background()); err == nil {
+		t.Error("Expected an invalid policy to be rejected")
+	}
+	if requests.Load() != 4 {
+		t.Errorf("Expected an invalid policy not to be retried, got %d requests", requests.Load())
+	}
+	attempts = 0
+	_ = retrier.Build()()
+	if attempts != 2 {
+		t.Errorf("Expected the last valid policy to stay in effect, got %d attempts", attempts)
+	}
+}
+
+func TestRemotePolicyProvider_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	remote := NewRemotePolicyProvider(server.URL).WithClient(&http.Client{Timeout: time.Second})
+	if err := remote.Refresh(context.Background()); err == nil {
+		t.Error("Expected a 404 to fail the fetch")
+	}
+	if _, ok := remote.Description(); ok {
+		t.Error("Expected no description after a failed fetch")
+	}
+}
@@ -0,0 +1,167 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// Resolver retries DNS lookups that fail with temporary errors or timeouts,
+// optionally rotating through a list of DNS servers. Its lookup methods
+// mirror net.Resolver, and DialContext can replace a dialer's in
+// http.Transport or similar.
+type Resolver struct {
+	config    *IteratorBuilder
+	resolvers []*net.Resolver
+	dialer    net.Dialer
+}
+
+// NewResolver creates a resolver that retries temporary DNS errors up to 3
+// times with short jittered backoff. With servers ("host:port"), attempt n
+// queries servers[(n-1) % len(servers)]; otherwise net.DefaultResolver is
+// used for every attempt.
+//
+// Example:
+//
+//	r := recur.NewResolver("10.0.0.2:53", "10.0.0.3:53")
+//	transport := &http.Transport{DialContext: r.DialContext}
+func NewResolver(servers ...string) *Resolver {
+	r := &Resolver{
+		config: Iter().
+			WithBackoff(Jitter(Exponential(50*time.Millisecond).(*ExponentialBackoff).WithMaxDelay(time.Second), 0.5)).
+			RetryIf(MatchTemporaryDNS),
+	}
+	for _, server := range servers {
+		r.resolvers = append(r.resolvers, &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		})
+	}
+	if len(r.resolvers) == 0 {
+		r.resolvers = []*net.Resolver{net.DefaultResolver}
+	}
+	return r
+}
+
+// WithPolicy applies a policy to lookups
+func (r *Resolver) WithPolicy(policy Policy) *Resolver {
+	r.config.WithPolicy(policy)
+	return r
+}
+
+// MatchTemporaryDNS matches DNS errors that are temporary or timed out.
+// Negative answers (no such host) are not retried.
+func MatchTemporaryDNS(err error) bool {
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) {
+		return false
+	}
+	return !dnsErr.IsNotFound && (dnsErr.IsTemporary || dnsErr.IsTimeout)
+}
+
+// LookupHost looks up host, returning its addresses
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return lookup(ctx, r, func(ctx context.Context, res *net.Resolver) ([]string, error) {
+		return res.LookupHost(ctx, host)
+	})
+}
+
+// LookupIPAddr looks up host, returning its IPv4 and IPv6 addresses
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return lookup(ctx, r, func(ctx context.Context, res *net.Resolver) ([]net.IPAddr, error) {
+		return res.LookupIPAddr(ctx, host)
+	})
+}
+
+// LookupIP looks up host for network "ip", "ip4" or "ip6"
+func (r *Resolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	return lookup(ctx, r, func(ctx context.Context, res *net.Resolver) ([]net.IP, error) {
+		return res.LookupIP(ctx, network, host)
+	})
+}
+
+// LookupAddr performs a reverse lookup of addr
+func (r *Resolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	return lookup(ctx, r, func(ctx context.Context, res *net.Resolver) ([]string, error) {
+		return res.LookupAddr(ctx, addr)
+	})
+}
+
+// LookupCNAME returns the canonical name for host
+func (r *Resolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	return lookup(ctx, r, func(ctx context.Context, res *net.Resolver) (string, error) {
+		return res.LookupCNAME(ctx, host)
+	})
+}
+
+// LookupTXT returns the DNS TXT records for name
+func (r *Resolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return lookup(ctx, r, func(ctx context.Context, res *net.Resolver) ([]string, error) {
+		return res.LookupTXT(ctx, name)
+	})
+}
+
+// LookupMX returns the DNS MX records for name
+func (r *Resolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	return lookup(ctx, r, func(ctx context.Context, res *net.Resolver) ([]*net.MX, error) {
+		return res.LookupMX(ctx, name)
+	})
+}
+
+// LookupSRV looks up the SRV records for service, proto and name
+func (r *Resolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	type srv struct {
+		cname string
+		addrs []*net.SRV
+	}
+	res, err := lookup(ctx, r, func(ctx context.Context, res *net.Resolver) (srv, error) {
+		cname, addrs, err := res.LookupSRV(ctx, service, proto, name)
+		return srv{cname, addrs}, err
+	})
+	return res.cname, res.addrs, err
+}
+
+// DialContext resolves address with retries and dials the resolved
+// addresses in order until one connects
+func (r *Resolver) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return r.dialer.DialContext(ctx, network, address)
+	}
+
+	addrs, err := r.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialErr error
+	for _, addr := range addrs {
+		conn, err := r.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+		dialErr = err
+	}
+	return nil, dialErr
+}
+
+func lookup[T any](ctx context.Context, r *Resolver, fn func(ctx context.Context, res *net.Resolver) (T, error)) (T, error) {
+	var result T
+	var final error
+	for attempt := range r.config.seq(ctx, &final) {
+		res := r.resolvers[(attempt.Number-1)%len(r.resolvers)]
+		v, err := fn(attempt.Context(), res)
+		if err == nil {
+			result = v
+		}
+		attempt.Result(err)
+	}
+	return result, final
+}
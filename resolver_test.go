@@ -0,0 +1,72 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+)
+
+// servfailServer answers every DNS query with SERVFAIL and counts them
+func servfailServer(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var queries atomic.Int32
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			queries.Add(1)
+			resp := append([]byte(nil), buf[:n]...)
+			resp[2] |= 0x80                   // QR: response
+			resp[3] = resp[3]&0xf0 | 0x80 | 2 // RA, RCODE: SERVFAIL
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+	return conn.LocalAddr().String(), &queries
+}
+
+func TestResolver_RotatesServers(t *testing.T) {
+	addr1, queries1 := servfailServer(t)
+	addr2, queries2 := servfailServer(t)
+
+	r := NewResolver(addr1, addr2).WithPolicy(WithBackoff(NoDelay()))
+	_, err := r.LookupHost(context.Background(), "service.example.")
+
+	if !IsMaxAttemptsExceeded(err) {
+		t.Fatalf("Expected MaxAttemptsExceededError, got %v", err)
+	}
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsTemporary {
+		t.Errorf("Expected temporary DNSError, got %v", err)
+	}
+	if queries1.Load() == 0 || queries2.Load() == 0 {
+		t.Errorf("Expected both servers to be queried, got %d and %d", queries1.Load(), queries2.Load())
+	}
+}
+
+func TestMatchTemporaryDNS(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&net.DNSError{IsTemporary: true}, true},
+		{&net.DNSError{IsTimeout: true}, true},
+		{&net.DNSError{IsNotFound: true, IsTemporary: true}, false},
+		{ErrTemporary, false},
+	}
+	for _, tt := range tests {
+		if got := MatchTemporaryDNS(tt.err); got != tt.want {
+			t.Errorf("MatchTemporaryDNS(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
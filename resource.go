@@ -0,0 +1,112 @@
+package recur
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// provisioner sets up a resource for an attempt, returning the attempt's
+// context carrying it and a function releasing it
+type provisioner func(ctx context.Context) (context.Context, func(), error)
+
+// resourceKey is the context key of an attempt resource of type T
+type resourceKey[T any] struct{}
+
+// WithAttemptResource provisions a fresh resource, such as a temporary
+// directory, scratch table or token, for every attempt. setup runs before
+// the attempt with its context; the value is available from
+// AttemptResource and cleanup runs once the attempt finishes, before the
+// next backoff delay, even if the loop is exited early. A setup error
+// fails the attempt without running it; Seq bodies still receive the
+// attempt, with its context canceled and the setup error as the cause.
+//
+// Resources are looked up by type, so a later resource of the same type
+// shadows an earlier one; wrap the type to keep both.
+//
+// Example:
+//
+//	recur.WithAttemptResource(func(ctx context.Context) (string, func(), error) {
+//	    dir, err := os.MkdirTemp("", "build-")
+//	    return dir, func() { os.RemoveAll(dir) }, err
+//	})
+//	...
+//	dir, _ := recur.AttemptResource[string](attempt.Context())
+func WithAttemptResource[T any](setup func(ctx context.Context) (T, func(), error)) Policy {
+	return func(b *IteratorBuilder) {
+		b.resources = append(b.resources[:len(b.resources):len(b.resources)], func(ctx context.Context) (context.Context, func(), error) {
+			v, cleanup, err := setup(ctx)
+			if err != nil {
+				if cleanup != nil {
+					cleanup()
+				}
+				return ctx, nil, err
+			}
+			return context.WithValue(ctx, resourceKey[T]{}, v), cleanup, nil
+		})
+	}
+}
+
+// AttemptResource returns the attempt's resource of type T provisioned with
+// WithAttemptResource
+func AttemptResource[T any](ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(resourceKey[T]{}).(T)
+	return v, ok
+}
+
+// runAttempt provisions att's resources and runs the loop body with it.
+// Subtasks are joined and resources released when the body returns, even
+// if it panics or exits the goroutine. A setup error fails the attempt
+// without running the operation.
+func (s *iteratorState) runAttempt(att *Attempt, yield func(*Attempt) bool) bool {
+	release, err := s.provision(att)
+	att.startedAt = time.Now()
+	if err != nil {
+		att.Result(err)
+		if s.final != nil {
+			return true
+		}
+		// Seq bodies have no other way to see the failure, so as in refuse
+		// they get the attempt already failed, its context canceled with
+		// the setup error as the cause
+		failed := att.result
+		ctx, cancel := context.WithCancelCause(att.ctx)
+		cancel(err)
+		att.ctx = ctx
+		more := yield(att)
+		att.result = failed
+		return more
+	}
+	defer release()
+	defer att.closeSubtasks()
+	return s.runLabeled(att, yield)
+}
+
+// noRelease is the release function of attempts without resources
+var noRelease = func() {}
+
+// provision sets up the builder's resources for att in order. If one fails,
+// those already set up are released and the error is returned.
+func (s *iteratorState) provision(att *Attempt) (func(), error) {
+	if len(s.builder.resources) == 0 {
+		return noRelease, nil
+	}
+	var cleanups []func()
+	release := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+	for _, setup := range s.builder.resources {
+		ctx, cleanup, err := setup(att.ctx)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("recur: attempt resource setup: %w", err)
+		}
+		att.ctx = ctx
+		if cleanup != nil {
+			cleanups = append(cleanups, cleanup)
+		}
+	}
+	return release, nil
+}
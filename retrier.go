@@ -0,0 +1,371 @@
+package recur
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Retrier decorates a function of type F with retry logic. Build returns a
+// function with the same signature as the original; BuildContext returns the
+// context-aware variant C, which takes a context.Context as first argument.
+//
+// Retriers are created with Func0, Func1, Func2, FuncR, Func1R and Func2R and
+// share the iterator's configuration and execution machinery.
+type Retrier[F, C any] struct {
+	config *IteratorBuilder
+	wrap   func(config *IteratorBuilder) C
+	bind   func(ctx context.Context, c C) F
+}
+
+func newRetrier[F, C any](wrap func(config *IteratorBuilder) C, bind func(ctx context.Context, c C) F) *Retrier[F, C] {
+	return &Retrier[F, C]{
+		config: Iter(),
+		wrap:   wrap,
+		bind:   bind,
+	}
+}
+
+// Func0 wraps a function with no arguments returning only an error
+func Func0(fn func() error) *Retrier[func() error, func(context.Context) error] {
+	return newRetrier(
+		func(config *IteratorBuilder) func(context.Context) error {
+			return func(ctx context.Context) error {
+				return config.run(ctx, func(context.Context) error {
+					return fn()
+				})
+			}
+		},
+		func(ctx context.Context, c func(context.Context) error) func() error {
+			return func() error {
+				return c(ctx)
+			}
+		},
+	)
+}
+
+// Func1 wraps a function with one argument returning only an error
+func Func1[A any](fn func(A) error) *Retrier[func(A) error, func(context.Context, A) error] {
+	return newRetrier(
+		func(config *IteratorBuilder) func(context.Context, A) error {
+			return func(ctx context.Context, a A) error {
+				return config.run(ctx, func(context.Context) error {
+					return fn(a)
+				})
+			}
+		},
+		func(ctx context.Context, c func(context.Context, A) error) func(A) error {
+			return func(a A) error {
+				return c(ctx, a)
+			}
+		},
+	)
+}
+
+// Func2 wraps a function with two arguments returning only an error
+func Func2[A, B any](fn func(A, B) error) *Retrier[func(A, B) error, func(context.Context, A, B) error] {
+	return newRetrier(
+		func(config *IteratorBuilder) func(context.Context, A, B) error {
+			return func(ctx context.Context, a A, b B) error {
+				return config.run(ctx, func(context.Context) error {
+					return fn(a, b)
+				})
+			}
+		},
+		func(ctx context.Context, c func(context.Context, A, B) error) func(A, B) error {
+			return func(a A, b B) error {
+				return c(ctx, a, b)
+			}
+		},
+	)
+}
+
+// FuncR wraps a function with no arguments returning a value and an error.
+// The built function returns the value from the successful attempt, or the
+// zero value of T if all attempts fail. Values failing one of conditions
+// fail their attempt, see PostCondition.
+func FuncR[T any](fn func() (T, error), conditions ...PostCondition[T]) *Retrier[func() (T, error), func(context.Context) (T, error)] {
+	return newRetrier(
+		func(config *IteratorBuilder) func(context.Context) (T, error) {
+			return func(ctx context.Context) (T, error) {
+				return runValue(ctx, config, conditions, fn)
+			}
+		},
+		func(ctx context.Context, c func(context.Context) (T, error)) func() (T, error) {
+			return func() (T, error) {
+				return c(ctx)
+			}
+		},
+	)
+}
+
+// Func1R wraps a function with one argument returning a value and an error,
+// checking values against conditions like FuncR
+func Func1R[A, T any](fn func(A) (T, error), conditions ...PostCondition[T]) *Retrier[func(A) (T, error), func(context.Context, A) (T, error)] {
+	return newRetrier(
+		func(config *IteratorBuilder) func(context.Context, A) (T, error) {
+			return func(ctx context.Context, a A) (T, error) {
+				return runValue(ctx, config, conditions, func() (T, error) {
+					return fn(a)
+				})
+			}
+		},
+		func(ctx context.Context, c func(context.Context, A) (T, error)) func(A) (T, error) {
+			return func(a A) (T, error) {
+				return c(ctx, a)
+			}
+		},
+	)
+}
+
+// Func2R wraps a function with two arguments returning a value and an error,
+// checking values against conditions like FuncR
+func Func2R[A, B, T any](fn func(A, B) (T, error), conditions ...PostCondition[T]) *Retrier[func(A, B) (T, error), func(context.Context, A, B) (T, error)] {
+	return newRetrier(
+		func(config *IteratorBuilder) func(context.Context, A, B) (T, error) {
+			return func(ctx context.Context, a A, b B) (T, error) {
+				return runValue(ctx, config, conditions, func() (T, error) {
+					return fn(a, b)
+				})
+			}
+		},
+		func(ctx context.Context, c func(context.Context, A, B) (T, error)) func(A, B) (T, error) {
+			return func(a A, b B) (T, error) {
+				return c(ctx, a, b)
+			}
+		},
+	)
+}
+
+// runValue retries fn and returns the value of the successful attempt.
+// Values failing a post-condition fail their attempt.
+func runValue[T any](ctx context.Context, config *IteratorBuilder, conditions []PostCondition[T], fn func() (T, error)) (T, error) {
+	var result slot[T]
+	err := config.run(ctx, func(context.Context) error {
+		v, err := fn()
+		if err == nil && (config.retryOnZero || len(conditions) > 0) {
+			err = checkValue(config, conditions, v)
+		}
+		if err == nil {
+			result.set(v)
+		}
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result.close(), nil
+}
+
+// slot holds a value set by attempts, ignoring sets from abandoned
+// attempts that finish after the cycle ended
+type slot[T any] struct {
+	mu     sync.Mutex
+	v      T
+	closed bool
+}
+
+func (s *slot[T]) set(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.v = v
+	}
+}
+
+// close stops further sets and returns the value
+func (s *slot[T]) close() T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.v
+}
+
+// WithMaxAttempts sets the maximum number of attempts, counting the first one
+func (r *Retrier[F, C]) WithMaxAttempts(n int) *Retrier[F, C] {
+	r.config.WithMaxAttempts(n)
+	return r
+}
+
+// WithMaxRetries sets the maximum number of retries after the first attempt
+func (r *Retrier[F, C]) WithMaxRetries(n int) *Retrier[F, C] {
+	r.config.WithMaxRetries(n)
+	return r
+}
+
+// WithBackoff sets the backoff strategy
+func (r *Retrier[F, C]) WithBackoff(backoff Backoff) *Retrier[F, C] {
+	r.config.WithBackoff(backoff)
+	return r
+}
+
+// WithTimeout sets an overall timeout for each call
+func (r *Retrier[F, C]) WithTimeout(d time.Duration) *Retrier[F, C] {
+	r.config.WithTimeout(d)
+	return r
+}
+
+// RetryIf sets the error matcher
+func (r *Retrier[F, C]) RetryIf(matcher ErrorMatcher) *Retrier[F, C] {
+	r.config.RetryIf(matcher)
+	return r
+}
+
+// OnRetry registers a hook called after each failed attempt
+func (r *Retrier[F, C]) OnRetry(hook Hook) *Retrier[F, C] {
+	r.config.OnRetry(hook)
+	return r
+}
+
+// WithAsyncHooks runs hooks on a worker goroutine, see
+// IteratorBuilder.WithAsyncHooks
+func (r *Retrier[F, C]) WithAsyncHooks(buffer int) *Retrier[F, C] {
+	r.config.WithAsyncHooks(buffer)
+	return r
+}
+
+// OnHookError sets fn to receive hook panics, see IteratorBuilder.OnHookError
+func (r *Retrier[F, C]) OnHookError(fn func(err error)) *Retrier[F, C] {
+	r.config.OnHookError(fn)
+	return r
+}
+
+// WithContext sets the context used by Build. BuildContext ignores it in
+// favor of the context passed to each call.
+func (r *Retrier[F, C]) WithContext(ctx context.Context) *Retrier[F, C] {
+	r.config.WithContext(ctx)
+	return r
+}
+
+// WithName names the retried operation for hook events and Describe
+func (r *Retrier[F, C]) WithName(name string) *Retrier[F, C] {
+	r.config.WithName(name)
+	return r
+}
+
+// WithNegativeCache caches non-retryable failures per key for ttl, see
+// IteratorBuilder.WithNegativeCache
+func (r *Retrier[F, C]) WithNegativeCache(ttl time.Duration) *Retrier[F, C] {
+	r.config.WithNegativeCache(ttl)
+	return r
+}
+
+// WithAttemptTimeout bounds each attempt, see
+// IteratorBuilder.WithAttemptTimeout
+func (r *Retrier[F, C]) WithAttemptTimeout(d time.Duration) *Retrier[F, C] {
+	r.config.WithAttemptTimeout(d)
+	return r
+}
+
+// WithIsolatedAttempts runs each attempt on its own goroutine, see
+// IteratorBuilder.WithIsolatedAttempts
+func (r *Retrier[F, C]) WithIsolatedAttempts(enabled bool) *Retrier[F, C] {
+	r.config.WithIsolatedAttempts(enabled)
+	return r
+}
+
+// WithPreflight checks conditions before every attempt, see
+// IteratorBuilder.WithPreflight
+func (r *Retrier[F, C]) WithPreflight(check func(ctx context.Context) error) *Retrier[F, C] {
+	r.config.WithPreflight(check)
+	return r
+}
+
+// WithPreflightBackoff sets the wait between failed preflight checks
+func (r *Retrier[F, C]) WithPreflightBackoff(backoff Backoff) *Retrier[F, C] {
+	r.config.WithPreflightBackoff(backoff)
+	return r
+}
+
+// WithRetryOnZeroValue treats zero values returned with a nil error as
+// failures, see IteratorBuilder.WithRetryOnZeroValue
+func (r *Retrier[F, C]) WithRetryOnZeroValue(enabled bool) *Retrier[F, C] {
+	r.config.WithRetryOnZeroValue(enabled)
+	return r
+}
+
+// WithDelayIncludesAttempt measures backoff delays between attempt starts,
+// see IteratorBuilder.WithDelayIncludesAttempt
+func (r *Retrier[F, C]) WithDelayIncludesAttempt(enabled bool) *Retrier[F, C] {
+	r.config.WithDelayIncludesAttempt(enabled)
+	return r
+}
+
+// WithMetrics enables automatic metrics collection
+func (r *Retrier[F, C]) WithMetrics(name string) *Retrier[F, C] {
+	r.config.WithMetrics(name)
+	return r
+}
+
+// WithMetricsCollector uses an existing metrics collector
+func (r *Retrier[F, C]) WithMetricsCollector(m *MetricsCollector) *Retrier[F, C] {
+	r.config.WithMetricsCollector(m)
+	return r
+}
+
+// WithPolicy applies a policy
+func (r *Retrier[F, C]) WithPolicy(policy Policy) *Retrier[F, C] {
+	r.config.WithPolicy(policy)
+	return r
+}
+
+// Metrics returns the metrics collector if metrics are enabled
+func (r *Retrier[F, C]) Metrics() *MetricsCollector {
+	return r.config.Metrics()
+}
+
+// Attempts returns an iterator over attempts with the retrier's
+// configuration, for call sites that need the loop form, e.g. to inspect
+// each attempt, without duplicating the configuration in Iter. Like Build,
+// it captures the configuration when called and runs under the context set
+// with WithContext. The wrapped function is not called.
+//
+// Example:
+//
+//	for attempt := range fetch.Attempts() {
+//	    resp, err := client.Do(req.WithContext(attempt.Context()))
+//	    attempt.Result(err)
+//	}
+func (r *Retrier[F, C]) Attempts() iter.Seq[*Attempt] {
+	return r.config.clone().Seq()
+}
+
+// Build returns the decorated function. Configuration is captured at build
+// time, so later changes to the retrier don't affect built functions.
+// The returned function is safe for concurrent use.
+func (r *Retrier[F, C]) Build() F {
+	config := r.config.clone()
+	return r.bind(config.ctx, r.wrap(config))
+}
+
+// BuildContext returns the decorated function taking a context as first
+// argument, used for cancellation and deadlines across all attempts
+func (r *Retrier[F, C]) BuildContext() C {
+	return r.wrap(r.config.clone())
+}
+
+// Clone returns a copy of the builder that can be configured independently,
+// for example to add or remove hooks without affecting the original
+func (b *IteratorBuilder) Clone() *IteratorBuilder {
+	return b.clone()
+}
+
+// clone returns a copy of the builder that can be configured independently
+func (b *IteratorBuilder) clone() *IteratorBuilder {
+	c := *b
+	c.hooks = slices.Clone(b.hooks)
+	return &c
+}
+
+// run executes op with retries under ctx and returns nil on success, or
+// the same classified error reported in give-up events
+func (b *IteratorBuilder) run(ctx context.Context, op func(ctx context.Context) error) error {
+	var final error
+	for attempt := range b.seq(ctx, &final) {
+		attempt.Result(b.execute(attempt.Context(), attempt, op))
+	}
+	return final
+}
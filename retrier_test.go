@@ -0,0 +1,1103 @@
+package recur
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFunc0_Build(t *testing.T) {
+	counter := 0
+	fn := Func0(func() error {
+		counter++
+		if counter < 3 {
+			return ErrTemporary
+		}
+		return nil
+	}).
+		WithMaxAttempts(5).
+		WithBackoff(NoDelay()).
+		Build()
+
+	if err := fn(); err != nil {
+		t.Errorf("Expected success, got %v", err)
+	}
+	if counter != 3 {
+		t.Errorf("Expected 3 attempts, got %d", counter)
+	}
+}
+
+func TestFunc0_MaxAttemptsExceeded(t *testing.T) {
+	counter := 0
+	fn := Func0(func() error {
+		counter++
+		return ErrTemporary
+	}).
+		WithMaxAttempts(3).
+		WithBackoff(NoDelay()).
+		Build()
+
+	err := fn()
+	if !IsMaxAttemptsExceeded(err) {
+		t.Fatalf("Expected MaxAttemptsExceededError, got %v", err)
+	}
+	if !errors.Is(err, ErrTemporary) {
+		t.Errorf("Expected error to wrap the last attempt error, got %v", err)
+	}
+	if counter != 3 {
+		t.Errorf("Expected 3 attempts, got %d", counter)
+	}
+}
+
+func TestFunc0_NonRetryable(t *testing.T) {
+	counter := 0
+	fn := Func0(func() error {
+		counter++
+		return ErrFatal
+	}).
+		RetryIf(MatchErrors(ErrTemporary)).
+		Build()
+
+	err := fn()
+	if !IsNonRetryable(err) || !errors.Is(err, ErrFatal) {
+		t.Errorf("Expected non-retryable error wrapping ErrFatal, got %v", err)
+	}
+	if counter != 1 {
+		t.Errorf("Expected 1 attempt, got %d", counter)
+	}
+}
+
+func TestFunc_Arguments(t *testing.T) {
+	seen := []string{}
+	process := Func1(func(item string) error {
+		seen = append(seen, item)
+		return nil
+	}).Build()
+
+	if err := process("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	add := Func2(func(a, b int) error {
+		if a+b != 3 {
+			return ErrFatal
+		}
+		return nil
+	}).Build()
+
+	if err := add(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != 1 || seen[0] != "a" {
+		t.Errorf("Expected argument to be passed through, got %v", seen)
+	}
+}
+
+func TestFuncR_ReturnValues(t *testing.T) {
+	counter := 0
+	fetch := FuncR(func() (string, error) {
+		counter++
+		if counter < 2 {
+			return "partial", ErrTemporary
+		}
+		return "data", nil
+	}).WithBackoff(NoDelay()).Build()
+
+	result, err := fetch()
+	if err != nil || result != "data" {
+		t.Errorf("Expected data, got %q, %v", result, err)
+	}
+
+	parse := Func1R(strconv.Atoi).WithMaxAttempts(1).Build()
+	if n, err := parse("42"); err != nil || n != 42 {
+		t.Errorf("Expected 42, got %d, %v", n, err)
+	}
+	if n, err := parse("x"); err == nil || n != 0 {
+		t.Errorf("Expected zero value and error, got %d, %v", n, err)
+	}
+
+	join := Func2R(func(a string, b int) (string, error) {
+		return a + strconv.Itoa(b), nil
+	}).Build()
+	if s, err := join("v", 2); err != nil || s != "v2" {
+		t.Errorf("Expected v2, got %q, %v", s, err)
+	}
+}
+
+func TestRetrier_BuildContext(t *testing.T) {
+	fn := Func0(func() error {
+		return ErrTemporary
+	}).
+		WithMaxAttempts(10).
+		WithBackoff(Constant(50 * time.Millisecond)).
+		BuildContext()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 75*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("Expected call to stop promptly at the deadline")
+	}
+}
+
+func TestRetrier_WithPolicy(t *testing.T) {
+	var events []RetryEvent
+	standard := CombinePolicies(
+		MaxAttempts(4),
+		WithBackoff(NoDelay()),
+		Timeout(time.Second),
+		RetryIf(MatchErrors(ErrTemporary)),
+		OnRetry(func(ctx context.Context, e RetryEvent) {
+			events = append(events, e)
+		}),
+	)
+
+	counter := 0
+	fn := Func0(func() error {
+		counter++
+		return ErrTemporary
+	}).WithPolicy(standard).WithMetrics("policy").Build()
+
+	if err := fn(); !IsMaxAttemptsExceeded(err) {
+		t.Errorf("Expected MaxAttemptsExceededError, got %v", err)
+	}
+	if counter != 4 {
+		t.Errorf("Expected 4 attempts, got %d", counter)
+	}
+	if len(events) != 4 {
+		t.Errorf("Expected 4 hook events, got %d", len(events))
+	}
+
+	// The same policy configures iterators
+	iterations := 0
+	for attempt := range Iter().WithPolicy(standard).Seq() {
+		iterations++
+		attempt.Result(ErrTemporary)
+	}
+	if iterations != 4 {
+		t.Errorf("Expected 4 iterations, got %d", iterations)
+	}
+}
+
+func TestRetrier_BuildSnapshotsConfig(t *testing.T) {
+	counter := 0
+	retrier := Func0(func() error {
+		counter++
+		return ErrTemporary
+	}).WithMaxAttempts(2).WithBackoff(NoDelay())
+
+	fn := retrier.Build()
+	retrier.WithMaxAttempts(5)
+
+	_ = fn()
+	if counter != 2 {
+		t.Errorf("Expected built function to keep 2 attempts, got %d", counter)
+	}
+}
+
+func TestRetrier_Metrics(t *testing.T) {
+	counter := 0
+	retrier := Func0(func() error {
+		counter++
+		if counter%2 == 1 {
+			return ErrTemporary
+		}
+		return nil
+	}).WithBackoff(NoDelay()).WithMetrics("calls")
+	fn := retrier.Build()
+
+	for i := 0; i < 3; i++ {
+		if err := fn(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	metrics := retrier.Metrics()
+	if metrics.TotalAttempts.Load() != 3 || metrics.SuccessCount.Load() != 3 || metrics.TotalRetries.Load() != 3 {
+		t.Errorf("Unexpected metrics: attempts=%d successes=%d retries=%d",
+			metrics.TotalAttempts.Load(), metrics.SuccessCount.Load(), metrics.TotalRetries.Load())
+	}
+}
+
+func BenchmarkFunc0_SuccessFirstAttempt(b *testing.B) {
+	fn := Func0(func() error { return nil }).Build()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = fn()
+	}
+}
+
+func TestFuncR_UncheckedValueAllocs(t *testing.T) {
+	type page struct{ items [8]int }
+	fetch := FuncR(func() (page, error) { return page{}, nil }).Build()
+	ping := Func0(func() error { return nil }).Build()
+
+	fetchAllocs := testing.AllocsPerRun(100, func() { _, _ = fetch() })
+	pingAllocs := testing.AllocsPerRun(100, func() { _ = ping() })
+	if fetchAllocs > pingAllocs+1 {
+		t.Errorf("Expected values to skip post-condition checks when none are configured, got %v allocations against %v", fetchAllocs, pingAllocs)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	desc := Describe(CombinePolicies(
+		MaxAttempts(5),
+		WithBackoff(Exponential(100*time.Millisecond)),
+		Timeout(10*time.Second),
+	))
+
+	if desc.MaxAttempts != 5 || desc.Timeout != "10s" || desc.Matcher != MatcherAny {
+		t.Errorf("Unexpected description: %+v", desc)
+	}
+	if desc.Backoff.Type != "exponential" || desc.Backoff.Params["initial"] != "100ms" || desc.Backoff.Params["factor"] != "2" {
+		t.Errorf("Unexpected backoff description: %+v", desc.Backoff)
+	}
+
+	retrier := Func0(func() error { return nil }).
+		RetryIf(MatchErrors(ErrTemporary)).
+		WithMetrics("startup")
+	desc = retrier.Describe()
+	if desc.Matcher != "recur.MatchErrors.func1" || desc.Metrics != "startup" || desc.Backoff.Type != "constant" {
+		t.Errorf("Unexpected retrier description: %+v", desc)
+	}
+
+	data, err := json.Marshal(desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded PolicyDescription
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, desc) {
+		t.Errorf("Expected JSON round trip, got %+v (%v)", decoded, err)
+	}
+}
+
+func TestParseBackoff(t *testing.T) {
+	backoffs := []Backoff{
+		Constant(time.Second).(*ConstantBackoff).WithMinDelay(10 * time.Millisecond),
+		Exponential(100 * time.Millisecond).(*ExponentialBackoff).WithFactor(1.5).WithMaxDelay(time.Minute).WithFirstDelayExact(true),
+		Fibonacci(time.Millisecond),
+		Linear(time.Second, 500*time.Millisecond).(*LinearBackoff).WithMaxDelay(10 * time.Second),
+		Elapsed(time.Second, time.Minute, time.Hour),
+		Jitter(CapBackoff(Exponential(time.Second), 30*time.Second), 0.25),
+		NoDelay(),
+	}
+	for _, b := range backoffs {
+		desc := DescribeBackoff(b)
+		parsed, err := ParseBackoff(desc)
+		if err != nil {
+			t.Fatalf("Failed to parse %v: %v", desc, err)
+		}
+		if got := DescribeBackoff(parsed); !reflect.DeepEqual(got, desc) {
+			t.Errorf("Expected round trip of %v, got %v", desc, got)
+		}
+	}
+
+	for _, desc := range []BackoffDescription{
+		{Type: "constant"},
+		{Type: "exponential", Params: map[string]string{"initial": "soon"}},
+		{Type: "jitter", Params: map[string]string{"fraction": "0.5", "base": "unknown"}},
+		{Type: "custom"},
+	} {
+		if _, err := ParseBackoff(desc); err == nil {
+			t.Errorf("Expected an error parsing %v", desc)
+		}
+	}
+}
+
+func TestMatcherRegistry(t *testing.T) {
+	RegisterMatcher("test_temporary", MatchErrors(ErrTemporary))
+
+	matcher, err := ResolveMatcher("test_temporary", MatcherTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"registered", ErrTemporary, true},
+		{"built-in timeout", context.DeadlineExceeded, true},
+		{"wrapped timeout", &net.OpError{Op: "dial", Err: &net.DNSError{IsTimeout: true}}, true},
+		{"other", ErrFatal, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v for %v", tt.expected, got, tt.err)
+			}
+		})
+	}
+
+	if _, err := ResolveMatcher("no_such_matcher"); err == nil {
+		t.Error("Expected error for unknown matcher")
+	}
+	if !slices.Contains(RegisteredMatchers(), MatcherConnectionReset) {
+		t.Error("Expected built-in matchers to be registered")
+	}
+
+	timeout, _ := ResolveMatcher(MatcherTimeout)
+	if desc := Iter().RetryIf(timeout).Describe(); desc.Matcher != MatcherTimeout {
+		t.Errorf("Expected description to use the registered name, got %q", desc.Matcher)
+	}
+
+	RegisterMatcher("test_replaced", matchFirstVersion)
+	RegisterMatcher("test_replaced", matchSecondVersion)
+	if name, ok := registeredName(matchFirstVersion); ok {
+		t.Errorf("Expected a replaced matcher to lose its name, got %q", name)
+	}
+	if name, _ := registeredName(matchSecondVersion); name != "test_replaced" {
+		t.Errorf("Expected the replacement to be described by name, got %q", name)
+	}
+}
+
+func matchFirstVersion(error) bool { return false }
+
+func matchSecondVersion(error) bool { return true }
+
+// grpcStatus mimics a grpc status.Status with its codes.Code
+type grpcStatus struct{ code grpcStatusCode }
+
+type grpcStatusCode uint32
+
+func (s *grpcStatus) Code() grpcStatusCode { return s.code }
+
+type grpcError struct{ status *grpcStatus }
+
+func (e *grpcError) Error() string { return "rpc error" }
+
+func (e *grpcError) GRPCStatus() *grpcStatus { return e.status }
+
+// statusCoder mimics HTTP client errors exposing a status code
+type statusCoder int
+
+func (e statusCoder) Error() string   { return http.StatusText(int(e)) }
+func (e statusCoder) StatusCode() int { return int(e) }
+
+func TestProtocolMatchers(t *testing.T) {
+	reset := &url.Error{Op: "Get", URL: "http://api", Err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}}
+	tests := []struct {
+		matcher  string
+		err      error
+		expected bool
+	}{
+		{MatcherHTTPRetryable, &StatusError{Status: http.StatusServiceUnavailable}, true},
+		{MatcherHTTPRetryable, fmt.Errorf("fetch: %w", statusCoder(http.StatusTooManyRequests)), true},
+		{MatcherHTTPRetryable, statusCoder(http.StatusNotFound), false},
+		{MatcherHTTPRetryable, ErrTemporary, false},
+		{MatcherHTTPTransport, reset, true},
+		{MatcherHTTPTransport, &url.Error{Op: "Get", URL: "http://api", Err: io.EOF}, true},
+		{MatcherHTTPTransport, &url.Error{Op: "Get", URL: "http://api", Err: errors.New("unsupported protocol scheme")}, false},
+		{MatcherGRPCRetryable, &grpcError{&grpcStatus{code: 14}}, true},
+		{MatcherGRPCRetryable, fmt.Errorf("call: %w", &grpcError{&grpcStatus{code: 8}}), true},
+		{MatcherGRPCRetryable, &grpcError{&grpcStatus{code: 3}}, false},
+		{MatcherGRPCRetryable, &grpcError{}, false},
+		{MatcherGRPCUnavailable, errors.Join(ErrTemporary, &grpcError{&grpcStatus{code: 14}}), true},
+		{MatcherGRPCUnavailable, &grpcError{&grpcStatus{code: 10}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.matcher, func(t *testing.T) {
+			matcher, err := ResolveMatcher(tt.matcher)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := matcher(tt.err); got != tt.expected {
+				t.Errorf("Expected %v, got %v for %v", tt.expected, got, tt.err)
+			}
+		})
+	}
+}
+
+func TestRetrier_EventsCarryNameAndPolicy(t *testing.T) {
+	var events []RetryEvent
+	unregister := RegisterGlobalHook(func(ctx context.Context, e RetryEvent) {
+		if e.Operation == "charge_card" {
+			events = append(events, e)
+		}
+	})
+	defer unregister()
+
+	fn := Func0(func() error { return ErrTemporary }).
+		WithName("charge_card").
+		WithPolicy(CombinePolicies(MaxAttempts(2), WithBackoff(NoDelay()))).
+		Build()
+	_ = fn()
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	for _, e := range events {
+		if e.Policy.MaxAttempts != 2 || e.Policy.Backoff.Type != "none" || e.Policy.Name != "charge_card" {
+			t.Errorf("Unexpected policy on event: %+v", e.Policy)
+		}
+	}
+
+	if name := Iter().WithMetrics("fallback").Describe().Name; name != "fallback" {
+		t.Errorf("Expected metrics name as fallback, got %q", name)
+	}
+}
+
+func TestRetrier_WithMaxRetries(t *testing.T) {
+	calls := 0
+	err := Func0(func() error {
+		calls++
+		return ErrTemporary
+	}).WithMaxRetries(3).WithBackoff(NoDelay()).Build()()
+
+	if calls != 4 {
+		t.Errorf("Expected 4 total attempts for 3 retries, got %d", calls)
+	}
+	want := "max attempts exceeded after 4 total attempts (3 retries): temporary error"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected %q, got %v", want, err)
+	}
+	if desc := Describe(MaxRetries(2)); desc.MaxAttempts != 3 {
+		t.Errorf("Expected MaxRetries(2) to allow 3 attempts, got %d", desc.MaxAttempts)
+	}
+}
+
+func TestRetrier_PolicySelector(t *testing.T) {
+	type flagKey struct{}
+	experiment := Variant("single", MaxAttempts(1))
+
+	var calls int
+	fn := Func0(func() error {
+		calls++
+		return errors.New("boom")
+	}).WithMetrics("charge").
+		WithBackoff(Constant(0)).
+		WithPolicy(PolicySelector(func(ctx context.Context) Policy {
+			if ctx.Value(flagKey{}) != nil {
+				return experiment
+			}
+			return nil
+		}))
+	run := fn.BuildContext()
+
+	_ = run(context.Background())
+	if calls != 3 {
+		t.Errorf("Expected control policy to make 3 calls, got %d", calls)
+	}
+	calls = 0
+	_ = run(context.WithValue(context.Background(), flagKey{}, true))
+	if calls != 1 {
+		t.Errorf("Expected experimental policy to make 1 call, got %d", calls)
+	}
+
+	metrics := fn.Metrics()
+	if got := metrics.Snapshot().Attempts; got != 3 {
+		t.Errorf("Expected 3 control attempts, got %d", got)
+	}
+	variants := metrics.Variants()
+	if len(variants) != 1 {
+		t.Fatalf("Expected 1 variant collector, got %d", len(variants))
+	}
+	snap := variants[0].Snapshot()
+	if snap.Name != "charge" || snap.Variant != "single" || snap.Attempts != 1 || snap.Failures != 1 {
+		t.Errorf("Unexpected variant snapshot: %+v", snap)
+	}
+
+	var buf bytes.Buffer
+	if err := metrics.WriteOpenMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `recur_attempts_total{name="charge",variant="single"} 1`) {
+		t.Errorf("Expected variant series in output:\n%s", buf.String())
+	}
+}
+
+func TestRetrier_NegativeCache(t *testing.T) {
+	errNotFound := errors.New("not found")
+	var calls int
+	get := FuncR(func() (string, error) {
+		calls++
+		return "", Fatal(errNotFound)
+	}).WithNegativeCache(time.Minute).BuildContext()
+
+	ctx := ContextWithCacheKey(context.Background(), "user:1")
+	if _, err := get(ctx); !errors.Is(err, errNotFound) {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+	_, err := get(ctx)
+	if calls != 1 {
+		t.Errorf("Expected cached failure to skip the call, got %d calls", calls)
+	}
+	if !errors.Is(err, ErrNegativeCached) || !errors.Is(err, errNotFound) || ErrorCode(err) != CodeNegativeCached {
+		t.Errorf("Expected cached not found error, got %v", err)
+	}
+
+	if _, err := get(ContextWithCacheKey(context.Background(), "user:2")); errors.Is(err, ErrNegativeCached) {
+		t.Errorf("Expected other keys to bypass the cache, got %v", err)
+	}
+	if _, err := get(context.Background()); errors.Is(err, ErrNegativeCached) {
+		t.Errorf("Expected calls without a key to bypass the cache, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestNegativeCache_Expiry(t *testing.T) {
+	now := time.Now()
+	c := newNegativeCache(time.Second)
+	c.now = func() time.Time { return now }
+
+	c.put("k", ErrFatal)
+	if _, ok := c.get("k"); !ok {
+		t.Fatal("Expected cached failure")
+	}
+	now = now.Add(time.Second)
+	if _, ok := c.get("k"); ok {
+		t.Error("Expected failure to expire after ttl")
+	}
+}
+
+func TestDoWhile(t *testing.T) {
+	errEmpty := errors.New("empty")
+	queue := 5
+	n, err := DoWhile(context.Background(), func(ctx context.Context) error {
+		if queue == 0 {
+			return errEmpty
+		}
+		queue--
+		return nil
+	}, WithBackoff(NoDelay()))
+	if n != 5 || !errors.Is(err, errEmpty) {
+		t.Errorf("Expected 5 successes then empty, got %d, %v", n, err)
+	}
+
+	n, err = DoWhile(context.Background(), func(ctx context.Context) error {
+		return nil
+	}, MaxAttempts(3), WithBackoff(NoDelay()))
+	if n != 3 || err != nil {
+		t.Errorf("Expected to stop after 3 attempts without error, got %d, %v", n, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n, err = DoWhile(ctx, func(ctx context.Context) error {
+		cancel()
+		return nil
+	}, WithBackoff(Constant(time.Hour)))
+	if n != 1 || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation after 1 success, got %d, %v", n, err)
+	}
+
+	n, err = DoWhile(context.Background(), func(ctx context.Context) error {
+		return nil
+	}, Timeout(20*time.Millisecond), WithBackoff(Constant(5*time.Millisecond)))
+	if n == 0 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the timeout policy to end the loop with its error, got %d, %v", n, err)
+	}
+
+	SetKillSwitch(true)
+	defer SetKillSwitch(false)
+	n, err = DoWhile(context.Background(), func(ctx context.Context) error {
+		return nil
+	}, WithBackoff(NoDelay()))
+	if n != 1 || err != ErrKillSwitch {
+		t.Errorf("Expected the kill-switch to end the loop after the first call, got %d, %v", n, err)
+	}
+}
+
+func TestPostCondition(t *testing.T) {
+	errEmpty := errors.New("empty page")
+	var calls int
+	var checked int
+	fetch := FuncR(func() ([]string, error) {
+		calls++
+		if calls < 3 {
+			return nil, nil
+		}
+		return []string{"a"}, nil
+	}, func(v []string) error {
+		if len(v) == 0 {
+			return errEmpty
+		}
+		return nil
+	}, func([]string) error {
+		checked++
+		return nil
+	}).
+		WithMaxAttempts(5).
+		WithBackoff(NoDelay()).
+		RetryIf(func(error) bool { return false }).
+		Build()
+
+	v, err := fetch()
+	if err != nil || !slices.Equal(v, []string{"a"}) {
+		t.Fatalf("Expected value passing the post-condition, got %v, %v", v, err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected violations retried despite RetryIf, got %d calls", calls)
+	}
+	if checked != 1 {
+		t.Errorf("Expected later conditions checked only after earlier ones pass, got %d checks", checked)
+	}
+}
+
+func TestPostCondition_Fatal(t *testing.T) {
+	errInvalid := errors.New("negative balance")
+	var calls int
+	v, err := FuncR(func() (int, error) {
+		calls++
+		return -1, nil
+	}, func(v int) error {
+		if v < 0 {
+			return Fatal(errInvalid)
+		}
+		return nil
+	}).
+		WithBackoff(NoDelay()).
+		Build()()
+
+	var violation *PostConditionError
+	if !errors.As(err, &violation) || !errors.Is(err, errInvalid) || violation.Value != -1 {
+		t.Errorf("Expected post-condition error, got %v", err)
+	}
+	if v != 0 || calls != 1 {
+		t.Errorf("Expected zero value after a single attempt, got %d after %d calls", v, calls)
+	}
+}
+
+func TestRunBestEffort(t *testing.T) {
+	var calls int
+	v, err := RunBestEffort(context.Background(), func(ctx context.Context) ([]int, error) {
+		calls++
+		return slices.Repeat([]int{calls}, calls), ErrTemporary
+	}, MaxAttempts(3), WithBackoff(NoDelay()))
+
+	if !errors.Is(err, ErrTemporary) {
+		t.Errorf("Expected final error, got %v", err)
+	}
+	if !slices.Equal(v, []int{3, 3, 3}) {
+		t.Errorf("Expected value from the last attempt, got %v", v)
+	}
+
+	v, err = RunBestEffort(context.Background(), func(ctx context.Context) ([]int, error) {
+		return []int{1}, nil
+	})
+	if err != nil || !slices.Equal(v, []int{1}) {
+		t.Errorf("Expected value on success, got %v, %v", v, err)
+	}
+}
+
+func TestWithRetryOnZeroValue(t *testing.T) {
+	var calls int
+	lookup := Func1R(func(id string) (map[string]string, error) {
+		calls++
+		if calls < 3 {
+			return map[string]string{}, nil // Not replicated yet
+		}
+		return map[string]string{"id": id}, nil
+	}).
+		WithMaxAttempts(5).
+		WithBackoff(NoDelay()).
+		WithRetryOnZeroValue(true).
+		Build()
+
+	v, err := lookup("42")
+	if err != nil || v["id"] != "42" || calls != 3 {
+		t.Errorf("Expected empty results retried, got %v, %v after %d calls", v, err, calls)
+	}
+
+	v2, err := FuncR(func() (*int, error) { return nil, nil }).
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		WithRetryOnZeroValue(true).
+		Build()()
+	if v2 != nil || !errors.Is(err, ErrZeroValue) {
+		t.Errorf("Expected ErrZeroValue after exhausting attempts, got %v, %v", v2, err)
+	}
+
+	n, err := FuncR(func() (int, error) { return 0, nil }).Build()()
+	if n != 0 || err != nil {
+		t.Errorf("Expected zero values accepted by default, got %d, %v", n, err)
+	}
+}
+
+func TestContextWithPolicyOverride(t *testing.T) {
+	var calls int
+	fn := Func0(func() error {
+		calls++
+		return ErrTemporary
+	}).WithMaxAttempts(5).WithBackoff(NoDelay()).BuildContext()
+
+	ctx := ContextWithPolicyOverride(context.Background(), MaxAttempts(3))
+	ctx = ContextWithPolicyOverride(ctx, MaxAttempts(2))
+	if err := fn(ctx); !errors.Is(err, ErrTemporary) {
+		t.Fatalf("Expected final error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the innermost override to apply, got %d calls", calls)
+	}
+
+	calls = 0
+	_ = fn(context.Background())
+	if calls != 5 {
+		t.Errorf("Expected the retrier's own configuration without an override, got %d calls", calls)
+	}
+}
+
+func TestWithWarmup(t *testing.T) {
+	defer func(start time.Time) { processStart = start }(processStart)
+
+	var calls int
+	fn := Func0(func() error {
+		calls++
+		return ErrTemporary
+	}).WithMaxAttempts(5).WithBackoff(NoDelay()).WithWarmup(time.Minute, MaxAttempts(2)).Build()
+
+	processStart = time.Now()
+	_ = fn()
+	if calls != 2 {
+		t.Errorf("Expected the reduced policy during warm-up, got %d calls", calls)
+	}
+
+	calls = 0
+	processStart = time.Now().Add(-time.Hour)
+	_ = fn()
+	if calls != 5 {
+		t.Errorf("Expected the full policy after warm-up, got %d calls", calls)
+	}
+}
+
+func TestWithErrorEnricher(t *testing.T) {
+	var hooked []string
+	err := Func0(func() error { return ErrTemporary }).
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		RetryIf(MatchErrors(ErrTemporary)).
+		WithErrorEnricher(func(_ context.Context, attempt int, err error) error {
+			return fmt.Errorf("orders-api attempt %d: %w", attempt, err)
+		}).
+		OnRetry(func(_ context.Context, e RetryEvent) {
+			hooked = append(hooked, e.Err.Error())
+		}).
+		Build()()
+
+	if !errors.Is(err, ErrTemporary) || !strings.Contains(err.Error(), "orders-api attempt 2") {
+		t.Errorf("Expected the enriched final error, got %v", err)
+	}
+	if len(hooked) != 2 || hooked[0] != "orders-api attempt 1: temporary error" {
+		t.Errorf("Expected hooks to see enriched errors, got %q", hooked)
+	}
+
+	var calls int
+	_ = Func0(func() error {
+		calls++
+		return ErrFatal
+	}).WithMaxAttempts(3).RetryIf(MatchErrors(ErrTemporary)).WithPolicy(EnrichErrors(func(_ context.Context, _ int, err error) error {
+		return fmt.Errorf("enriched: %w", err)
+	})).Build()()
+	if calls != 1 {
+		t.Errorf("Expected the matcher to see through enrichment, got %d calls", calls)
+	}
+}
+
+func TestWithTimeoutMode(t *testing.T) {
+	cases := []struct {
+		mode   TimeoutMode
+		parent time.Duration
+		min    time.Duration
+		max    time.Duration
+	}{
+		{TimeoutMin, time.Minute, 59 * time.Second, time.Minute},
+		{TimeoutInherit, time.Minute, 59 * time.Second, time.Minute},
+		{TimeoutOverride, time.Minute, 59 * time.Minute, time.Hour},
+		{TimeoutMin, 2 * time.Hour, 59 * time.Minute, time.Hour},
+		{TimeoutInherit, 2 * time.Hour, 119 * time.Minute, 2 * time.Hour},
+	}
+	for _, tt := range cases {
+		ctx, cancel := context.WithTimeout(context.Background(), tt.parent)
+		var remaining time.Duration
+		for attempt := range Iter().WithTimeout(time.Hour).WithTimeoutMode(tt.mode).seq(ctx, nil) {
+			d, _ := attempt.Deadline()
+			remaining = time.Until(d)
+			attempt.Result(nil)
+		}
+		cancel()
+		if remaining < tt.min || remaining > tt.max {
+			t.Errorf("%v with a %v caller deadline: expected %v to %v left, got %v", tt.mode, tt.parent, tt.min, tt.max, remaining)
+		}
+	}
+
+	t.Run("override keeps cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		fn := Func0(func() error { return ErrTemporary }).
+			WithTimeout(time.Hour).
+			WithTimeoutMode(TimeoutOverride).
+			WithBackoff(Constant(time.Hour)).
+			BuildContext()
+		time.AfterFunc(10*time.Millisecond, cancel)
+		if err := fn(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the caller's cancellation to end the cycle, got %v", err)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		var calls int
+		fn := Func0(func() error {
+			calls++
+			return nil
+		}).WithTimeout(time.Hour).WithTimeoutMode(TimeoutStrict).BuildContext()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		err := fn(ctx)
+		if !errors.Is(err, ErrTimeoutConflict) || ErrorCode(err) != CodeTimeoutConflict || calls != 0 {
+			t.Errorf("Expected a conflict without attempts, got %v after %d calls", err, calls)
+		}
+		if err := fn(context.Background()); err != nil || calls != 1 {
+			t.Errorf("Expected no conflict without a caller deadline, got %v", err)
+		}
+
+		var seqErr error
+		for attempt := range Iter().WithContext(ctx).WithTimeout(time.Hour).WithTimeoutMode(TimeoutStrict).Seq() {
+			if seqErr = attempt.LastErr; seqErr == nil {
+				calls++
+			}
+			attempt.Result(seqErr)
+		}
+		if !errors.Is(seqErr, ErrTimeoutConflict) || calls != 1 {
+			t.Errorf("Expected Seq to see the conflict without a call, got %v after %d calls", seqErr, calls)
+		}
+	})
+}
+
+func TestBehaviorVersion(t *testing.T) {
+	t.Run("attempt counting", func(t *testing.T) {
+		for _, tt := range []struct {
+			version BehaviorVersion
+			calls   int
+		}{{V1, 0}, {V2, 1}} {
+			var calls int
+			_ = Func0(func() error {
+				calls++
+				return nil
+			}).WithMaxAttempts(0).WithPolicy(Behavior(tt.version)).Build()()
+			if calls != tt.calls {
+				t.Errorf("V%d: expected %d calls with max attempts 0, got %d", tt.version, tt.calls, calls)
+			}
+		}
+	})
+
+	t.Run("first delay", func(t *testing.T) {
+		for _, tt := range []struct {
+			version BehaviorVersion
+			backoff Backoff
+			want    time.Duration
+		}{
+			{V1, Exponential(time.Millisecond), 2 * time.Millisecond},
+			{V2, Exponential(time.Millisecond), time.Millisecond},
+			{V2, CapBackoff(Linear(time.Millisecond, time.Millisecond), time.Second), time.Millisecond},
+			{V2, Exponential(time.Millisecond).(*ExponentialBackoff).WithFirstDelayExact(false), 2 * time.Millisecond},
+		} {
+			var delay time.Duration
+			_ = Func0(func() error { return ErrTemporary }).
+				WithMaxAttempts(2).
+				WithBackoff(tt.backoff).
+				WithPolicy(Behavior(tt.version)).
+				OnRetry(func(_ context.Context, e RetryEvent) {
+					if e.WillRetry {
+						delay = e.NextDelay
+					}
+				}).
+				Build()()
+			if delay != tt.want {
+				t.Errorf("V%d %s: expected first delay %v, got %v", tt.version, DescribeBackoff(tt.backoff), tt.want, delay)
+			}
+		}
+	})
+
+	t.Run("resolved per cycle", func(t *testing.T) {
+		backoff := Exponential(time.Millisecond).(*ExponentialBackoff)
+		b := Iter().WithBehaviorVersion(V2).WithBackoff(backoff)
+		if b.selectPolicy(context.Background()) != b {
+			t.Error("Expected V2 builders to run without a per-cycle copy")
+		}
+		state := &iteratorState{builder: b}
+		if delay := state.nextDelay(1); delay != time.Millisecond {
+			t.Errorf("Expected an exact first delay, got %v", delay)
+		}
+		backoff.WithFactor(3).WithMaxDelay(time.Second)
+		if delay := state.nextDelay(2); delay != 3*time.Millisecond {
+			t.Errorf("Expected changes to the backoff to apply under V2, got %v", delay)
+		}
+		b.WithBehaviorVersion(V1)
+		if delay := state.nextDelay(1); delay != 3*time.Millisecond {
+			t.Errorf("Expected V1 first delay after switching back, got %v", delay)
+		}
+	})
+
+	t.Run("context error passthrough", func(t *testing.T) {
+		errStop := errors.New("shutting down")
+		for _, version := range []BehaviorVersion{V1, V2} {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			err := Func0(func() error {
+				cancel(errStop)
+				return ErrTemporary
+			}).WithPolicy(Behavior(version)).BuildContext()(ctx)
+
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("V%d: expected context error, got %v", version, err)
+			}
+			wrapsAttempt := errors.Is(err, ErrTemporary) && errors.Is(err, errStop)
+			if wrapsAttempt != (version == V2) {
+				t.Errorf("V%d: unexpected final error %v", version, err)
+			}
+		}
+	})
+}
+
+func TestWithIsolatedAttempts(t *testing.T) {
+	release := make(chan struct{})
+
+	var calls atomic.Int32
+	start := time.Now()
+	v, err := FuncR(func() (int, error) {
+		n := calls.Add(1)
+		if n == 1 {
+			<-release // Ignores cancellation
+		}
+		return int(n), nil
+	}).
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		WithAttemptTimeout(10 * time.Millisecond).
+		WithIsolatedAttempts(true).
+		WithMetrics("isolated").
+		Build()()
+
+	if err != nil || v != 2 {
+		t.Fatalf("Expected second attempt to succeed, got %d, %v", v, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the hung attempt cut off on time, took %v", elapsed)
+	}
+	if AbandonedOperations() < 1 {
+		t.Error("Expected the hung attempt tracked as abandoned")
+	}
+
+	close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := WaitAbandoned(ctx); err != nil {
+		t.Errorf("Expected the hung attempt's goroutine to be joined once it returns, got %v", err)
+	}
+}
+
+func TestWithIsolatedAttempts_CancellationRespected(t *testing.T) {
+	errStopped := errors.New("stopped")
+	metrics := NewMetricsCollector("respectful")
+	engine := NewEngine(nil, MaxAttempts(1), AttemptTimeout(time.Millisecond), IsolatedAttempts(true)).
+		WithPolicy(func(b *IteratorBuilder) { b.WithMetricsCollector(metrics) })
+
+	for range 100 {
+		err := engine.Run(context.Background(), func(ctx context.Context) error {
+			<-ctx.Done()
+			return errStopped
+		})
+		if IsAbandoned(err) || !errors.Is(err, errStopped) {
+			t.Fatalf("Expected the operation's own error, got %v", err)
+		}
+	}
+	if n := metrics.AbandonedCount.Load(); n != 0 {
+		t.Errorf("Expected no abandoned operations, got %d", n)
+	}
+}
+
+func TestWithAttemptTimeout(t *testing.T) {
+	var deadlines int
+	for attempt := range Iter().WithMaxAttempts(2).WithBackoff(NoDelay()).WithPolicy(AttemptTimeout(time.Millisecond)).Seq() {
+		<-attempt.Context().Done()
+		if errors.Is(attempt.Context().Err(), context.DeadlineExceeded) {
+			deadlines++
+		}
+		attempt.Result(attempt.Context().Err())
+	}
+	if deadlines != 2 {
+		t.Errorf("Expected each attempt to time out on its own, got %d", deadlines)
+	}
+}
+
+func TestWithPreflight(t *testing.T) {
+	errOffline := errors.New("offline")
+	var checks, calls int
+	err := Func0(func() error {
+		calls++
+		if calls < 2 {
+			return ErrTemporary
+		}
+		return nil
+	}).
+		WithMaxAttempts(2).
+		WithBackoff(NoDelay()).
+		WithPreflight(func(ctx context.Context) error {
+			checks++
+			if checks <= 3 {
+				return errOffline
+			}
+			return nil
+		}).
+		WithPreflightBackoff(NoDelay()).
+		Build()()
+
+	if err != nil {
+		t.Fatalf("Expected failed preflights not to use up attempts, got %v", err)
+	}
+	if checks != 5 || calls != 2 {
+		t.Errorf("Expected 5 checks and 2 calls, got %d and %d", checks, calls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = Func0(func() error {
+		t.Error("Expected no attempt while preflight fails")
+		return nil
+	}).
+		WithPolicy(Preflight(func(ctx context.Context) error { return errOffline })).
+		BuildContext()(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context error while preflight fails, got %v", err)
+	}
+}
+
+func TestRetrier_Attempts(t *testing.T) {
+	retrier := Func0(func() error {
+		t.Error("Expected the wrapped function not to be called")
+		return nil
+	}).WithMaxAttempts(4).WithBackoff(NoDelay()).WithMetrics("attempts")
+
+	var numbers []int
+	for attempt := range retrier.Attempts() {
+		numbers = append(numbers, attempt.Number)
+		attempt.Result(ErrTemporary)
+	}
+	if !slices.Equal(numbers, []int{1, 2, 3, 4}) {
+		t.Errorf("Expected the retrier's max attempts, got %v", numbers)
+	}
+	if snap := retrier.Metrics().Snapshot(); snap.Attempts != 4 {
+		t.Errorf("Expected attempts recorded in the retrier's metrics, got %+v", snap)
+	}
+}
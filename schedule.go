@@ -0,0 +1,70 @@
+package recur
+
+import "time"
+
+// SchedulingMode selects how backoff delays are measured between attempts
+type SchedulingMode int
+
+const (
+	// FixedDelay waits the backoff delay after each attempt ends, so slow
+	// attempts push later ones back. This is the default.
+	FixedDelay SchedulingMode = iota
+
+	// FixedRate schedules each attempt the backoff delay after the previous
+	// attempt was scheduled to start, regardless of how long attempts take.
+	// Lateness from slow attempts, gates or oversleeping is made up on the
+	// next wait, so a Constant backoff keeps a steady rate without drift.
+	// An attempt that overruns its slot is followed immediately and the
+	// schedule restarts from there rather than bursting to catch up.
+	FixedRate
+)
+
+// String returns the mode's name
+func (m SchedulingMode) String() string {
+	switch m {
+	case FixedDelay:
+		return "fixed-delay"
+	case FixedRate:
+		return "fixed-rate"
+	default:
+		return "unknown"
+	}
+}
+
+// WithSchedulingMode selects how delays between attempts are measured, see
+// SchedulingMode. RateLimited hints and WithMinDelay still set a lower
+// bound on the wait, and Attempt.SetNextDelay replaces it outright, subject
+// only to WithMinDelay. The FixedRate schedule then continues from when the
+// delayed attempt starts.
+func (b *IteratorBuilder) WithSchedulingMode(mode SchedulingMode) *IteratorBuilder {
+	b.scheduling = mode
+	return b
+}
+
+// Scheduling returns a policy selecting the scheduling mode, see
+// SchedulingMode
+func Scheduling(mode SchedulingMode) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithSchedulingMode(mode)
+	}
+}
+
+// WithSchedulingMode selects how delays between attempts are measured, see
+// SchedulingMode
+func (r *Retrier[F, C]) WithSchedulingMode(mode SchedulingMode) *Retrier[F, C] {
+	r.config.WithSchedulingMode(mode)
+	return r
+}
+
+// WithSchedulingMode selects how delays between attempts are measured, see
+// SchedulingMode
+func (e *Engine) WithSchedulingMode(mode SchedulingMode) *Engine {
+	e.config.WithSchedulingMode(mode)
+	return e
+}
+
+// untilSlot returns the wait until delay after the previous attempt's
+// scheduled start
+func (s *iteratorState) untilSlot(delay time.Duration) time.Duration {
+	return max(time.Until(s.scheduled.Add(delay)), 0)
+}
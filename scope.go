@@ -0,0 +1,89 @@
+package recur
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// attemptScope tracks subtasks started from a single attempt
+type attemptScope struct {
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+// Go runs fn in a new goroutine scoped to this attempt, errgroup-style.
+// The context passed to fn is canceled when any subtask returns an error,
+// when Result is called with a non-nil error, or when the iterator moves
+// past this attempt, with the subtask's error, ErrAttemptFailed or
+// ErrAttemptFinished as its cause. The iterator waits for all subtasks of
+// an attempt to return before starting the next one, so abandoned attempts
+// never leak goroutines. Subtasks must therefore honor ctx cancellation.
+func (a *Attempt) Go(fn func(ctx context.Context) error) {
+	scope := a.subtasks()
+	scope.wg.Add(1)
+	go func() {
+		defer scope.wg.Done()
+		if err := fn(scope.ctx); err != nil {
+			scope.errOnce.Do(func() {
+				scope.err = err
+				scope.cancel(err)
+			})
+		}
+	}()
+}
+
+// Wait blocks until all subtasks started with Go have returned and
+// returns the first non-nil error, if any. Typical usage:
+//
+//	attempt.Result(attempt.Wait())
+func (a *Attempt) Wait() error {
+	a.scopeMu.Lock()
+	scope := a.scope
+	a.scopeMu.Unlock()
+
+	if scope == nil {
+		return nil
+	}
+	scope.wg.Wait()
+	return scope.err
+}
+
+// subtasks returns the attempt's scope, creating it on first use
+func (a *Attempt) subtasks() *attemptScope {
+	a.scopeMu.Lock()
+	defer a.scopeMu.Unlock()
+
+	if a.scope == nil {
+		ctx, cancel := context.WithCancelCause(a.ctx)
+		a.scope = &attemptScope{ctx: ctx, cancel: cancel}
+	}
+	return a.scope
+}
+
+// cancelSubtasks cancels any running subtasks because the attempt failed
+// with err, without waiting for them
+func (a *Attempt) cancelSubtasks(err error) {
+	a.scopeMu.Lock()
+	scope := a.scope
+	a.scopeMu.Unlock()
+
+	if scope != nil {
+		scope.cancel(fmt.Errorf("%w: %w", ErrAttemptFailed, err))
+	}
+}
+
+// closeSubtasks cancels any running subtasks and waits for them to return
+func (a *Attempt) closeSubtasks() {
+	a.scopeMu.Lock()
+	scope := a.scope
+	a.scopeMu.Unlock()
+
+	if scope != nil {
+		scope.cancel(ErrAttemptFinished)
+		scope.wg.Wait()
+	}
+}
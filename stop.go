@@ -0,0 +1,71 @@
+package recur
+
+import "context"
+
+// AttemptContext wraps an attempt's context for operations that loop
+// internally, such as polling a channel or paging through results, so they
+// can check between steps whether the retry engine wants the attempt to end.
+// The engine cancels the context when the cycle's timeout expires, its
+// lifecycle context shuts down or the caller's context is canceled.
+//
+// Example:
+//
+//	ctx := attempt.AttemptContext()
+//	for !ctx.StopRequested() {
+//	    if done := poll(ctx); done {
+//	        break
+//	    }
+//	}
+type AttemptContext struct {
+	context.Context
+}
+
+// StopRequested reports whether the attempt should stop. Operations should
+// return promptly, typically with context.Cause(ctx), once it is true.
+func (c AttemptContext) StopRequested() bool {
+	return c.Err() != nil
+}
+
+// AttemptContext returns the attempt's context with StopRequested
+func (a *Attempt) AttemptContext() AttemptContext {
+	return AttemptContext{a.ctx}
+}
+
+// StopRequested reports whether the retry engine wants this attempt to stop
+func (a *Attempt) StopRequested() bool {
+	return a.ctx.Err() != nil
+}
+
+// Select receives from ch unless ctx is done first. It returns the value
+// and whether it was sent, like a two-value receive, or the context's cause
+// if the attempt should stop.
+//
+// Example:
+//
+//	for {
+//	    ev, ok, err := recur.Select(ctx, events)
+//	    if err != nil || !ok {
+//	        return err
+//	    }
+//	    handle(ev)
+//	}
+func Select[T any](ctx context.Context, ch <-chan T) (T, bool, error) {
+	select {
+	case v, ok := <-ch:
+		return v, ok, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, false, context.Cause(ctx)
+	}
+}
+
+// SelectSend sends v on ch unless ctx is done first, in which case it
+// returns the context's cause
+func SelectSend[T any](ctx context.Context, ch chan<- T, v T) error {
+	select {
+	case ch <- v:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
@@ -0,0 +1,138 @@
+package recur
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrKillSwitch is reported when retrying stopped because the global
+// kill-switch was engaged
+var ErrKillSwitch error = &codedError{code: CodeKillSwitch, msg: "retries disabled by kill-switch"}
+
+// killSwitch disables retries in every iterator while engaged
+var killSwitch atomic.Bool
+
+// SetKillSwitch engages or releases the global soft kill-switch. While
+// engaged, iterators still make their first attempt but never retry.
+func SetKillSwitch(engaged bool) {
+	killSwitch.Store(engaged)
+}
+
+// KillSwitchEngaged reports whether the global kill-switch is engaged
+func KillSwitchEngaged() bool {
+	return killSwitch.Load()
+}
+
+// StormAlarm describes a detected retry storm
+type StormAlarm struct {
+	Retries int64   // Retries observed since the previous check
+	Cycles  int64   // Retry cycles (primary operations) completed since the previous check
+	Ratio   float64 // Retries per cycle
+	Limit   float64 // Configured threshold
+}
+
+// StormDetector watches registered metrics collectors and raises an alarm
+// when retries exceed a configured multiple of primary traffic
+type StormDetector struct {
+	mu         sync.Mutex
+	threshold  float64
+	collectors []*MetricsCollector
+	last       map[*MetricsCollector][2]int64
+	onAlarm    func(StormAlarm)
+	trip       bool
+	tripped    bool
+}
+
+// NewStormDetector creates a detector alarming when retries per completed
+// cycle exceed threshold, e.g. 0.5 allows one retry for every two operations
+func NewStormDetector(threshold float64) *StormDetector {
+	return &StormDetector{
+		threshold: threshold,
+		last:      make(map[*MetricsCollector][2]int64),
+	}
+}
+
+// Register adds collectors to watch
+func (d *StormDetector) Register(collectors ...*MetricsCollector) *StormDetector {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, m := range collectors {
+		d.collectors = append(d.collectors, m)
+		d.last[m] = [2]int64{m.TotalRetries.Load(), m.TotalAttempts.Load()}
+	}
+	return d
+}
+
+// OnAlarm sets the function called whenever a check detects a storm
+func (d *StormDetector) OnAlarm(fn func(StormAlarm)) *StormDetector {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onAlarm = fn
+	return d
+}
+
+// WithKillSwitch makes the detector engage the global kill-switch while a
+// storm is detected and release it once the retry rate recovers
+func (d *StormDetector) WithKillSwitch(enabled bool) *StormDetector {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.trip = enabled
+	return d
+}
+
+// Check compares retries and completed cycles since the previous check
+// and reports whether they indicate a storm
+func (d *StormDetector) Check() (StormAlarm, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	alarm := StormAlarm{Limit: d.threshold}
+	for _, m := range d.collectors {
+		retries, cycles := m.TotalRetries.Load(), m.TotalAttempts.Load()
+		prev := d.last[m]
+		alarm.Retries += retries - prev[0]
+		alarm.Cycles += cycles - prev[1]
+		d.last[m] = [2]int64{retries, cycles}
+	}
+
+	if alarm.Retries > 0 {
+		alarm.Ratio = float64(alarm.Retries) / float64(max(alarm.Cycles, 1))
+	}
+	storm := alarm.Ratio > d.threshold
+
+	if d.trip && storm != d.tripped {
+		SetKillSwitch(storm)
+		d.tripped = storm
+	}
+	if storm && d.onAlarm != nil {
+		d.onAlarm(alarm)
+	}
+	return alarm, storm
+}
+
+// Run calls Check every interval until ctx is done, releasing the
+// kill-switch on exit if the detector engaged it
+func (d *StormDetector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.Check()
+		case <-ctx.Done():
+			d.mu.Lock()
+			if d.tripped {
+				SetKillSwitch(false)
+				d.tripped = false
+			}
+			d.mu.Unlock()
+			return
+		}
+	}
+}
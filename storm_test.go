@@ -0,0 +1,73 @@
+package recur
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStormDetector(t *testing.T) {
+	builder := Iter().WithMaxAttempts(3).WithBackoff(NoDelay()).WithMetrics("storm")
+
+	var alarms []StormAlarm
+	detector := NewStormDetector(1.0).
+		Register(builder.Metrics()).
+		OnAlarm(func(a StormAlarm) { alarms = append(alarms, a) }).
+		WithKillSwitch(true)
+	defer SetKillSwitch(false)
+
+	// Every cycle fails twice before succeeding: 2 retries per cycle
+	for i := 0; i < 5; i++ {
+		for attempt := range builder.Seq() {
+			if attempt.Number < 3 {
+				attempt.Result(ErrTemporary)
+				continue
+			}
+			attempt.Result(nil)
+		}
+	}
+
+	alarm, storm := detector.Check()
+	if !storm || alarm.Ratio != 2 || alarm.Cycles != 5 {
+		t.Fatalf("Expected storm at 2 retries per cycle, got %+v", alarm)
+	}
+	if len(alarms) != 1 {
+		t.Errorf("Expected 1 alarm, got %d", len(alarms))
+	}
+	if !KillSwitchEngaged() {
+		t.Fatal("Expected kill-switch to be engaged")
+	}
+
+	// With the kill-switch engaged, iterators only make a first attempt
+	counter := 0
+	for attempt := range builder.Seq() {
+		counter++
+		attempt.Result(ErrTemporary)
+	}
+	if counter != 1 {
+		t.Errorf("Expected 1 attempt while kill-switch engaged, got %d", counter)
+	}
+
+	if _, storm := detector.Check(); storm {
+		t.Error("Expected retry rate to have recovered")
+	}
+	if KillSwitchEngaged() {
+		t.Error("Expected kill-switch to be released after recovery")
+	}
+}
+
+func TestStormDetector_ConfigureWhileRunning(t *testing.T) {
+	detector := NewStormDetector(1.0)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		detector.Run(ctx, time.Microsecond)
+	}()
+
+	for i := 0; i < 100; i++ {
+		detector.OnAlarm(func(StormAlarm) {}).WithKillSwitch(i%2 == 0)
+	}
+	cancel()
+	<-done
+}
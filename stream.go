@@ -0,0 +1,134 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Receiver is a stream of messages, such as a gRPC client stream or a
+// server-sent events reader. Recv returns io.EOF when the stream completes.
+// Receivers implementing io.Closer are closed when they fail.
+type Receiver[M any] interface {
+	Recv() (M, error)
+}
+
+// StreamRetrier keeps a long-running stream alive, re-establishing it with
+// the configured policy when it fails and resuming after the last message
+// that was handled successfully. Backoff applies only to re-establishing
+// the stream; once a re-established stream delivers a message, the attempt
+// budget starts over, after the backoff's first delay if the stream fails
+// again. Errors the policy doesn't retry end Run even after progress.
+type StreamRetrier[M any] struct {
+	config *IteratorBuilder
+	resume func(ctx context.Context, lastToken string) (Receiver[M], error)
+	token  func(M) string
+	gate   Gate
+}
+
+// NewStreamRetrier creates a stream retrier. resume opens the stream
+// continuing after lastToken, which is empty on the first call; token
+// extracts the resume token from a handled message.
+//
+// Example:
+//
+//	sr := recur.NewStreamRetrier(
+//	    func(ctx context.Context, last string) (recur.Receiver[*pb.Event], error) {
+//	        return client.Watch(ctx, &pb.WatchRequest{ResumeAfter: last})
+//	    },
+//	    func(e *pb.Event) string { return e.Id },
+//	).WithPolicy(recur.WithBackoff(recur.Exponential(time.Second)))
+//
+//	err := sr.Run(ctx, func(e *pb.Event) error { return apply(e) })
+func NewStreamRetrier[M any](resume func(ctx context.Context, lastToken string) (Receiver[M], error), token func(M) string) *StreamRetrier[M] {
+	s := &StreamRetrier[M]{
+		config: Iter(),
+		resume: resume,
+		token:  token,
+	}
+	s.config.WithGate(&s.gate)
+	return s
+}
+
+// WithPolicy applies a policy to stream re-establishment
+func (s *StreamRetrier[M]) WithPolicy(policy Policy) *StreamRetrier[M] {
+	s.config.WithPolicy(policy)
+	return s
+}
+
+// Run receives messages and passes them to handle until the stream completes
+// with io.EOF, ctx is done, handle returns an error, or re-establishing the
+// stream fails per the policy. Handler errors are returned as-is without
+// retrying, since the message would be delivered again on resume.
+func (s *StreamRetrier[M]) Run(ctx context.Context, handle func(M) error) error {
+	var lastToken string
+	config := s.config
+
+	for {
+		var final, stop error
+		done, progressed := false, false
+
+		for attempt := range config.seq(ctx, &final) {
+			recv, err := s.resume(attempt.Context(), lastToken)
+			if err != nil {
+				attempt.Result(err)
+				continue
+			}
+
+			for {
+				msg, err := recv.Recv()
+				if errors.Is(err, io.EOF) {
+					closeReceiver(recv)
+					done = true
+					break
+				}
+				if err != nil {
+					closeReceiver(recv)
+					attempt.Result(err)
+					if progressed && config.failFast && !attempt.Retryable(err) {
+						progressed = false // End the cycle with the classified error
+					}
+					break
+				}
+				if err := handle(msg); err != nil {
+					closeReceiver(recv)
+					done, stop = true, err
+					break
+				}
+				lastToken = s.token(msg)
+				progressed = true
+			}
+
+			if done || progressed {
+				break
+			}
+		}
+
+		switch {
+		case done:
+			return stop
+		case !progressed:
+			return final
+		}
+		// The stream made progress before failing: start over with a fresh
+		// budget whose first attempt waits out the policy's first delay, so
+		// a flapping stream doesn't reconnect in a tight loop
+		if !config.resumed {
+			config = s.resumed()
+		}
+	}
+}
+
+// resumed returns the configuration of cycles following progress, which
+// wait before their first attempt as before a first retry
+func (s *StreamRetrier[M]) resumed() *IteratorBuilder {
+	config := s.config.clone()
+	config.resumed = true
+	return config
+}
+
+func closeReceiver[M any](recv Receiver[M]) {
+	if c, ok := recv.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
@@ -0,0 +1,186 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type sliceReceiver struct {
+	msgs []int
+	fail error
+}
+
+func (r *sliceReceiver) Recv() (int, error) {
+	if len(r.msgs) == 0 {
+		return 0, r.fail
+	}
+	msg := r.msgs[0]
+	r.msgs = r.msgs[1:]
+	return msg, nil
+}
+
+func TestStreamRetrier_ResumesAfterLastToken(t *testing.T) {
+	var tokens []string
+	opens := 0
+
+	sr := NewStreamRetrier(
+		func(ctx context.Context, last string) (Receiver[int], error) {
+			opens++
+			tokens = append(tokens, last)
+			switch opens {
+			case 1:
+				return &sliceReceiver{msgs: []int{1, 2}, fail: ErrTemporary}, nil
+			case 2, 3:
+				// Re-establishment fails twice in a row
+				return nil, ErrTemporary
+			default:
+				return &sliceReceiver{msgs: []int{3}, fail: io.EOF}, nil
+			}
+		},
+		func(m int) string { return strconv.Itoa(m) },
+	).WithPolicy(CombinePolicies(MaxAttempts(3), WithBackoff(NoDelay())))
+
+	var handled []int
+	err := sr.Run(context.Background(), func(m int) error {
+		handled = append(handled, m)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected stream to complete, got %v", err)
+	}
+	if !slices.Equal(handled, []int{1, 2, 3}) {
+		t.Errorf("Expected each message once, got %v", handled)
+	}
+	if !slices.Equal(tokens, []string{"", "2", "2", "2"}) {
+		t.Errorf("Expected resume from last token, got %v", tokens)
+	}
+}
+
+func TestStreamRetrier_GivesUp(t *testing.T) {
+	sr := NewStreamRetrier(
+		func(ctx context.Context, last string) (Receiver[int], error) {
+			return nil, ErrTemporary
+		},
+		func(m int) string { return strconv.Itoa(m) },
+	).WithPolicy(CombinePolicies(MaxAttempts(2), WithBackoff(NoDelay())))
+
+	err := sr.Run(context.Background(), func(int) error { return nil })
+	if !IsMaxAttemptsExceeded(err) {
+		t.Errorf("Expected MaxAttemptsExceededError, got %v", err)
+	}
+}
+
+func TestStreamRetrier_HandlerErrorStops(t *testing.T) {
+	sr := NewStreamRetrier(
+		func(ctx context.Context, last string) (Receiver[int], error) {
+			return &sliceReceiver{msgs: []int{1, 2}, fail: io.EOF}, nil
+		},
+		func(m int) string { return strconv.Itoa(m) },
+	)
+
+	err := sr.Run(context.Background(), func(m int) error {
+		return ErrFatal
+	})
+	if !errors.Is(err, ErrFatal) {
+		t.Errorf("Expected handler error, got %v", err)
+	}
+}
+
+type closingReceiver struct {
+	sliceReceiver
+	closed *int
+}
+
+func (r *closingReceiver) Close() error {
+	*r.closed++
+	return nil
+}
+
+func TestStreamRetrier_FlappingStreamBacksOff(t *testing.T) {
+	opens, closed := 0, 0
+	sr := NewStreamRetrier(
+		func(ctx context.Context, last string) (Receiver[int], error) {
+			opens++
+			return &closingReceiver{sliceReceiver{msgs: []int{opens}, fail: ErrTemporary}, &closed}, nil
+		},
+		func(m int) string { return strconv.Itoa(m) },
+	).WithPolicy(WithBackoff(Constant(20 * time.Millisecond)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := sr.Run(ctx, func(int) error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the stream to run until ctx is done, got %v", err)
+	}
+	if opens < 2 || opens > 6 {
+		t.Errorf("Expected reconnects paced by the backoff, got %d", opens)
+	}
+	if closed != opens {
+		t.Errorf("Expected every failed stream to be closed, got %d of %d", closed, opens)
+	}
+}
+
+func TestStreamRetrier_FatalAfterProgress(t *testing.T) {
+	opens, closed := 0, 0
+	sr := NewStreamRetrier(
+		func(ctx context.Context, last string) (Receiver[int], error) {
+			opens++
+			return &closingReceiver{sliceReceiver{msgs: []int{1}, fail: Fatal(ErrTemporary)}, &closed}, nil
+		},
+		func(m int) string { return strconv.Itoa(m) },
+	).WithPolicy(WithBackoff(NoDelay()))
+
+	err := sr.Run(context.Background(), func(int) error { return nil })
+	if !errors.Is(err, ErrTemporary) || opens != 1 {
+		t.Errorf("Expected a fatal error to end the stream without reconnecting, got %v after %d opens", err, opens)
+	}
+
+	opens = 0
+	sr = NewStreamRetrier(
+		func(ctx context.Context, last string) (Receiver[int], error) {
+			opens++
+			return &closingReceiver{sliceReceiver{msgs: []int{1}, fail: io.EOF}, &closed}, nil
+		},
+		func(m int) string { return strconv.Itoa(m) },
+	)
+	closed = 0
+	if err := sr.Run(context.Background(), func(int) error { return nil }); err != nil || closed != 1 {
+		t.Errorf("Expected a completed stream to be closed, got %v with %d closes", err, closed)
+	}
+}
+
+// elapsedOnly is an ElapsedBackoffer recording which method computed delays
+type elapsedOnly struct{ plain, elapsed int }
+
+func (b *elapsedOnly) Next(int) time.Duration { b.plain++; return 0 }
+
+func (b *elapsedOnly) NextElapsed(int, time.Duration) time.Duration { b.elapsed++; return 0 }
+
+func TestStreamRetrier_ResumeUsesRetryDelay(t *testing.T) {
+	opens := 0
+	sr := NewStreamRetrier(
+		func(ctx context.Context, last string) (Receiver[int], error) {
+			opens++
+			if opens > 1 {
+				return &sliceReceiver{fail: io.EOF}, nil
+			}
+			return &sliceReceiver{msgs: []int{1}, fail: ErrTemporary}, nil
+		},
+		func(m int) string { return strconv.Itoa(m) },
+	)
+	backoff := &elapsedOnly{}
+	sr.WithPolicy(WithBackoff(backoff))
+
+	if err := sr.Run(context.Background(), func(int) error { return nil }); err != nil || opens != 2 {
+		t.Fatalf("Expected the stream to resume and complete, got %v after %d opens", err, opens)
+	}
+	if backoff.elapsed != 1 || backoff.plain != 0 {
+		t.Errorf("Expected the resume delay computed like a retry's, got %d elapsed and %d plain calls", backoff.elapsed, backoff.plain)
+	}
+}
@@ -0,0 +1,470 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrNoHealthyTargets is returned when every target's breaker is open
+var ErrNoHealthyTargets error = &codedError{code: CodeNoHealthyTargets, msg: "recur: no healthy targets"}
+
+// SelectionStrategy decides which target each attempt goes to
+type SelectionStrategy int
+
+const (
+	// RoundRobin sends each attempt to the next target in turn
+	RoundRobin SelectionStrategy = iota
+	// Weighted spreads attempts in proportion to target weights
+	Weighted
+	// StickyUntilFailure keeps using one target until an attempt on it fails
+	StickyUntilFailure
+)
+
+// Default per-target breaker settings
+const (
+	DefaultTargetFailureThreshold = 5
+	DefaultTargetCooldown         = 10 * time.Second
+)
+
+// Target is an endpoint with an optional weight, used by the Weighted
+// strategy. Weights below 1 count as 1.
+type Target[T any] struct {
+	Value  T
+	Weight int
+}
+
+// targetState tracks selection and breaker state for one target
+type targetState[T any] struct {
+	Target[T]
+	current   int // Smooth weighted round-robin counter
+	failures  int // Consecutive failures
+	openUntil time.Time
+}
+
+// Targets spreads retries across replicas of a dependency, combining
+// failover with retry: each attempt can go to a different target, and a
+// per-target breaker skips targets that keep failing. It is safe for
+// concurrent use.
+type Targets[T any] struct {
+	mu        sync.Mutex
+	strategy  SelectionStrategy
+	targets   []*targetState[T]
+	cursor    int
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	refresh   func(ctx context.Context) ([]Target[T], error)
+	fallback  *Targets[T]
+	onRoute   func(RouteEvent)
+	degraded  bool         // Whether selections currently go to the fallback
+	counts    ErrorMatcher // Failures counted against breakers, see WithBreakerMatcher
+}
+
+// RouteEvent reports that Targets switched between its own targets and its
+// fallback
+type RouteEvent struct {
+	Fallback bool      // True when switching to the fallback, false when reverting
+	Time     time.Time // When the switch happened
+}
+
+// NewTargets creates a target set using strategy
+//
+// Example:
+//
+//	replicas := recur.NewTargets(recur.RoundRobin,
+//	    recur.Target[string]{Value: "http://db-1"},
+//	    recur.Target[string]{Value: "http://db-2"},
+//	)
+//
+//	err := replicas.Run(ctx, recur.Iter().WithMaxAttempts(4), func(ctx context.Context, url string) error {
+//	    return query(ctx, url)
+//	})
+func NewTargets[T any](strategy SelectionStrategy, targets ...Target[T]) *Targets[T] {
+	t := &Targets[T]{
+		strategy:  strategy,
+		threshold: DefaultTargetFailureThreshold,
+		cooldown:  DefaultTargetCooldown,
+		now:       time.Now,
+	}
+	t.targets = newTargetStates(targets)
+	return t
+}
+
+func newTargetStates[T any](targets []Target[T]) []*targetState[T] {
+	states := make([]*targetState[T], len(targets))
+	for i, target := range targets {
+		target.Weight = max(target.Weight, 1)
+		states[i] = &targetState[T]{Target: target}
+	}
+	return states
+}
+
+// WithBreaker sets how many consecutive failures open a target's breaker and
+// how long it stays open. A threshold of 0 disables the breaker.
+func (t *Targets[T]) WithBreaker(threshold int, cooldown time.Duration) *Targets[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.threshold = threshold
+	t.cooldown = cooldown
+	return t
+}
+
+// WithBreakerMatcher sets which failures count against a target's breaker.
+// By default context cancellations and deadlines are ignored, as they say
+// nothing about the target, and so are errors Run's matcher won't retry or
+// that are classified with Fatal, such as a request the target rejected.
+// Ignored failures leave the breaker as it was.
+func (t *Targets[T]) WithBreakerMatcher(matcher ErrorMatcher) *Targets[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts = matcher
+	return t
+}
+
+// WithRefresh sets fn to re-resolve the target list, for example from DNS
+// SRV records or a service registry. Run calls it before every retry so a
+// cycle picks up newly healthy endpoints; call Refresh to trigger it directly.
+func (t *Targets[T]) WithRefresh(fn func(ctx context.Context) ([]Target[T], error)) *Targets[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.refresh = fn
+	return t
+}
+
+// WithFallback routes selections to fallback, such as replicas in a
+// secondary region, while every one of these targets has its breaker open.
+// Selections revert to these targets as soon as one becomes available
+// again, which includes the probe after a breaker's cooldown.
+//
+// Example:
+//
+//	primary := recur.NewTargets(recur.RoundRobin, usEast...).
+//	    WithFallback(recur.NewTargets(recur.RoundRobin, usWest...)).
+//	    OnRoute(func(e recur.RouteEvent) { log.Printf("fallback=%v", e.Fallback) })
+func (t *Targets[T]) WithFallback(fallback *Targets[T]) *Targets[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fallback = fallback
+	return t
+}
+
+// OnRoute sets fn to be called whenever selections switch to or from the
+// fallback. fn runs synchronously, outside the target set's lock.
+func (t *Targets[T]) OnRoute(fn func(RouteEvent)) *Targets[T] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onRoute = fn
+	return t
+}
+
+// Refresh replaces the target list with the result of the WithRefresh
+// function. Targets that are still present keep their breaker state when T
+// is comparable. On error, or if the function returns no targets, the
+// current list is kept.
+func (t *Targets[T]) Refresh(ctx context.Context) error {
+	t.mu.Lock()
+	refresh := t.refresh
+	t.mu.Unlock()
+	if refresh == nil {
+		return nil
+	}
+
+	targets, err := refresh(ctx)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return ErrNoHealthyTargets
+	}
+
+	states := newTargetStates(targets)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if reflect.TypeFor[T]().Comparable() {
+		for _, state := range states {
+			for _, old := range t.targets {
+				if sameTarget(old.Value, state.Value) {
+					state.current, state.failures, state.openUntil = old.current, old.failures, old.openUntil
+				}
+			}
+		}
+	}
+	t.targets = states
+	t.cursor %= len(states)
+	return nil
+}
+
+// sameTarget reports whether a and b are equal, treating values that can't
+// be compared, such as interfaces holding slices, as different
+func sameTarget[T any](a, b T) bool {
+	va, vb := reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem()
+	return va.Comparable() && vb.Comparable() && va.Equal(vb)
+}
+
+// Selection is a target picked for one attempt. Report its outcome with Done.
+type Selection[T any] struct {
+	Value   T
+	targets *Targets[T]
+	state   *targetState[T]
+}
+
+// Done records the outcome of the attempt on the selected target
+func (s *Selection[T]) Done(err error) {
+	s.targets.done(s.state, err, nil)
+}
+
+// Select picks a target for the next attempt, skipping targets whose
+// breaker is open. If all are open, it selects from the fallback, if any.
+func (t *Targets[T]) Select() (*Selection[T], error) {
+	sel, err := t.selectOwn()
+	fallback, event, notify := t.route(err != nil)
+	if notify != nil {
+		notify(event)
+	}
+	if err != nil && fallback != nil {
+		return fallback.Select()
+	}
+	return sel, err
+}
+
+// route records whether selections go to the fallback, returning the
+// fallback and, on a switch, the event to report
+func (t *Targets[T]) route(degraded bool) (*Targets[T], RouteEvent, func(RouteEvent)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.fallback == nil || degraded == t.degraded {
+		return t.fallback, RouteEvent{}, nil
+	}
+	t.degraded = degraded
+	return t.fallback, RouteEvent{Fallback: degraded, Time: t.now()}, t.onRoute
+}
+
+// selectOwn picks one of the set's own targets
+func (t *Targets[T]) selectOwn() (*Selection[T], error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	var picked *targetState[T]
+	switch t.strategy {
+	case Weighted:
+		picked = t.selectWeighted(now)
+	case StickyUntilFailure:
+		picked = t.selectFrom(t.cursor, now, false)
+	default:
+		picked = t.selectFrom(t.cursor, now, true)
+	}
+	if picked == nil {
+		return nil, ErrNoHealthyTargets
+	}
+	return &Selection[T]{Value: picked.Value, targets: t, state: picked}, nil
+}
+
+// Run retries fn with builder's configuration, sending each attempt to the
+// next selected target and refreshing targets between attempts. It returns
+// ErrNoHealthyTargets, wrapped in the classified error, if every breaker is
+// open, including the fallback's.
+func (t *Targets[T]) Run(ctx context.Context, builder *IteratorBuilder, fn func(ctx context.Context, target T) error) error {
+	first := true
+	return builder.run(ctx, func(ctx context.Context) error {
+		if !first {
+			// Keep the current list if re-resolving fails
+			_ = t.Refresh(ctx)
+		}
+		first = false
+
+		sel, err := t.Select()
+		if err != nil {
+			return err
+		}
+		err = fn(ctx, sel.Value)
+		t.done(sel.state, err, builder.matcher)
+		return err
+	})
+}
+
+// selectFrom returns the first available target starting at start. With
+// advance, the cursor moves past the picked target.
+func (t *Targets[T]) selectFrom(start int, now time.Time, advance bool) *targetState[T] {
+	n := len(t.targets)
+	for i := range n {
+		idx := (start + i) % n
+		if state := t.targets[idx]; t.available(state, now) {
+			t.cursor = idx
+			if advance {
+				t.cursor = (idx + 1) % n
+			}
+			return state
+		}
+	}
+	return nil
+}
+
+// selectWeighted implements smooth weighted round-robin over available targets
+func (t *Targets[T]) selectWeighted(now time.Time) *targetState[T] {
+	var best *targetState[T]
+	total := 0
+	for _, state := range t.targets {
+		if !t.available(state, now) {
+			continue
+		}
+		state.current += state.Weight
+		total += state.Weight
+		if best == nil || state.current > best.current {
+			best = state
+		}
+	}
+	if best != nil {
+		best.current -= total
+	}
+	return best
+}
+
+// BreakerStatus describes the breaker of one target
+type BreakerStatus struct {
+	Target    string        `json:"target"`              // The target's value, formatted with fmt
+	Failures  int           `json:"failures"`            // Consecutive failures
+	Open      bool          `json:"open"`                // Whether selections skip the target
+	Remaining time.Duration `json:"remaining,omitempty"` // Time until the breaker lets a probe through
+}
+
+// Breakers returns the breaker status of each target, in order
+func (t *Targets[T]) Breakers() []BreakerStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	statuses := make([]BreakerStatus, len(t.targets))
+	for i, state := range t.targets {
+		statuses[i] = BreakerStatus{
+			Target:   fmt.Sprint(state.Value),
+			Failures: state.failures,
+			Open:     !t.available(state, now),
+		}
+		if statuses[i].Open {
+			statuses[i].Remaining = state.openUntil.Sub(now)
+		}
+	}
+	return statuses
+}
+
+// TripBreaker opens the breaker of the target at index i, as returned by
+// Breakers, for the cooldown, for example to drain a replica by hand. It
+// reports false if there is no such target or breakers are disabled.
+func (t *Targets[T]) TripBreaker(i int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i < 0 || i >= len(t.targets) || t.threshold <= 0 {
+		return false
+	}
+	state := t.targets[i]
+	state.failures = max(state.failures, t.threshold)
+	state.openUntil = t.now().Add(t.cooldown)
+	return true
+}
+
+// ResetBreaker closes the breaker of the target at index i, as returned by
+// Breakers, and clears its failures. It reports false if there is no such
+// target.
+func (t *Targets[T]) ResetBreaker(i int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if i < 0 || i >= len(t.targets) {
+		return false
+	}
+	t.targets[i].failures = 0
+	t.targets[i].openUntil = time.Time{}
+	return true
+}
+
+func (t *Targets[T]) available(state *targetState[T], now time.Time) bool {
+	return t.threshold <= 0 || state.failures < t.threshold || !now.Before(state.openUntil)
+}
+
+// done records an attempt's outcome on state. matcher, if set, is the
+// retry matcher the attempt ran under.
+func (t *Targets[T]) done(state *targetState[T], err error, matcher ErrorMatcher) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err == nil {
+		state.failures = 0
+		return
+	}
+	if !t.countsFailure(err, matcher) {
+		return
+	}
+	state.failures++
+	if t.threshold > 0 && state.failures >= t.threshold {
+		// Open, or re-open after a failed probe once the cooldown passed
+		state.openUntil = t.now().Add(t.cooldown)
+	}
+	if t.strategy == StickyUntilFailure && len(t.targets) > 0 && t.targets[t.cursor] == state {
+		t.cursor = (t.cursor + 1) % len(t.targets)
+	}
+}
+
+// countsFailure reports whether err counts against a target's breaker
+func (t *Targets[T]) countsFailure(err error, matcher ErrorMatcher) bool {
+	if t.counts != nil {
+		return t.counts(err)
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if matcher != nil {
+		return retryable(matcher, err)
+	}
+	retryable, ok := Classify(err)
+	return !ok || retryable
+}
+
+// SRVTargets returns a refresh function for WithRefresh that resolves SRV
+// records through resolver into "host:port" targets weighted by the records'
+// weights. Only the lowest priority present is used, as SRV prescribes.
+func SRVTargets(resolver *Resolver, service, proto, name string) func(ctx context.Context) ([]Target[string], error) {
+	return func(ctx context.Context) ([]Target[string], error) {
+		_, records, err := resolver.LookupSRV(ctx, service, proto, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var targets []Target[string]
+		for _, rec := range records {
+			if rec.Priority != records[0].Priority {
+				break // LookupSRV sorts by priority
+			}
+			targets = append(targets, Target[string]{
+				Value:  net.JoinHostPort(rec.Target, strconv.Itoa(int(rec.Port))),
+				Weight: int(rec.Weight),
+			})
+		}
+		return targets, nil
+	}
+}
+
+// HostTargets returns a refresh function for WithRefresh that resolves host
+// through resolver into one "addr:port" target per address, as for
+// Kubernetes headless services
+func HostTargets(resolver *Resolver, host, port string) func(ctx context.Context) ([]Target[string], error) {
+	return func(ctx context.Context) ([]Target[string], error) {
+		addrs, err := resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		targets := make([]Target[string], len(addrs))
+		for i, addr := range addrs {
+			targets[i] = Target[string]{Value: net.JoinHostPort(addr, port)}
+		}
+		return targets, nil
+	}
+}
@@ -0,0 +1,235 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func selectN[T any](t *testing.T, targets *Targets[T], n int, fail func(T) bool) []T {
+	t.Helper()
+	var picked []T
+	for range n {
+		sel, err := targets.Select()
+		if err != nil {
+			t.Fatal(err)
+		}
+		picked = append(picked, sel.Value)
+		if fail != nil && fail(sel.Value) {
+			sel.Done(ErrTemporary)
+		} else {
+			sel.Done(nil)
+		}
+	}
+	return picked
+}
+
+func TestTargets_RoundRobin(t *testing.T) {
+	targets := NewTargets(RoundRobin, Target[string]{Value: "a"}, Target[string]{Value: "b"}, Target[string]{Value: "c"})
+	if got := selectN(t, targets, 4, nil); !slices.Equal(got, []string{"a", "b", "c", "a"}) {
+		t.Errorf("Unexpected order %v", got)
+	}
+}
+
+func TestTargets_Weighted(t *testing.T) {
+	targets := NewTargets(Weighted, Target[string]{Value: "big", Weight: 3}, Target[string]{Value: "small", Weight: 1})
+	got := selectN(t, targets, 8, nil)
+
+	counts := map[string]int{}
+	for _, v := range got {
+		counts[v]++
+	}
+	if counts["big"] != 6 || counts["small"] != 2 {
+		t.Errorf("Expected a 3:1 split, got %v", counts)
+	}
+}
+
+func TestTargets_StickyUntilFailure(t *testing.T) {
+	targets := NewTargets(StickyUntilFailure, Target[string]{Value: "a"}, Target[string]{Value: "b"})
+	failing := true
+	got := selectN(t, targets, 4, func(v string) bool {
+		if v == "a" && failing {
+			failing = false
+			return true
+		}
+		return false
+	})
+	if !slices.Equal(got, []string{"a", "b", "b", "b"}) {
+		t.Errorf("Expected to stick until failure, got %v", got)
+	}
+}
+
+func TestTargets_Breaker(t *testing.T) {
+	now := time.Now()
+	targets := NewTargets(RoundRobin, Target[string]{Value: "bad"}, Target[string]{Value: "good"}).
+		WithBreaker(2, time.Minute)
+	targets.now = func() time.Time { return now }
+
+	got := selectN(t, targets, 6, func(v string) bool { return v == "bad" })
+	if !slices.Equal(got, []string{"bad", "good", "bad", "good", "good", "good"}) {
+		t.Errorf("Expected bad target to be skipped once open, got %v", got)
+	}
+
+	now = now.Add(time.Minute)
+	if sel, _ := targets.Select(); sel.Value != "bad" {
+		t.Errorf("Expected bad target to be probed after cooldown, got %v", sel.Value)
+	}
+}
+
+func TestTargets_Fallback(t *testing.T) {
+	now := time.Now()
+	var events []RouteEvent
+	secondary := NewTargets(RoundRobin, Target[string]{Value: "west"})
+	primary := NewTargets(RoundRobin, Target[string]{Value: "east"}).
+		WithBreaker(1, time.Minute).
+		WithFallback(secondary).
+		OnRoute(func(e RouteEvent) { events = append(events, e) })
+	primary.now = func() time.Time { return now }
+
+	healthy := false
+	got := selectN(t, primary, 3, func(v string) bool { return v == "east" && !healthy })
+	if !slices.Equal(got, []string{"east", "west", "west"}) {
+		t.Errorf("Expected failover to the secondary once the breaker opened, got %v", got)
+	}
+
+	now = now.Add(time.Minute)
+	healthy = true
+	got = selectN(t, primary, 2, func(v string) bool { return v == "east" && !healthy })
+	if !slices.Equal(got, []string{"east", "east"}) {
+		t.Errorf("Expected to revert to the primary after its cooldown, got %v", got)
+	}
+
+	if len(events) != 2 || !events[0].Fallback || events[1].Fallback {
+		t.Errorf("Expected switch to and from the fallback, got %+v", events)
+	}
+}
+
+func TestTargets_Run(t *testing.T) {
+	targets := NewTargets(RoundRobin, Target[string]{Value: "down"}, Target[string]{Value: "up"}).WithBreaker(1, time.Minute)
+
+	var tried []string
+	err := targets.Run(context.Background(), Iter().WithBackoff(NoDelay()), func(ctx context.Context, target string) error {
+		tried = append(tried, target)
+		if target == "down" {
+			return ErrTemporary
+		}
+		return nil
+	})
+	if err != nil || !slices.Equal(tried, []string{"down", "up"}) {
+		t.Errorf("Expected failover to succeed, got %v after %v", err, tried)
+	}
+
+	only := NewTargets(RoundRobin, Target[string]{Value: "down"}).WithBreaker(1, time.Minute)
+	err = only.Run(context.Background(), Iter().WithBackoff(NoDelay()), func(ctx context.Context, target string) error {
+		return ErrTemporary
+	})
+	if !errors.Is(err, ErrNoHealthyTargets) {
+		t.Errorf("Expected ErrNoHealthyTargets, got %v", err)
+	}
+}
+
+func TestTargets_RefreshBetweenAttempts(t *testing.T) {
+	resolved := []Target[string]{{Value: "pod-1"}}
+	targets := NewTargets(RoundRobin, resolved...).
+		WithRefresh(func(ctx context.Context) ([]Target[string], error) {
+			return resolved, nil
+		})
+
+	var tried []string
+	err := targets.Run(context.Background(), Iter().WithBackoff(NoDelay()), func(ctx context.Context, target string) error {
+		tried = append(tried, target)
+		if target == "pod-1" {
+			// The pod is replaced while the cycle is running
+			resolved = []Target[string]{{Value: "pod-2"}}
+			return ErrTemporary
+		}
+		return nil
+	})
+	if err != nil || !slices.Equal(tried, []string{"pod-1", "pod-2"}) {
+		t.Errorf("Expected retry to reach the new pod, got %v after %v", err, tried)
+	}
+}
+
+func TestTargets_RefreshKeepsBreakerState(t *testing.T) {
+	targets := NewTargets(RoundRobin, Target[string]{Value: "a"}, Target[string]{Value: "b"}).
+		WithBreaker(1, time.Minute).
+		WithRefresh(func(ctx context.Context) ([]Target[string], error) {
+			return []Target[string]{{Value: "b"}, {Value: "a"}, {Value: "c"}}, nil
+		})
+
+	sel, _ := targets.Select()
+	sel.Done(ErrTemporary) // opens "a"
+
+	if err := targets.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		if sel, _ := targets.Select(); sel.Value == "a" {
+			t.Fatal("Expected a's breaker to survive refresh")
+		}
+	}
+}
+
+func TestTargets_RefreshNonComparableValues(t *testing.T) {
+	targets := NewTargets(RoundRobin, Target[any]{Value: []string{"a"}}, Target[any]{Value: "b"}).
+		WithRefresh(func(ctx context.Context) ([]Target[any], error) {
+			return []Target[any]{{Value: []string{"a"}}, {Value: "b"}}, nil
+		})
+	if err := targets.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTargets_BreakerIgnoresCallerFailures(t *testing.T) {
+	targets := NewTargets(RoundRobin, Target[string]{Value: "a"}).WithBreaker(1, time.Minute)
+	for _, err := range []error{context.Canceled, context.DeadlineExceeded, Fatal(errors.New("bad request"))} {
+		sel, _ := targets.Select()
+		sel.Done(err)
+	}
+	if _, err := targets.Select(); err != nil {
+		t.Errorf("Expected caller failures not to open the breaker, got %v", err)
+	}
+
+	errNotFound := errors.New("not found")
+	err := targets.Run(context.Background(), Iter().RetryIf(MatchErrors(ErrTemporary)), func(ctx context.Context, target string) error {
+		return errNotFound
+	})
+	if !errors.Is(err, errNotFound) || targets.Breakers()[0].Open {
+		t.Errorf("Expected errors the matcher won't retry not to open the breaker, got %v", err)
+	}
+
+	targets.WithBreakerMatcher(MatchErrors(context.DeadlineExceeded))
+	sel, _ := targets.Select()
+	sel.Done(context.DeadlineExceeded)
+	if !targets.Breakers()[0].Open {
+		t.Error("Expected the breaker matcher to count deadlines")
+	}
+}
+
+func TestHostTargets(t *testing.T) {
+	refresh := HostTargets(NewResolver(), "localhost", "8080")
+	targets, err := refresh(context.Background())
+	if err != nil {
+		t.Skipf("localhost lookup: %v", err)
+	}
+	if len(targets) == 0 || !strings.HasSuffix(targets[0].Value, ":8080") {
+		t.Errorf("Unexpected targets %v", targets)
+	}
+}
+
+func TestTargets_Breakers(t *testing.T) {
+	targets := NewTargets(RoundRobin, Target[int]{Value: 1}, Target[int]{Value: 2})
+	if !targets.TripBreaker(0) || targets.TripBreaker(2) {
+		t.Fatal("Expected trip to succeed only for existing targets")
+	}
+	sel, err := targets.Select()
+	if err != nil || sel.Value != 2 {
+		t.Errorf("Expected tripped target skipped, got %v, %v", sel, err)
+	}
+	if !targets.ResetBreaker(0) || targets.Breakers()[0].Open {
+		t.Error("Expected breaker closed after reset")
+	}
+}
@@ -0,0 +1,52 @@
+package recur
+
+// ErrSuccessThresholdNotMet is the last error of a cycle that ran out of
+// attempts while its latest attempts succeeded, but fewer times in a row
+// than WithSuccessThreshold requires
+var ErrSuccessThresholdNotMet error = &codedError{code: CodeThresholdNotMet, msg: "success threshold not met"}
+
+// WithSuccessThreshold makes a cycle complete only after n consecutive
+// attempts succeed, mirroring readiness-probe semantics for health checks
+// and polling. A failed attempt resets the count. Attempts after a success
+// wait the backoff delay like retries and count towards the maximum
+// attempts. Values below 2 keep the default of stopping on the first
+// success.
+//
+// Example:
+//
+//	for attempt := range recur.Iter().
+//	    WithMaxAttempts(30).
+//	    WithBackoff(recur.Constant(time.Second)).
+//	    WithSuccessThreshold(3).
+//	    Seq() {
+//	    attempt.Result(probe(attempt.Context()))
+//	}
+func (b *IteratorBuilder) WithSuccessThreshold(n int) *IteratorBuilder {
+	b.successThreshold = n
+	return b
+}
+
+// SuccessThreshold creates a policy requiring n consecutive successes,
+// see IteratorBuilder.WithSuccessThreshold
+func SuccessThreshold(n int) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithSuccessThreshold(n)
+	}
+}
+
+// countSuccess updates the streak of consecutive successful attempts
+func (s *iteratorState) countSuccess(att *Attempt) {
+	if s.builder.successThreshold < 2 {
+		return
+	}
+	if att.resultSet && att.result == nil {
+		s.successes++
+	} else {
+		s.successes = 0
+	}
+}
+
+// thresholdMet reports whether enough consecutive attempts succeeded
+func (s *iteratorState) thresholdMet() bool {
+	return s.builder.successThreshold < 2 || s.successes >= s.builder.successThreshold
+}
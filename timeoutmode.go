@@ -0,0 +1,117 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeoutConflict reports that a cycle under TimeoutStrict made no
+// attempts because the caller's deadline is earlier than WithTimeout allows.
+// Seq loops see it as the LastErr of a single attempt whose context is
+// already canceled.
+var ErrTimeoutConflict error = &codedError{code: CodeTimeoutConflict, msg: "recur: caller deadline conflicts with timeout"}
+
+// TimeoutMode selects how WithTimeout combines with a deadline already set
+// on the caller's context. Without a caller deadline, WithTimeout applies
+// in every mode.
+type TimeoutMode int
+
+const (
+	// TimeoutMin ends the cycle at whichever of the caller's deadline and
+	// WithTimeout comes first. This is the default.
+	TimeoutMin TimeoutMode = iota
+
+	// TimeoutInherit keeps the caller's deadline and ignores WithTimeout,
+	// so the outermost layer owns the time budget
+	TimeoutInherit
+
+	// TimeoutOverride replaces the caller's deadline with WithTimeout, for
+	// work that must finish even if the caller's deadline is shorter, such
+	// as cleanup. Cancellation of the caller's context still ends the cycle.
+	TimeoutOverride
+
+	// TimeoutStrict behaves like TimeoutMin, except that a cycle whose
+	// caller's deadline is earlier than WithTimeout would end makes no
+	// attempts and fails with ErrTimeoutConflict, exposing layers whose
+	// budgets don't compose
+	TimeoutStrict
+)
+
+// String returns the mode's name
+func (m TimeoutMode) String() string {
+	switch m {
+	case TimeoutMin:
+		return "min"
+	case TimeoutInherit:
+		return "inherit"
+	case TimeoutOverride:
+		return "override"
+	case TimeoutStrict:
+		return "strict"
+	default:
+		return "unknown"
+	}
+}
+
+// WithTimeoutMode selects how WithTimeout combines with the caller's
+// deadline, see TimeoutMode
+func (b *IteratorBuilder) WithTimeoutMode(mode TimeoutMode) *IteratorBuilder {
+	b.timeoutMode = mode
+	return b
+}
+
+// Timeouts returns a policy selecting the timeout mode, see TimeoutMode
+func Timeouts(mode TimeoutMode) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithTimeoutMode(mode)
+	}
+}
+
+// WithTimeoutMode selects how WithTimeout combines with the caller's
+// deadline, see TimeoutMode
+func (r *Retrier[F, C]) WithTimeoutMode(mode TimeoutMode) *Retrier[F, C] {
+	r.config.WithTimeoutMode(mode)
+	return r
+}
+
+// timeoutConflict returns an ErrTimeoutConflict error if the builder is
+// strict and parent's deadline is earlier than its timeout would end
+func (b *IteratorBuilder) timeoutConflict(parent context.Context) error {
+	if b.timeoutMode != TimeoutStrict || b.timeout <= 0 {
+		return nil
+	}
+	deadline, ok := parent.Deadline()
+	if !ok {
+		return nil
+	}
+	if remaining := time.Until(deadline); remaining < b.timeout {
+		return fmt.Errorf("%w: %v left of the caller's deadline, timeout %v", ErrTimeoutConflict, remaining.Round(time.Millisecond), b.timeout)
+	}
+	return nil
+}
+
+// withTimeout applies the builder's timeout to ctx according to its mode
+func (b *IteratorBuilder) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		switch b.timeoutMode {
+		case TimeoutInherit:
+			return ctx, func() {}
+		case TimeoutOverride:
+			detached, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+			stop := context.AfterFunc(ctx, func() {
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					cancel(context.Cause(ctx))
+				}
+			})
+			timed, cancelTimeout := context.WithTimeoutCause(detached, b.timeout, ErrRetryTimeout)
+			return timed, func() {
+				cancelTimeout()
+				stop()
+				cancel(nil)
+			}
+		}
+	}
+	return context.WithTimeoutCause(ctx, b.timeout, ErrRetryTimeout)
+}
@@ -0,0 +1,109 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// Error labels drivers such as MongoDB attach to transaction errors
+const (
+	LabelTransientTransaction = "TransientTransactionError"
+	LabelUnknownCommitResult  = "UnknownTransactionCommitResult"
+)
+
+// Txn is a started transaction
+type Txn interface {
+	Commit(ctx context.Context) error
+	Abort(ctx context.Context) error
+}
+
+// TxnStarter starts transactions, typically a driver session or connection
+// wrapped in a small adapter
+type TxnStarter[T Txn] interface {
+	StartTransaction(ctx context.Context) (T, error)
+}
+
+// errorLabeler is implemented by driver errors carrying labels, such as
+// MongoDB's mongo.CommandError
+type errorLabeler interface {
+	HasErrorLabel(label string) bool
+}
+
+// sqlStater is implemented by SQL driver errors exposing their SQLSTATE,
+// such as pgx's pgconn.PgError
+type sqlStater interface {
+	SQLState() string
+}
+
+// Retryable SQLSTATE codes: serialization_failure and deadlock_detected
+var transientSQLStates = []string{"40001", "40P01"}
+
+// HasErrorLabel reports whether any error in err's chain carries label
+func HasErrorLabel(err error, label string) bool {
+	var labeled errorLabeler
+	return errors.As(err, &labeled) && labeled.HasErrorLabel(label)
+}
+
+// MatchTransientTransaction matches errors after which the whole
+// transaction can be retried: errors labelled TransientTransactionError and
+// SQL serialization failures and deadlocks. It relies only on the driver
+// errors' methods, so no driver needs to be imported.
+func MatchTransientTransaction(err error) bool {
+	if HasErrorLabel(err, LabelTransientTransaction) {
+		return true
+	}
+	var stater sqlStater
+	return errors.As(err, &stater) && slices.Contains(transientSQLStates, stater.SQLState())
+}
+
+// WithTransactionRetry runs fn in a transaction started from sess and
+// commits it, retrying the whole transaction on errors matching
+// MatchTransientTransaction. A commit failing with
+// UnknownTransactionCommitResult is retried on its own, since the
+// transaction may have been applied. The transaction is aborted whenever
+// fn or the commit fails. Defaults are 5 attempts with jittered
+// exponential backoff from 10ms up to 1s, followed by any policies.
+//
+// Example:
+//
+//	err := recur.WithTransactionRetry(ctx, session, func(ctx context.Context, tx *Tx) error {
+//	    return tx.Transfer(ctx, from, to, amount)
+//	})
+func WithTransactionRetry[T Txn](ctx context.Context, sess TxnStarter[T], fn func(ctx context.Context, tx T) error, policies ...Policy) error {
+	config := Iter().
+		WithMaxAttempts(5).
+		WithBackoff(Jitter(Exponential(10*time.Millisecond).(*ExponentialBackoff).WithMaxDelay(time.Second), 0.5)).
+		RetryIf(MatchTransientTransaction).
+		WithPolicy(CombinePolicies(policies...))
+
+	var final error
+	for attempt := range config.seq(ctx, &final) {
+		attempt.Result(runTransaction(attempt.Context(), sess, fn, config.maxAttempts))
+	}
+	return final
+}
+
+// runTransaction makes one transaction attempt, retrying a commit with an
+// unknown result up to commitAttempts times
+func runTransaction[T Txn](ctx context.Context, sess TxnStarter[T], fn func(ctx context.Context, tx T) error, commitAttempts int) error {
+	tx, err := sess.StartTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(ctx, tx); err != nil {
+		return errors.Join(err, tx.Abort(context.WithoutCancel(ctx)))
+	}
+
+	for range max(commitAttempts, 1) {
+		err = tx.Commit(ctx)
+		if err == nil || !HasErrorLabel(err, LabelUnknownCommitResult) || ctx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		return errors.Join(err, tx.Abort(context.WithoutCancel(ctx)))
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package recur
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type labeledError struct {
+	label string
+}
+
+func (e *labeledError) Error() string                   { return e.label }
+func (e *labeledError) HasErrorLabel(label string) bool { return e.label == label }
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+type fakeTxn struct {
+	commitErrs []error
+	commits    int
+	aborts     int
+}
+
+func (t *fakeTxn) Commit(context.Context) error {
+	t.commits++
+	if len(t.commitErrs) == 0 {
+		return nil
+	}
+	err := t.commitErrs[0]
+	t.commitErrs = t.commitErrs[1:]
+	return err
+}
+
+func (t *fakeTxn) Abort(context.Context) error {
+	t.aborts++
+	return nil
+}
+
+type fakeSession struct {
+	txns []*fakeTxn
+}
+
+func (s *fakeSession) StartTransaction(context.Context) (*fakeTxn, error) {
+	tx := &fakeTxn{}
+	if len(s.txns) == 1 {
+		tx.commitErrs = []error{&labeledError{LabelUnknownCommitResult}}
+	}
+	s.txns = append(s.txns, tx)
+	return tx, nil
+}
+
+func TestWithTransactionRetry(t *testing.T) {
+	sess := &fakeSession{}
+	var runs int
+	err := WithTransactionRetry(context.Background(), sess, func(ctx context.Context, tx *fakeTxn) error {
+		runs++
+		if runs == 1 {
+			return &labeledError{LabelTransientTransaction}
+		}
+		return nil
+	}, WithBackoff(Constant(0)))
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+
+	if len(sess.txns) != 2 || runs != 2 {
+		t.Fatalf("Expected 2 transactions, got %d (%d runs)", len(sess.txns), runs)
+	}
+	if first := sess.txns[0]; first.aborts != 1 || first.commits != 0 {
+		t.Errorf("Expected failed transaction to be aborted without commit, got %+v", first)
+	}
+	if second := sess.txns[1]; second.commits != 2 || second.aborts != 0 {
+		t.Errorf("Expected unknown commit result to be retried, got %+v", second)
+	}
+}
+
+func TestWithTransactionRetry_NonTransient(t *testing.T) {
+	sess := &fakeSession{}
+	errConstraint := errors.New("constraint violation")
+	err := WithTransactionRetry(context.Background(), sess, func(ctx context.Context, tx *fakeTxn) error {
+		return errConstraint
+	})
+
+	var nonRetryable *NonRetryableError
+	if !errors.As(err, &nonRetryable) || !errors.Is(err, errConstraint) {
+		t.Errorf("Expected non-retryable constraint error, got %v", err)
+	}
+	if len(sess.txns) != 1 {
+		t.Errorf("Expected 1 transaction, got %d", len(sess.txns))
+	}
+}
+
+func TestMatchTransientTransaction(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&labeledError{LabelTransientTransaction}, true},
+		{&labeledError{LabelUnknownCommitResult}, false},
+		{sqlStateError("40001"), true},
+		{sqlStateError("40P01"), true},
+		{sqlStateError("23505"), false},
+		{errors.New("plain"), false},
+	}
+	for _, tt := range tests {
+		if got := MatchTransientTransaction(tt.err); got != tt.want {
+			t.Errorf("MatchTransientTransaction(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package recur
+
+import "time"
+
+// processStart approximates when the process started, for WithWarmup
+var processStart = time.Now()
+
+// WithWarmup applies reduced on top of the iterator's configuration to
+// cycles starting within d of process start, so retries stay gentle while
+// dependencies and caches are cold and a fleet-wide restart doesn't amplify
+// load on them. reduced typically allows fewer attempts with longer delays.
+//
+// Example:
+//
+//	r.WithWarmup(time.Minute, recur.CombinePolicies(
+//	    recur.MaxAttempts(2),
+//	    recur.WithBackoff(recur.Constant(time.Second)),
+//	))
+func (b *IteratorBuilder) WithWarmup(d time.Duration, reduced Policy) *IteratorBuilder {
+	b.warmup = d
+	b.warmupPolicy = reduced
+	return b
+}
+
+// Warmup returns a policy applying reduced during the first d after process
+// start, see IteratorBuilder.WithWarmup
+func Warmup(d time.Duration, reduced Policy) Policy {
+	return func(b *IteratorBuilder) {
+		b.WithWarmup(d, reduced)
+	}
+}
+
+// WithWarmup applies a gentler policy right after process start, see
+// IteratorBuilder.WithWarmup
+func (r *Retrier[F, C]) WithWarmup(d time.Duration, reduced Policy) *Retrier[F, C] {
+	r.config.WithWarmup(d, reduced)
+	return r
+}
+
+// warmingUp reports whether a cycle starting now falls in the warm-up period
+func (b *IteratorBuilder) warmingUp() bool {
+	return b.warmupPolicy != nil && time.Since(processStart) < b.warmup
+}